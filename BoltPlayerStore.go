@@ -0,0 +1,475 @@
+package poker
+
+import (
+	"encoding/json"
+	bolt "go.etcd.io/bbolt"
+	"log"
+	"time"
+)
+
+var playersBucket = []byte("players")
+
+var gamesBucket = []byte("games")
+
+var ratingsBucket = []byte("ratings")
+
+// leaguesBucket holds the seasons and tables of every league together under leaguesKey,
+// as they're small and a season is started or closed across all of them at once
+var leaguesBucket = []byte("leagues")
+
+var leaguesKey = []byte("leagues")
+
+// playerKey prefixes names so that a player with an empty name still has a valid key
+func playerKey(name string) []byte {
+	return []byte("player/" + name)
+}
+
+// BoltPlayerStore stores each Player under their own key in an embedded bbolt database.
+// It is safe for concurrent use.
+type BoltPlayerStore struct {
+	db *bolt.DB
+}
+
+// NewBoltPlayerStore is a constructor for the BoltPlayerStore, creating its buckets if they don't exist
+func NewBoltPlayerStore(db *bolt.DB) (*BoltPlayerStore, error) {
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{playersBucket, gamesBucket, ratingsBucket, leaguesBucket} {
+			_, err := tx.CreateBucketIfNotExists(bucket)
+			if err != nil {
+				return err
+			}
+		}
+
+		if tx.Bucket(leaguesBucket).Get(leaguesKey) != nil {
+			return nil
+		}
+
+		// databases from before leagues start the main league off with every win so far
+		league, err := readLeague(tx)
+
+		if err != nil {
+			return err
+		}
+
+		return putLeagues(tx, newLeagues(league))
+	})
+
+	if err != nil {
+		return nil, ErrDBInitialize
+	}
+
+	return &BoltPlayerStore{db: db}, nil
+}
+
+// BoltStoreFromFile opens the bbolt database in filename and returns the store, its close func and the error
+func BoltStoreFromFile(filename string) (*BoltPlayerStore, func(), error) {
+
+	db, err := bolt.Open(filename, 0600, &bolt.Options{Timeout: time.Second})
+
+	if err != nil {
+		return nil, nil, ErrFileOpen
+	}
+
+	closeFunc := func() {
+		e := db.Close()
+		if e != nil {
+			log.Printf(string(ErrFileClose))
+		}
+	}
+
+	store, err := NewBoltPlayerStore(db)
+
+	if err != nil {
+		db.Close()
+		return nil, nil, ErrCreateStore
+	}
+
+	return store, closeFunc, nil
+}
+
+// GetLeague reads every Player and returns them sorted by wins
+func (b *BoltPlayerStore) GetLeague() League {
+
+	var league League
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		league, err = readLeague(tx)
+		return err
+	})
+
+	if err != nil {
+		log.Printf("%s %v", ErrLoadingPlayerStore, err)
+	}
+
+	return league.sorted()
+}
+
+// readLeague reads every Player
+func readLeague(tx *bolt.Tx) (League, error) {
+
+	league := League{}
+
+	err := tx.Bucket(playersBucket).ForEach(func(_, v []byte) error {
+		var player Player
+		err := json.Unmarshal(v, &player)
+		if err != nil {
+			return err
+		}
+		league = append(league, player)
+		return nil
+	})
+
+	return league, err
+}
+
+// GetPlayerScore returns a player's score (or zero if they don't exist)
+func (b *BoltPlayerStore) GetPlayerScore(name string) int {
+
+	var player Player
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return getPlayer(tx, name, &player)
+	})
+
+	if err != nil {
+		log.Printf("%s %v", ErrLoadingPlayerStore, err)
+	}
+
+	return player.Wins
+}
+
+// PostRecordWin increments a player's score (or creates the player if they don't exist)
+// in the all-time League and the main league
+func (b *BoltPlayerStore) PostRecordWin(name string) error {
+	return b.RecordLeagueWin("", "", name)
+}
+
+// RecordLeagueWin increments a player's score in the all-time League and a season of league
+func (b *BoltPlayerStore) RecordLeagueWin(league, season, name string) error {
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		leagues, err := getLeagues(tx)
+
+		if err != nil {
+			return err
+		}
+
+		season, err := leagues.seasonFor(league, season)
+
+		if err != nil {
+			return err
+		}
+
+		err = updatePlayers(tx, []string{name}, func(league League) League {
+			return league.recordWin(name)
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return putLeagues(tx, leagues.recordWin(league, season, name))
+	})
+}
+
+// RecordGame stores a finished game under its id and counts and rates it for everyone who played
+func (b *BoltPlayerStore) RecordGame(game GameRecord) error {
+
+	// bbolt needs a key, so games that haven't been given an id get one here
+	if game.ID == "" {
+		game.ID = NewGameID()
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		leagues, err := getLeagues(tx)
+
+		if err != nil {
+			return err
+		}
+
+		game.Season, err = leagues.seasonFor(game.League, game.Season)
+
+		if err != nil {
+			return err
+		}
+
+		v, err := json.Marshal(game)
+
+		if err != nil {
+			return ErrEncode
+		}
+
+		err = tx.Bucket(gamesBucket).Put([]byte(game.ID), v)
+
+		if err != nil {
+			return ErrFileWrite
+		}
+
+		err = updatePlayers(tx, game.participants(), func(league League) League {
+			return league.recordGame(game)
+		})
+
+		if err != nil {
+			return err
+		}
+
+		err = updateRatings(tx, game)
+
+		if err != nil {
+			return err
+		}
+
+		return putLeagues(tx, leagues.recordGame(game))
+	})
+}
+
+// StartSeason opens a new season of league, which wins go into until it is closed
+func (b *BoltPlayerStore) StartSeason(league, season string) error {
+
+	return b.updateLeagues(func(leagues Leagues) (Leagues, error) {
+		return leagues.startSeason(Season{League: league, Name: season, Started: time.Now()})
+	})
+}
+
+// CloseSeason closes a season of league, leaving its table as it stands
+func (b *BoltPlayerStore) CloseSeason(league, season string) error {
+
+	return b.updateLeagues(func(leagues Leagues) (Leagues, error) {
+		return leagues.closeSeason(league, season, time.Now())
+	})
+}
+
+// GetSeasons returns the seasons of league in the order they started
+func (b *BoltPlayerStore) GetSeasons(league string) []Season {
+
+	leagues, err := b.viewLeagues()
+
+	if err != nil {
+		log.Printf("%s %v", ErrLoadingPlayerStore, err)
+	}
+
+	return leagues.seasons(league)
+}
+
+// GetLeagueTable returns the League for a season of league sorted by wins,
+// or the league's totals across its seasons when season is empty
+func (b *BoltPlayerStore) GetLeagueTable(league, season string) (League, error) {
+
+	leagues, err := b.viewLeagues()
+
+	if err != nil {
+		return nil, ErrLoadingPlayerStore
+	}
+
+	return leagues.table(league, season)
+}
+
+func (b *BoltPlayerStore) viewLeagues() (Leagues, error) {
+
+	var leagues Leagues
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		leagues, err = getLeagues(tx)
+		return err
+	})
+
+	return leagues, err
+}
+
+// updateLeagues reads the Leagues for apply to change, then writes them back if apply succeeds
+func (b *BoltPlayerStore) updateLeagues(apply func(Leagues) (Leagues, error)) error {
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		leagues, err := getLeagues(tx)
+
+		if err != nil {
+			return err
+		}
+
+		leagues, err = apply(leagues)
+
+		if err != nil {
+			return err
+		}
+
+		return putLeagues(tx, leagues)
+	})
+}
+
+func getLeagues(tx *bolt.Tx) (Leagues, error) {
+
+	var leagues Leagues
+
+	err := json.Unmarshal(tx.Bucket(leaguesBucket).Get(leaguesKey), &leagues)
+
+	if err != nil {
+		return leagues, ErrLoadingPlayerStore
+	}
+
+	return leagues, nil
+}
+
+func putLeagues(tx *bolt.Tx, leagues Leagues) error {
+
+	v, err := json.Marshal(leagues)
+
+	if err != nil {
+		return ErrEncode
+	}
+
+	err = tx.Bucket(leaguesBucket).Put(leaguesKey, v)
+
+	if err != nil {
+		return ErrFileWrite
+	}
+
+	return nil
+}
+
+// GetRatings returns every player's rating, highest first
+func (b *BoltPlayerStore) GetRatings() Ratings {
+
+	ratings := Ratings{}
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(ratingsBucket).ForEach(func(_, v []byte) error {
+			var rating Rating
+			err := json.Unmarshal(v, &rating)
+			if err != nil {
+				return err
+			}
+			ratings = append(ratings, rating)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Printf("%s %v", ErrLoadingPlayerStore, err)
+	}
+
+	return ratings.sorted()
+}
+
+// updateRatings rates the game with the DefaultRatingEngine and writes back the changed Ratings
+func updateRatings(tx *bolt.Tx, game GameRecord) error {
+
+	bucket := tx.Bucket(ratingsBucket)
+	ratings := Ratings{}
+
+	for _, name := range game.participants() {
+		v := bucket.Get(playerKey(name))
+
+		if v == nil {
+			continue
+		}
+
+		var rating Rating
+		err := json.Unmarshal(v, &rating)
+
+		if err != nil {
+			return ErrLoadingPlayerStore
+		}
+
+		ratings = append(ratings, rating)
+	}
+
+	for _, rating := range DefaultRatingEngine.Rate(ratings, game) {
+		v, err := json.Marshal(rating)
+
+		if err != nil {
+			return ErrEncode
+		}
+
+		err = bucket.Put(playerKey(rating.Name), v)
+
+		if err != nil {
+			return ErrFileWrite
+		}
+	}
+
+	return nil
+}
+
+// GetGames returns the games matching query in the order they started
+func (b *BoltPlayerStore) GetGames(query GameQuery) []GameRecord {
+
+	games := []GameRecord{}
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).ForEach(func(_, v []byte) error {
+			var game GameRecord
+			err := json.Unmarshal(v, &game)
+			if err != nil {
+				return err
+			}
+			games = append(games, game)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Printf("%s %v", ErrLoadingPlayerStore, err)
+	}
+
+	return filterGames(games, query)
+}
+
+// updatePlayers reads the named Players into a League for apply to change, then writes them back
+func updatePlayers(tx *bolt.Tx, names []string, apply func(League) League) error {
+
+	league := League{}
+
+	for _, name := range names {
+		player := Player{Name: name}
+
+		err := getPlayer(tx, name, &player)
+
+		if err != nil {
+			return ErrLoadingPlayerStore
+		}
+
+		league = append(league, player)
+	}
+
+	for _, player := range apply(league) {
+		err := putPlayer(tx, player)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getPlayer decodes the named Player into player, leaving it untouched if they don't exist
+func getPlayer(tx *bolt.Tx, name string, player *Player) error {
+
+	v := tx.Bucket(playersBucket).Get(playerKey(name))
+
+	if v == nil {
+		return nil
+	}
+
+	return json.Unmarshal(v, player)
+}
+
+func putPlayer(tx *bolt.Tx, player Player) error {
+
+	v, err := json.Marshal(player)
+
+	if err != nil {
+		return ErrEncode
+	}
+
+	err = tx.Bucket(playersBucket).Put(playerKey(player.Name), v)
+
+	if err != nil {
+		return ErrFileWrite
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package poker_test
+
+import (
+	"github.com/vetch101/go-tddapp"
+	"testing"
+)
+
+func TestBoltPlayerStore(t *testing.T) {
+
+	database, cleanDatabase := createTempDB(t, "")
+	defer cleanDatabase()
+
+	t.Run("works with a new database", func(t *testing.T) {
+		store, closeStore := mustOpenBoltStore(t, database)
+		defer closeStore()
+
+		poker.AssertLeague(t, store.GetLeague(), poker.League{})
+	})
+
+	t.Run("store wins for new and existing players", func(t *testing.T) {
+		store, closeStore := mustOpenBoltStore(t, database)
+		defer closeStore()
+
+		for _, name := range []string{"Cleo", "Chris", "Chris", ""} {
+			poker.AssertNoError(t, store.PostRecordWin(name))
+		}
+
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Chris"), 2)
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 1)
+		poker.AssertScoreEquals(t, store.GetPlayerScore(""), 1)
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Joe"), 0)
+	})
+
+	t.Run("/league sorted after a reopen", func(t *testing.T) {
+		store, closeStore := mustOpenBoltStore(t, database)
+		defer closeStore()
+
+		want := poker.League{
+			{Name: "Chris", Wins: 2, GamesPlayed: 2},
+			{Name: "", Wins: 1, GamesPlayed: 1},
+			{Name: "Cleo", Wins: 1, GamesPlayed: 1},
+		}
+
+		poker.AssertLeague(t, store.GetLeague(), want)
+	})
+}
+
+func mustOpenBoltStore(t *testing.T, filename string) (*poker.BoltPlayerStore, func()) {
+	t.Helper()
+
+	store, closeStore, err := poker.BoltStoreFromFile(filename)
+
+	if err != nil {
+		t.Fatalf("could not open bolt store %v", err)
+	}
+
+	return store, closeStore
+}
+
+func TestBoltPlayerStoreConformance(t *testing.T) {
+	poker.RunPlayerStoreSuite(t, func(t *testing.T, filename string) (poker.PlayerStore, func()) {
+		return mustOpenBoltStore(t, filename)
+	})
+}
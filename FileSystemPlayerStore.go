@@ -2,105 +2,173 @@ package poker
 
 import (
 	"encoding/json"
-	"log"
 	"os"
-	"sort"
+	"path/filepath"
+	"sync"
+	"time"
 )
 
-// FileSystemPlayerStore is a json.Encoder that stores a League of Players[]
+// FileSystemPlayerStore stores a League of Players[] in a versioned json file.
+// It is safe for concurrent use.
 type FileSystemPlayerStore struct {
-	database *json.Encoder
+	mu       sync.RWMutex
+	fs       FileSystem
+	filename string
 	league   League
+	games    []GameRecord
+	ratings  Ratings
+	leagues  Leagues
 }
 
-// NewFileSystemPlayerStore is a constructor method for the FileSystemPlayerStore
+// NewFileSystemPlayerStore is a constructor method for the FileSystemPlayerStore kept in the file
+// named by file, which it opens with OpenFileSystemPlayerStore
 func NewFileSystemPlayerStore(file *os.File) (*FileSystemPlayerStore, error) {
+	return OpenFileSystemPlayerStore(OSFileSystem{}, file.Name())
+}
+
+// OpenFileSystemPlayerStore loads the FileSystemPlayerStore kept in filename on fsys,
+// repairing any write that was interrupted part way through
+func OpenFileSystemPlayerStore(fsys FileSystem, filename string) (*FileSystemPlayerStore, error) {
+
+	err := recoverPlayerDBFile(fsys, filename)
+
+	if err != nil {
+		return nil, err
+	}
 
-	err := initializePlayerDBFile(file)
+	data, err := initializePlayerDBFile(fsys, filename)
 
 	if err != nil {
 		return nil, ErrDBInitialize
 	}
 
-	league, err := NewLeague(file)
+	return newFileSystemPlayerStore(data, &FileSystemPlayerStore{fs: fsys, filename: filename})
+}
+
+// newFileSystemPlayerStore loads the db file in data into store, saving it back if it had to be migrated
+func newFileSystemPlayerStore(data []byte, store *FileSystemPlayerStore) (*FileSystemPlayerStore, error) {
+
+	db, migrated, err := decodeDBFile(data)
 
 	if err != nil {
-		return nil, ErrLoadingPlayerStore
+		return nil, err
 	}
 
-	return &FileSystemPlayerStore{
-		database: json.NewEncoder(&Tape{file}),
-		league:   league,
-	}, nil
+	store.league = db.League
+	store.games = db.Games
+	store.ratings = db.Ratings
+	store.leagues = db.Leagues
+
+	if migrated {
+		err = store.save(db)
+		if err != nil && err != ErrDirSync {
+			return nil, err
+		}
+	}
+
+	return store, nil
 }
 
-func initializePlayerDBFile(file *os.File) error {
+// recoverPlayerDBFile deals with a temp file left behind by a crash during a write.
+// A complete temp file is rolled forward over the db, a partial one is thrown away.
+func recoverPlayerDBFile(fsys FileSystem, filename string) error {
+
+	tmpName := tempFileName(filename)
 
-	_, err := file.Seek(0, 0)
+	data, err := readFile(fsys, tmpName)
 
 	if err != nil {
-		return ErrFileSeek
+		return ErrRecover
+	}
+
+	if data == nil {
+		return nil
 	}
 
-	info, err := file.Stat()
+	_, _, err = decodeDBFile(data)
 
 	if err != nil {
-		return ErrFileInfo
+		if fsys.Remove(tmpName) != nil {
+			return ErrRecover
+		}
+		return nil
 	}
 
-	if info.Size() == 0 {
-		_, err := file.Write([]byte("[]"))
+	if fsys.Rename(tmpName, filename) != nil {
+		return ErrRecover
+	}
+
+	return syncDir(fsys, filepath.Dir(filename))
+}
+
+func initializePlayerDBFile(fsys FileSystem, filename string) ([]byte, error) {
+
+	data, err := readFile(fsys, filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		data, err = emptyDBFile()
 		if err != nil {
-			return ErrFileWrite
+			return nil, err
 		}
-		_, err = file.Seek(0, 0)
+		err = writeFileAtomic(fsys, filename, data)
 		if err != nil {
-			return ErrFileSeek
+			return nil, err
 		}
 	}
 
-	return nil
+	return data, nil
 
 }
 
-// FileSystemStoreFromFile retrieves a file and returns the FSPS, its close func and the error
-func FileSystemStoreFromFile(filename string) (*FileSystemPlayerStore, func(), error) {
+// emptyDBFile is the db file a new store starts with
+func emptyDBFile() ([]byte, error) {
 
-	db, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0600)
+	data, err := json.Marshal(dbFile{Version: CurrentDBVersion, League: League{}, Leagues: newLeagues(League{})})
 
 	if err != nil {
-		return nil, nil, ErrFileOpen
+		return nil, ErrEncode
 	}
 
-	closeFunc := func() {
-		e := db.Close()
-		if e != nil {
-			log.Printf(string(ErrFileClose))
-		}
+	return data, nil
+}
+
+// FileSystemStoreFromFile opens the FSPS kept in filename, creating it if it doesn't exist, and returns
+// it with its close func. Nothing is held open between writes, so there's nothing for it to close. A file
+// from a newer version is an ErrDBVersion, so it can be told apart from one that couldn't be read.
+func FileSystemStoreFromFile(filename string) (*FileSystemPlayerStore, func(), error) {
+
+	store, err := OpenFileSystemPlayerStore(OSFileSystem{}, filename)
+
+	if err == ErrDBVersion {
+		return nil, nil, err
 	}
-	store, err := NewFileSystemPlayerStore(db)
 
 	if err != nil {
 		return nil, nil, ErrCreateStore
 	}
 
-	return store, closeFunc, nil
+	return store, func() {}, nil
 
 }
 
-// GetLeague is a method on a FSPlayerStore that sorts the League
+// GetLeague returns a copy of the League sorted by wins
 func (f *FileSystemPlayerStore) GetLeague() League {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 
-	sort.Slice(f.league, func(i, j int) bool {
-		return f.league[i].Wins > f.league[j].Wins
-	})
-	return f.league
+	return f.league.sorted()
 }
 
 // GetPlayerScore returns a player's score (or zero if they don't exist)
 func (f *FileSystemPlayerStore) GetPlayerScore(name string) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 
-	player := f.GetLeague().Find(name)
+	player := f.league.Find(name)
 
 	if player != nil {
 		return player.Wins
@@ -110,21 +178,151 @@ func (f *FileSystemPlayerStore) GetPlayerScore(name string) int {
 }
 
 // PostRecordWin increments a player's score (or creates the player if they don't exist)
+// in the all-time League and the main league
 func (f *FileSystemPlayerStore) PostRecordWin(name string) error {
+	return f.RecordLeagueWin("", "", name)
+}
 
-	player := f.league.Find(name)
+// RecordLeagueWin increments a player's score in the all-time League and a season of league
+func (f *FileSystemPlayerStore) RecordLeagueWin(league, season, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
-	if player != nil {
-		player.Wins++
-	} else {
-		f.league = append(f.league, Player{name, 1})
+	season, err := f.leagues.seasonFor(league, season)
+
+	if err != nil {
+		return err
 	}
 
-	err := f.database.Encode(f.league)
+	db := f.db()
+	db.League = f.league.copyLeague().recordWin(name)
+	db.Leagues = f.leagues.copyLeagues().recordWin(league, season, name)
+
+	return f.commit(db)
+}
+
+// RecordGame adds a finished game to the history kept alongside the League
+// and counts and rates it for everyone who played
+func (f *FileSystemPlayerStore) RecordGame(game GameRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var err error
+	game.Season, err = f.leagues.seasonFor(game.League, game.Season)
+
+	if err != nil {
+		return err
+	}
+
+	db := f.db()
+	db.League = f.league.copyLeague().recordGame(game)
+	db.Ratings = DefaultRatingEngine.Rate(f.ratings.copyRatings(), game)
+	db.Leagues = f.leagues.copyLeagues().recordGame(game)
+
+	// capping the slice makes append copy, so f.games is untouched if the save fails
+	db.Games = append(f.games[:len(f.games):len(f.games)], game)
+
+	return f.commit(db)
+}
+
+// StartSeason opens a new season of league, which wins go into until it is closed
+func (f *FileSystemPlayerStore) StartSeason(league, season string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	leagues, err := f.leagues.copyLeagues().startSeason(Season{League: league, Name: season, Started: time.Now()})
+
+	if err != nil {
+		return err
+	}
+
+	db := f.db()
+	db.Leagues = leagues
+
+	return f.commit(db)
+}
+
+// CloseSeason closes a season of league, leaving its table as it stands
+func (f *FileSystemPlayerStore) CloseSeason(league, season string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	leagues, err := f.leagues.copyLeagues().closeSeason(league, season, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	db := f.db()
+	db.Leagues = leagues
+
+	return f.commit(db)
+}
+
+// GetSeasons returns the seasons of league in the order they started
+func (f *FileSystemPlayerStore) GetSeasons(league string) []Season {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.leagues.seasons(league)
+}
+
+// GetLeagueTable returns the League for a season of league sorted by wins,
+// or the league's totals across its seasons when season is empty
+func (f *FileSystemPlayerStore) GetLeagueTable(league, season string) (League, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.leagues.table(league, season)
+}
+
+// GetGames returns the games matching query in the order they started
+func (f *FileSystemPlayerStore) GetGames(query GameQuery) []GameRecord {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return filterGames(f.games, query)
+}
+
+// GetRatings returns every player's rating, highest first
+func (f *FileSystemPlayerStore) GetRatings() Ratings {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.ratings.sorted()
+}
+
+// db returns what the store holds as a dbFile at the current version
+func (f *FileSystemPlayerStore) db() dbFile {
+	return dbFile{Version: CurrentDBVersion, League: f.league, Games: f.games, Ratings: f.ratings, Leagues: f.leagues}
+}
+
+// commit saves db and makes it what the store holds. Once the rename has happened db is
+// what is on disk, even if the directory could not be synced afterwards.
+func (f *FileSystemPlayerStore) commit(db dbFile) error {
+
+	err := f.save(db)
+
+	if err != nil && err != ErrDirSync {
+		return err
+	}
+
+	f.league = db.League
+	f.games = db.Games
+	f.ratings = db.Ratings
+	f.leagues = db.Leagues
+
+	return err
+}
+
+// save atomically replaces the db file with db
+func (f *FileSystemPlayerStore) save(db dbFile) error {
+
+	data, err := json.Marshal(db)
 
 	if err != nil {
 		return ErrEncode
 	}
 
-	return nil
+	return writeFileAtomic(f.fs, f.filename, data)
 }
@@ -1,9 +1,11 @@
 package poker_test
 
 import (
+	"errors"
 	"github.com/vetch101/go-tddapp"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -34,8 +36,8 @@ func TestFileSystemStore(t *testing.T) {
 		got := store.GetLeague()
 
 		want := []poker.Player{
-			{Name: "Chris", Wins: 33},
-			{Name: "Cleo", Wins: 10},
+			{Name: "Chris", Wins: 33, GamesPlayed: 33},
+			{Name: "Cleo", Wins: 10, GamesPlayed: 10},
 		}
 
 		poker.AssertLeague(t, got, want)
@@ -70,6 +72,214 @@ func TestFileSystemStore(t *testing.T) {
 
 }
 
+func TestFileSystemPlayerStoreConformance(t *testing.T) {
+	poker.RunPlayerStoreSuite(t, func(t *testing.T, filename string) (poker.PlayerStore, func()) {
+		store, closeStore, err := poker.FileSystemStoreFromFile(filename)
+		poker.AssertNoError(t, err)
+		return store, closeStore
+	})
+}
+
+var errInjected = errors.New("injected failure")
+
+// faultyFileSystem is an OSFileSystem that fails the step named in failOn,
+// only failing renames onto failRenameTo if it's set
+type faultyFileSystem struct {
+	poker.OSFileSystem
+	failOn       string
+	failRenameTo string
+}
+
+func (fs *faultyFileSystem) OpenFile(name string, flag int, perm os.FileMode) (poker.File, error) {
+	if fs.failOn == "open" && flag&os.O_CREATE != 0 {
+		return nil, errInjected
+	}
+
+	file, err := fs.OSFileSystem.OpenFile(name, flag, perm)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &faultyFile{File: file, fs: fs, isDir: flag == os.O_RDONLY && isDir(name)}, nil
+}
+
+func (fs *faultyFileSystem) Rename(oldpath, newpath string) error {
+	if fs.failOn == "rename" && (fs.failRenameTo == "" || fs.failRenameTo == newpath) {
+		return errInjected
+	}
+	return fs.OSFileSystem.Rename(oldpath, newpath)
+}
+
+type faultyFile struct {
+	poker.File
+	fs    *faultyFileSystem
+	isDir bool
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.fs.failOn == "write" {
+		n, _ := f.File.Write(p[:len(p)/2])
+		return n, errInjected
+	}
+	return f.File.Write(p)
+}
+
+func (f *faultyFile) Sync() error {
+	if (f.fs.failOn == "sync" && !f.isDir) || (f.fs.failOn == "syncdir" && f.isDir) {
+		return errInjected
+	}
+	return f.File.Sync()
+}
+
+func (f *faultyFile) Close() error {
+	err := f.File.Close()
+	if f.fs.failOn == "close" && !f.isDir {
+		return errInjected
+	}
+	return err
+}
+
+func isDir(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && info.IsDir()
+}
+
+func TestFileSystemStoreAtomicWrites(t *testing.T) {
+
+	cases := []struct {
+		failOn   string
+		wantErr  error
+		wantWins int
+	}{
+		{"open", poker.ErrFileOpen, 10},
+		{"write", poker.ErrFileWrite, 10},
+		{"sync", poker.ErrFileSync, 10},
+		{"close", poker.ErrFileClose, 10},
+		{"rename", poker.ErrFileRename, 10},
+		{"syncdir", poker.ErrDirSync, 11},
+	}
+
+	for _, c := range cases {
+		t.Run("failing to "+c.failOn+" keeps the db whole", func(t *testing.T) {
+			database, cleanDatabase := createTempDB(t, `[{"Name": "Cleo", "Wins": 10}]`)
+			defer cleanDatabase()
+
+			fs := &faultyFileSystem{}
+			store, err := poker.OpenFileSystemPlayerStore(fs, database)
+			poker.AssertNoError(t, err)
+
+			fs.failOn = c.failOn
+			err = store.PostRecordWin("Cleo")
+
+			if err != c.wantErr {
+				t.Errorf("got error %v want %v", err, c.wantErr)
+			}
+
+			poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), c.wantWins)
+			assertFileNotExists(t, database+".tmp")
+
+			reopened, err := poker.OpenFileSystemPlayerStore(poker.OSFileSystem{}, database)
+			poker.AssertNoError(t, err)
+			poker.AssertScoreEquals(t, reopened.GetPlayerScore("Cleo"), c.wantWins)
+		})
+	}
+}
+
+func TestFileSystemStoreRecovery(t *testing.T) {
+
+	t.Run("rolls forward a complete temp file", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `[{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		writeTempDBFile(t, database+".tmp", `[{"Name": "Cleo", "Wins": 11}]`)
+
+		store, err := poker.OpenFileSystemPlayerStore(poker.OSFileSystem{}, database)
+		poker.AssertNoError(t, err)
+
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 11)
+		assertFileNotExists(t, database+".tmp")
+	})
+
+	t.Run("discards a half written temp file", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `[{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		writeTempDBFile(t, database+".tmp", `[{"Name": "Cleo", "Wi`)
+
+		store, err := poker.OpenFileSystemPlayerStore(poker.OSFileSystem{}, database)
+		poker.AssertNoError(t, err)
+
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 10)
+		assertFileNotExists(t, database+".tmp")
+	})
+
+	t.Run("recovers the db file it's handed too", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `[{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		writeTempDBFile(t, database+".tmp", `[{"Name": "Cleo", "Wins": 11}]`)
+
+		file, err := os.Open(database)
+		poker.AssertNoError(t, err)
+		defer file.Close()
+
+		store, err := poker.NewFileSystemPlayerStore(file)
+		poker.AssertNoError(t, err)
+
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 11)
+		assertFileNotExists(t, database+".tmp")
+	})
+
+	t.Run("creates a missing db file", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, "")
+		defer cleanDatabase()
+		os.Remove(database)
+
+		store, err := poker.OpenFileSystemPlayerStore(poker.OSFileSystem{}, database)
+		poker.AssertNoError(t, err)
+
+		poker.AssertLeague(t, store.GetLeague(), poker.League{})
+	})
+}
+
+// createTempDB creates a db file with initialData in its own directory,
+// so that temp files written next to it are cleaned up too
+func createTempDB(t *testing.T, initialData string) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "db")
+
+	if err != nil {
+		t.Fatalf("could not create temp dir %v", err)
+	}
+
+	database := filepath.Join(dir, "game.db.json")
+	writeTempDBFile(t, database, initialData)
+
+	return database, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func writeTempDBFile(t *testing.T, filename, data string) {
+	t.Helper()
+
+	err := ioutil.WriteFile(filename, []byte(data), 0600)
+
+	if err != nil {
+		t.Fatalf("could not write %s %v", filename, err)
+	}
+}
+
+func assertFileNotExists(t *testing.T, filename string) {
+	t.Helper()
+
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("expected %s to not exist", filename)
+	}
+}
+
 func createTempFile(t *testing.T, initialData string) (*os.File, func()) {
 	t.Helper()
 
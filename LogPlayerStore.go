@@ -0,0 +1,523 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCompactEvery is how many records the log grows by before it is compacted into a snapshot
+const DefaultCompactEvery = 1000
+
+// LogPlayerStore keeps the League in memory and appends one record per win or game to a log
+// file, so recording a win doesn't rewrite the whole League. On startup the League is rebuilt from
+// the last snapshot plus the log, and every compactEvery records the log is folded into a new
+// snapshot and a new generation of the log is started. It is safe for concurrent use.
+type LogPlayerStore struct {
+	mu           sync.RWMutex
+	fs           FileSystem
+	filename     string
+	logFile      File
+	generation   int
+	size         int64
+	records      int
+	compactEvery int
+	league       League
+	games        []GameRecord
+	ratings      Ratings
+	leagues      Leagues
+}
+
+// logRecord is a single line of the log: a win for Name in a season of League,
+// a finished Game, or a season being started or closed. Every log after the first
+// starts with a record of its Generation, which counts the compactions before it.
+type logRecord struct {
+	Generation    int         `json:",omitempty"`
+	Name          string      `json:",omitempty"`
+	League        string      `json:",omitempty"`
+	Season        string      `json:",omitempty"`
+	Game          *GameRecord `json:",omitempty"`
+	SeasonStarted *Season     `json:",omitempty"`
+	SeasonClosed  *Season     `json:",omitempty"`
+}
+
+// logSnapshot is what the store held once the first Covers bytes of the Generation of the log had been applied
+type logSnapshot struct {
+	Generation int
+	Covers     int64
+	League     League
+	Games      []GameRecord
+	Ratings    Ratings
+	Leagues    Leagues
+}
+
+func snapshotFileName(filename string) string {
+	return filename + ".snapshot"
+}
+
+// NewLogPlayerStore loads the LogPlayerStore kept in filename (and its snapshot) on fsys,
+// compacting the log every compactEvery records
+func NewLogPlayerStore(fsys FileSystem, filename string, compactEvery int) (*LogPlayerStore, error) {
+
+	// every step of a compaction is safe to roll back, so staged files can always be dropped
+	for _, name := range []string{filename, snapshotFileName(filename)} {
+		err := removeTempFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l := &LogPlayerStore{
+		fs:           fsys,
+		filename:     filename,
+		compactEvery: compactEvery,
+	}
+
+	err := l.load()
+
+	if err != nil {
+		return nil, err
+	}
+
+	l.logFile, err = fsys.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+
+	if err != nil {
+		return nil, ErrFileOpen
+	}
+
+	return l, nil
+}
+
+// LogStoreFromFile opens the log kept in filename and returns the store, its close func and the error
+func LogStoreFromFile(filename string) (*LogPlayerStore, func(), error) {
+
+	store, err := NewLogPlayerStore(OSFileSystem{}, filename, DefaultCompactEvery)
+
+	if err != nil {
+		return nil, nil, ErrCreateStore
+	}
+
+	closeFunc := func() {
+		e := store.Close()
+		if e != nil {
+			log.Printf(string(ErrFileClose))
+		}
+	}
+
+	return store, closeFunc, nil
+}
+
+// load rebuilds the League from the snapshot and replays the log over it
+func (l *LogPlayerStore) load() error {
+
+	snapshot := logSnapshot{League: League{}, Games: []GameRecord{}, Ratings: Ratings{}}
+
+	data, err := readFile(l.fs, snapshotFileName(l.filename))
+
+	if err != nil {
+		return ErrLoadingPlayerStore
+	}
+
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &snapshot)
+		if err != nil {
+			return ErrLoadingPlayerStore
+		}
+	}
+
+	data, err = readFile(l.fs, l.filename)
+
+	if err != nil {
+		return ErrLoadingPlayerStore
+	}
+
+	l.generation, err = logGeneration(data)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case l.generation == snapshot.Generation+1:
+		// the log was started by a compaction after it wrote the snapshot, so the snapshot covers none of it
+		snapshot.Covers = 0
+	case l.generation != snapshot.Generation:
+		return ErrLoadingPlayerStore
+	case int64(len(data)) < snapshot.Covers:
+		return ErrLoadingPlayerStore
+	}
+
+	// snapshots from before leagues start the main league off with every win so far
+	if snapshot.Leagues.Tables == nil {
+		snapshot.Leagues = newLeagues(snapshot.League)
+	}
+
+	if snapshot.Leagues.Seasons == nil {
+		snapshot.Leagues.Seasons = []Season{}
+	}
+
+	l.league, l.games, l.ratings, l.leagues = snapshot.League, snapshot.Games, snapshot.Ratings, snapshot.Leagues
+
+	size, records, err := l.replayLog(data[snapshot.Covers:])
+
+	if err != nil {
+		return err
+	}
+
+	l.size = snapshot.Covers + size
+	l.records = records
+
+	if l.size < int64(len(data)) {
+		return l.truncateLog()
+	}
+
+	return nil
+}
+
+// logGeneration is the generation the log in data starts with, which is 0 for the first log
+func logGeneration(data []byte) (int, error) {
+
+	end := bytes.IndexByte(data, '\n')
+
+	if end < 0 {
+		return 0, nil
+	}
+
+	var record logRecord
+
+	if json.Unmarshal(data[:end], &record) != nil {
+		return 0, ErrLoadingPlayerStore
+	}
+
+	return record.Generation, nil
+}
+
+// replayLog applies each complete record in data to the store, returning the number of bytes
+// and records used. A final record without a newline was torn by a crash.
+func (l *LogPlayerStore) replayLog(data []byte) (int64, int, error) {
+
+	var size int64
+	records := 0
+
+	for {
+		end := bytes.IndexByte(data[size:], '\n')
+
+		if end < 0 {
+			return size, records, nil
+		}
+
+		var record logRecord
+		err := json.Unmarshal(data[size:size+int64(end)], &record)
+
+		if err != nil {
+			return 0, 0, ErrLoadingPlayerStore
+		}
+
+		size += int64(end) + 1
+
+		if record.Generation == 0 {
+			l.apply(record)
+			records++
+		}
+	}
+}
+
+// truncateLog cuts the log back to the records that have been applied to the League
+func (l *LogPlayerStore) truncateLog() error {
+
+	data, err := readFile(l.fs, l.filename)
+
+	if err != nil {
+		return err
+	}
+
+	if int64(len(data)) > l.size {
+		data = data[:l.size]
+	}
+
+	return writeFileAtomic(l.fs, l.filename, data)
+}
+
+// reopenLog swaps the append handle for one on the current log file,
+// which is needed whenever the log has been replaced by a rename
+func (l *LogPlayerStore) reopenLog() error {
+
+	if l.logFile != nil {
+		l.logFile.Close()
+	}
+
+	file, err := l.fs.OpenFile(l.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+
+	if err != nil {
+		l.logFile = nil
+		return ErrFileOpen
+	}
+
+	l.logFile = file
+	return nil
+}
+
+func (l *LogPlayerStore) writeSnapshot(snapshot logSnapshot) error {
+
+	data, err := json.Marshal(snapshot)
+
+	if err != nil {
+		return ErrEncode
+	}
+
+	return writeFileAtomic(l.fs, snapshotFileName(l.filename), data)
+}
+
+// GetLeague returns a copy of the League sorted by wins
+func (l *LogPlayerStore) GetLeague() League {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.league.sorted()
+}
+
+// GetPlayerScore returns a player's score (or zero if they don't exist)
+func (l *LogPlayerStore) GetPlayerScore(name string) int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	player := l.league.Find(name)
+
+	if player != nil {
+		return player.Wins
+	}
+
+	return 0
+}
+
+// PostRecordWin appends a win for the player in the main league to the log (creating the player if they don't exist)
+func (l *LogPlayerStore) PostRecordWin(name string) error {
+	return l.RecordLeagueWin("", "", name)
+}
+
+// RecordLeagueWin appends a win for the player in a season of league to the log
+func (l *LogPlayerStore) RecordLeagueWin(league, season, name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	season, err := l.leagues.seasonFor(league, season)
+
+	if err != nil {
+		return err
+	}
+
+	return l.appendRecord(logRecord{Name: name, League: league, Season: season})
+}
+
+// RecordGame appends a finished game to the log and counts and rates it for everyone who played
+func (l *LogPlayerStore) RecordGame(game GameRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var err error
+	game.Season, err = l.leagues.seasonFor(game.League, game.Season)
+
+	if err != nil {
+		return err
+	}
+
+	return l.appendRecord(logRecord{Game: &game})
+}
+
+// StartSeason appends the start of a new season of league to the log
+func (l *LogPlayerStore) StartSeason(league, season string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	started := Season{League: league, Name: season, Started: time.Now()}
+
+	_, err := l.leagues.copyLeagues().startSeason(started)
+
+	if err != nil {
+		return err
+	}
+
+	return l.appendRecord(logRecord{SeasonStarted: &started})
+}
+
+// CloseSeason appends the close of a season of league to the log
+func (l *LogPlayerStore) CloseSeason(league, season string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	closed := Season{League: league, Name: season, Closed: time.Now()}
+
+	_, err := l.leagues.copyLeagues().closeSeason(league, season, closed.Closed)
+
+	if err != nil {
+		return err
+	}
+
+	return l.appendRecord(logRecord{SeasonClosed: &closed})
+}
+
+// GetSeasons returns the seasons of league in the order they started
+func (l *LogPlayerStore) GetSeasons(league string) []Season {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.leagues.seasons(league)
+}
+
+// GetLeagueTable returns the League for a season of league sorted by wins,
+// or the league's totals across its seasons when season is empty
+func (l *LogPlayerStore) GetLeagueTable(league, season string) (League, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.leagues.table(league, season)
+}
+
+// GetRatings returns every player's rating, highest first
+func (l *LogPlayerStore) GetRatings() Ratings {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.ratings.sorted()
+}
+
+// apply makes the change a record in the log stands for. Season records are
+// checked before they're appended, so they can't fail when they're applied.
+func (l *LogPlayerStore) apply(record logRecord) {
+	switch {
+	case record.SeasonStarted != nil:
+		l.leagues, _ = l.leagues.startSeason(*record.SeasonStarted)
+	case record.SeasonClosed != nil:
+		closed := record.SeasonClosed
+		l.leagues, _ = l.leagues.closeSeason(closed.League, closed.Name, closed.Closed)
+	case record.Game != nil:
+		l.games = append(l.games, *record.Game)
+		l.league = l.league.recordGame(*record.Game)
+		l.ratings = DefaultRatingEngine.Rate(l.ratings, *record.Game)
+		l.leagues = l.leagues.recordGame(*record.Game)
+	default:
+		l.league = l.league.recordWin(record.Name)
+		l.leagues = l.leagues.recordWin(record.League, record.Season, record.Name)
+	}
+}
+
+// GetGames returns the games matching query in the order they started
+func (l *LogPlayerStore) GetGames(query GameQuery) []GameRecord {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return filterGames(l.games, query)
+}
+
+// appendRecord writes record to the log and applies it once it is there, compacting when it's due
+func (l *LogPlayerStore) appendRecord(record logRecord) error {
+
+	if l.logFile == nil {
+		err := l.reopenLog()
+		if err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(record)
+
+	if err != nil {
+		return ErrEncode
+	}
+
+	line = append(line, '\n')
+
+	_, err = l.logFile.Write(line)
+
+	if err != nil {
+		// don't leave part of a record for the next one to be appended after
+		if l.truncateLog() == nil {
+			l.reopenLog()
+		}
+		return ErrFileWrite
+	}
+
+	l.apply(record)
+	l.size += int64(len(line))
+	l.records++
+
+	// the record is in the log even if it couldn't be flushed, so it is kept
+	if l.logFile.Sync() != nil {
+		return ErrFileSync
+	}
+
+	// the record is kept whether or not the log can be compacted, which is tried again after the next one
+	if l.records >= l.compactEvery {
+		if err := l.compact(); err != nil {
+			log.Printf("problem compacting the player log %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// Compact folds the log into a new snapshot and starts an empty log
+func (l *LogPlayerStore) Compact() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.compact()
+}
+
+// compact is made of two atomic steps, either of which can be interrupted: snapshot the League
+// along with how much of which generation of the log it covers, then start the next generation.
+// A log a generation on from its snapshot is one the snapshot covers none of, so there's nothing
+// left to record once the new log is in place.
+func (l *LogPlayerStore) compact() error {
+
+	err := l.writeSnapshot(logSnapshot{
+		Generation: l.generation,
+		Covers:     l.size,
+		League:     l.league,
+		Games:      l.games,
+		Ratings:    l.ratings,
+		Leagues:    l.leagues,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	header, err := json.Marshal(logRecord{Generation: l.generation + 1})
+
+	if err != nil {
+		return ErrEncode
+	}
+
+	header = append(header, '\n')
+
+	err = writeFileAtomic(l.fs, l.filename, header)
+
+	if err != nil && err != ErrDirSync {
+		return err
+	}
+
+	l.generation++
+	l.size = int64(len(header))
+	l.records = 0
+
+	return l.reopenLog()
+}
+
+// Close closes the log file
+func (l *LogPlayerStore) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.logFile == nil {
+		return nil
+	}
+
+	err := l.logFile.Close()
+	l.logFile = nil
+
+	return err
+}
@@ -0,0 +1,340 @@
+package poker_test
+
+import (
+	"github.com/vetch101/go-tddapp"
+	"io/ioutil"
+	"testing"
+)
+
+func TestLogPlayerStore(t *testing.T) {
+
+	t.Run("works with a missing log", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, "")
+		defer cleanDatabase()
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer store.Close()
+
+		poker.AssertLeague(t, store.GetLeague(), poker.League{})
+	})
+
+	t.Run("rebuilds the league from the log", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Name":"Cleo"}
+{"Name":"Chris"}
+{"Name":"Chris"}
+`)
+		defer cleanDatabase()
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer store.Close()
+
+		want := poker.League{
+			{Name: "Chris", Wins: 2, GamesPlayed: 2},
+			{Name: "Cleo", Wins: 1, GamesPlayed: 1},
+		}
+		poker.AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("appends wins that survive a reopen", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, "")
+		defer cleanDatabase()
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		poker.AssertNoError(t, store.PostRecordWin("Pepper"))
+		poker.AssertNoError(t, store.PostRecordWin("Pepper"))
+		poker.AssertNoError(t, store.PostRecordWin("Bob"))
+		store.Close()
+
+		assertFileContents(t, database, `{"Name":"Pepper"}
+{"Name":"Pepper"}
+{"Name":"Bob"}
+`)
+
+		reopened := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer reopened.Close()
+
+		poker.AssertScoreEquals(t, reopened.GetPlayerScore("Pepper"), 2)
+		poker.AssertScoreEquals(t, reopened.GetPlayerScore("Bob"), 1)
+	})
+
+	t.Run("drops a record torn by a crash", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Name":"Cleo"}
+{"Name":"Cl`)
+		defer cleanDatabase()
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 1)
+
+		poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+		store.Close()
+
+		reopened := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer reopened.Close()
+
+		poker.AssertScoreEquals(t, reopened.GetPlayerScore("Cleo"), 2)
+	})
+
+	t.Run("fails to load a log corrupted in the middle", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Name":"Cleo"}
+garbage
+{"Name":"Cleo"}
+`)
+		defer cleanDatabase()
+
+		_, err := poker.NewLogPlayerStore(poker.OSFileSystem{}, database, poker.DefaultCompactEvery)
+
+		if err != poker.ErrLoadingPlayerStore {
+			t.Errorf("got error %v want %v", err, poker.ErrLoadingPlayerStore)
+		}
+	})
+}
+
+func TestLogPlayerStoreLeagues(t *testing.T) {
+
+	t.Run("starts the main league off from a snapshot from before leagues", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Name":"Cleo"}
+`)
+		defer cleanDatabase()
+
+		writeTempDBFile(t, database+".snapshot", `{"Covers":0,"League":[{"Name":"Cleo","Wins":2,"GamesPlayed":2}]}`)
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer store.Close()
+
+		mainLeague, err := store.GetLeagueTable("", "")
+		poker.AssertNoError(t, err)
+		poker.AssertLeague(t, mainLeague, poker.League{{Name: "Cleo", Wins: 3, GamesPlayed: 3}})
+	})
+}
+
+func TestLogPlayerStoreConformance(t *testing.T) {
+	poker.RunPlayerStoreSuite(t, func(t *testing.T, filename string) (poker.PlayerStore, func()) {
+		store, closeStore, err := poker.LogStoreFromFile(filename)
+		poker.AssertNoError(t, err)
+		return store, closeStore
+	})
+
+	t.Run("while compacting", func(t *testing.T) {
+		poker.RunPlayerStoreSuite(t, func(t *testing.T, filename string) (poker.PlayerStore, func()) {
+			store := mustOpenLogStore(t, filename, 2)
+			return store, func() { store.Close() }
+		})
+	})
+}
+
+func TestLogPlayerStoreCompaction(t *testing.T) {
+
+	t.Run("compacts the log into a snapshot every n records", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, "")
+		defer cleanDatabase()
+
+		store := mustOpenLogStore(t, database, 3)
+
+		for _, name := range []string{"Pepper", "Bob", "Pepper", "Bob"} {
+			poker.AssertNoError(t, store.PostRecordWin(name))
+		}
+		store.Close()
+
+		assertFileContents(t, database, `{"Generation":1}
+{"Name":"Bob"}
+`)
+
+		reopened := mustOpenLogStore(t, database, 3)
+		defer reopened.Close()
+
+		poker.AssertScoreEquals(t, reopened.GetPlayerScore("Pepper"), 2)
+		poker.AssertScoreEquals(t, reopened.GetPlayerScore("Bob"), 2)
+	})
+
+	t.Run("does not double count when interrupted before the log is reset", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Name":"Cleo"}
+{"Name":"Cleo"}
+`)
+		defer cleanDatabase()
+
+		writeTempDBFile(t, database+".snapshot", `{"Covers":32,"League":[{"Name":"Cleo","Wins":2}]}`)
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer store.Close()
+
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 2)
+	})
+
+	t.Run("keeps every record in a log started after its snapshot, however long it grows", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Generation":1}
+`)
+		defer cleanDatabase()
+
+		// a compaction that stopped once the new log was in place leaves a snapshot still covering the last one
+		writeTempDBFile(t, database+".snapshot", `{"Generation":0,"Covers":32,"League":[{"Name":"Cleo","Wins":2}]}`)
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		for i := 0; i < 5; i++ {
+			poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+		}
+		store.Close()
+
+		reopened := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer reopened.Close()
+
+		poker.AssertScoreEquals(t, reopened.GetPlayerScore("Cleo"), 7)
+	})
+
+	t.Run("loses nothing when a step of a compaction fails", func(t *testing.T) {
+		for _, step := range []string{"snapshot", "log"} {
+			t.Run(step, func(t *testing.T) {
+				database, cleanDatabase := createTempDB(t, "")
+				defer cleanDatabase()
+
+				fsys := &faultyFileSystem{}
+				store, err := poker.NewLogPlayerStore(fsys, database, 3)
+				poker.AssertNoError(t, err)
+
+				poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+				poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+
+				fsys.failOn, fsys.failRenameTo = "rename", database
+				if step == "snapshot" {
+					fsys.failRenameTo = database + ".snapshot"
+				}
+
+				// the win is recorded, so it mustn't look like it failed and be tried again
+				poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+				poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 3)
+				store.Close()
+
+				// the log can grow past what the snapshot covered, and still not be read from where it left off
+				store = mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+				for i := 0; i < 5; i++ {
+					poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+				}
+				store.Close()
+
+				reopened := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+				defer reopened.Close()
+
+				poker.AssertScoreEquals(t, reopened.GetPlayerScore("Cleo"), 8)
+			})
+		}
+	})
+
+	t.Run("loses nothing when a snapshot can't be written after the log is reset", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, "")
+		defer cleanDatabase()
+
+		store, err := poker.NewLogPlayerStore(&resetThenFailFileSystem{log: database}, database, poker.DefaultCompactEvery)
+		poker.AssertNoError(t, err)
+
+		poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+		poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+		poker.AssertNoError(t, store.Compact())
+
+		for i := 0; i < 5; i++ {
+			poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+		}
+		store.Close()
+
+		reopened := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer reopened.Close()
+
+		poker.AssertScoreEquals(t, reopened.GetPlayerScore("Cleo"), 7)
+	})
+
+	t.Run("fails to load a log of a generation its snapshot can't have come before", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Generation":3}
+`)
+		defer cleanDatabase()
+
+		writeTempDBFile(t, database+".snapshot", `{"Generation":1,"Covers":0,"League":[]}`)
+
+		_, err := poker.NewLogPlayerStore(poker.OSFileSystem{}, database, poker.DefaultCompactEvery)
+
+		if err != poker.ErrLoadingPlayerStore {
+			t.Errorf("got error %v want %v", err, poker.ErrLoadingPlayerStore)
+		}
+	})
+
+	t.Run("throws away a staged snapshot", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Name":"Cleo"}
+`)
+		defer cleanDatabase()
+
+		writeTempDBFile(t, database+".snapshot.tmp", `{"Covers":16,"League":[{"Na`)
+
+		store := mustOpenLogStore(t, database, poker.DefaultCompactEvery)
+		defer store.Close()
+
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 1)
+		assertFileNotExists(t, database+".snapshot.tmp")
+	})
+}
+
+func TestStoreFromFile(t *testing.T) {
+
+	t.Run("opens each backend", func(t *testing.T) {
+		for _, backend := range []string{poker.FileBackend, poker.LogBackend, poker.BoltBackend} {
+			database, cleanDatabase := createTempDB(t, "")
+
+			store, closeStore, err := poker.StoreFromFile(backend, database)
+			poker.AssertNoError(t, err)
+
+			poker.AssertNoError(t, store.PostRecordWin("Pepper"))
+			poker.AssertScoreEquals(t, store.GetPlayerScore("Pepper"), 1)
+
+			closeStore()
+			cleanDatabase()
+		}
+	})
+
+	t.Run("rejects an unknown backend", func(t *testing.T) {
+		_, _, err := poker.StoreFromFile("carrier pigeon", "game.db")
+
+		if err != poker.ErrUnknownBackend {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownBackend)
+		}
+	})
+}
+
+// resetThenFailFileSystem fails to write the snapshot of log once log has been replaced,
+// the last step of a compaction that has to record the reset in its snapshot
+type resetThenFailFileSystem struct {
+	poker.OSFileSystem
+	log   string
+	reset bool
+}
+
+func (fs *resetThenFailFileSystem) Rename(oldpath, newpath string) error {
+	if fs.reset && newpath == fs.log+".snapshot" {
+		return errInjected
+	}
+
+	fs.reset = fs.reset || newpath == fs.log
+
+	return fs.OSFileSystem.Rename(oldpath, newpath)
+}
+
+func mustOpenLogStore(t *testing.T, filename string, compactEvery int) *poker.LogPlayerStore {
+	t.Helper()
+
+	store, err := poker.NewLogPlayerStore(poker.OSFileSystem{}, filename, compactEvery)
+
+	if err != nil {
+		t.Fatalf("could not open log store %v", err)
+	}
+
+	return store
+}
+
+func assertFileContents(t *testing.T, filename, want string) {
+	t.Helper()
+
+	got, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		t.Fatalf("could not read %s %v", filename, err)
+	}
+
+	if string(got) != want {
+		t.Errorf("got %q in %s want %q", string(got), filename, want)
+	}
+}
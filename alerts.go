@@ -0,0 +1,187 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// BlindEventType is what happened to a game's blinds
+type BlindEventType int
+
+// The things that happen to a game's blinds
+const (
+	LevelStarted BlindEventType = iota
+	BreakStarted
+	BreakEnded
+)
+
+var blindEventTypeNames = []string{"level", "break-start", "break-end"}
+
+func (e BlindEventType) String() string {
+	if e < LevelStarted || e > BreakEnded {
+		return "unknown"
+	}
+	return blindEventTypeNames[e]
+}
+
+// MarshalText writes the BlindEventType as its name, such as "break-start"
+func (e BlindEventType) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText reads the BlindEventType from its name
+func (e *BlindEventType) UnmarshalText(text []byte) error {
+	for i, name := range blindEventTypeNames {
+		if string(text) == name {
+			*e = BlindEventType(i)
+			return nil
+		}
+	}
+	return ErrUnknownBlindEvent
+}
+
+// BlindEvent is a level of blinds starting or a break starting or ending. Level counts from 1
+// and during a break is the level before it. Next is how long until the next level starts,
+// which is 0 for the last level.
+type BlindEvent struct {
+	Type       BlindEventType `json:"type"`
+	Level      int            `json:"level"`
+	SmallBlind int            `json:"small_blind,omitempty"`
+	BigBlind   int            `json:"big_blind,omitempty"`
+	Ante       int            `json:"ante,omitempty"`
+	Next       Duration       `json:"next,omitempty"`
+}
+
+// BlindSink is somewhere blind events are sent as they happen
+type BlindSink interface {
+	Alert(event BlindEvent) error
+}
+
+// BlindSinkFunc lets a func be used as a BlindSink
+type BlindSinkFunc func(event BlindEvent) error
+
+// Alert sends the event to the func
+func (f BlindSinkFunc) Alert(event BlindEvent) error {
+	return f(event)
+}
+
+// Renderer makes a BlindSink that writes blind events to out
+type Renderer func(out io.Writer) BlindSink
+
+// The names of the renderers that RendererFor knows
+const (
+	TextAlerts = "text"
+	JSONAlerts = "json"
+)
+
+// RendererFor returns the Renderer called name
+func RendererFor(name string) (Renderer, error) {
+	switch name {
+	case TextAlerts:
+		return TextRenderer, nil
+	case JSONAlerts:
+		return JSONRenderer, nil
+	}
+	return nil, ErrUnknownRenderer
+}
+
+// TextRenderer writes each blind event to out as a line of text, such as "Blind is now 200"
+func TextRenderer(out io.Writer) BlindSink {
+	return BlindSinkFunc(func(event BlindEvent) error {
+		_, err := fmt.Fprintln(out, textAlert(event))
+		return err
+	})
+}
+
+func textAlert(event BlindEvent) string {
+	switch event.Type {
+	case BreakStarted:
+		return fmt.Sprintf("Break for %v", time.Duration(event.Next))
+	case BreakEnded:
+		return "Break is over"
+	}
+
+	if event.Ante > 0 {
+		return fmt.Sprintf("Blind is now %d, ante %d", event.BigBlind, event.Ante)
+	}
+
+	return fmt.Sprintf("Blind is now %d", event.BigBlind)
+}
+
+// JSONRenderer writes each blind event to out as a line of JSON
+func JSONRenderer(out io.Writer) BlindSink {
+	encoder := json.NewEncoder(out)
+	return BlindSinkFunc(func(event BlindEvent) error {
+		return encoder.Encode(event)
+	})
+}
+
+// WebSocketRenderer writes each blind event to out as a single JSON message that holds it under "blind",
+// which tells it apart from the other messages a game sends to a websocket
+func WebSocketRenderer(out io.Writer) BlindSink {
+	return BlindSinkFunc(func(event BlindEvent) error {
+		message, err := json.Marshal(struct {
+			Blind BlindEvent `json:"blind"`
+		}{event})
+
+		if err != nil {
+			return ErrEncode
+		}
+
+		_, err = out.Write(message)
+		return err
+	})
+}
+
+// AlerterRenderer sends each level of blinds to alerter as an alert that's due now, with breaks written
+// to out the way TextRenderer writes them, as a BlindAlerter only knows about blinds
+func AlerterRenderer(alerter BlindAlerter) Renderer {
+	return func(out io.Writer) BlindSink {
+		return BlindSinkFunc(func(event BlindEvent) error {
+			if event.Type != LevelStarted {
+				return TextRenderer(out).Alert(event)
+			}
+			alerter.ScheduledAlertAt(0, event.BigBlind, out)
+			return nil
+		})
+	}
+}
+
+// Schedule returns the events of the structure's blinds along with when in a game they happen
+func (s BlindStructure) Schedule() []ScheduledBlind {
+
+	var schedule []ScheduledBlind
+	var at time.Duration
+	number := 0
+
+	for i, level := range s.Levels {
+		next := level.Duration
+
+		if i == len(s.Levels)-1 {
+			next = 0
+		}
+
+		if level.Break {
+			schedule = append(schedule,
+				ScheduledBlind{At: at, Event: BlindEvent{Type: BreakStarted, Level: number, Next: next}},
+				ScheduledBlind{At: at + time.Duration(level.Duration), Event: BlindEvent{Type: BreakEnded, Level: number}},
+			)
+		} else {
+			number++
+			schedule = append(schedule, ScheduledBlind{At: at, Event: BlindEvent{
+				Type:       LevelStarted,
+				Level:      number,
+				SmallBlind: level.SmallBlind,
+				BigBlind:   level.BigBlind,
+				Ante:       level.Ante,
+				Next:       next,
+			}})
+		}
+
+		at += time.Duration(level.Duration)
+	}
+
+	return schedule
+}
@@ -0,0 +1,149 @@
+package poker_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var breakStructure = poker.BlindStructure{Levels: []poker.BlindLevel{
+	{SmallBlind: 50, BigBlind: 100, Duration: poker.Duration(10 * time.Minute)},
+	{Break: true, Duration: poker.Duration(5 * time.Minute)},
+	{SmallBlind: 100, BigBlind: 200, Ante: 20},
+}}
+
+func TestBlindStructureSchedule(t *testing.T) {
+
+	want := []poker.ScheduledBlind{
+		{At: 0, Event: poker.BlindEvent{Type: poker.LevelStarted, Level: 1, SmallBlind: 50, BigBlind: 100, Next: poker.Duration(10 * time.Minute)}},
+		{At: 10 * time.Minute, Event: poker.BlindEvent{Type: poker.BreakStarted, Level: 1, Next: poker.Duration(5 * time.Minute)}},
+		{At: 15 * time.Minute, Event: poker.BlindEvent{Type: poker.BreakEnded, Level: 1}},
+		{At: 15 * time.Minute, Event: poker.BlindEvent{Type: poker.LevelStarted, Level: 2, SmallBlind: 100, BigBlind: 200, Ante: 20}},
+	}
+
+	if got := breakStructure.Schedule(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+}
+
+func TestRenderers(t *testing.T) {
+
+	events := []poker.BlindEvent{
+		{Type: poker.LevelStarted, Level: 1, SmallBlind: 50, BigBlind: 100, Next: poker.Duration(10 * time.Minute)},
+		{Type: poker.BreakStarted, Level: 1, Next: poker.Duration(5 * time.Minute)},
+		{Type: poker.BreakEnded, Level: 1},
+		{Type: poker.LevelStarted, Level: 2, SmallBlind: 100, BigBlind: 200, Ante: 20},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		renderAll(t, poker.TextRenderer(out), events)
+
+		want := "Blind is now 100\nBreak for 5m0s\nBreak is over\nBlind is now 200, ante 20\n"
+
+		if out.String() != want {
+			t.Errorf("got %q want %q", out.String(), want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		renderAll(t, poker.JSONRenderer(out), events)
+
+		decoder := json.NewDecoder(out)
+
+		for _, want := range events {
+			var got poker.BlindEvent
+			poker.AssertNoError(t, decoder.Decode(&got))
+
+			if got != want {
+				t.Errorf("got %+v want %+v", got, want)
+			}
+		}
+	})
+
+	t.Run("json names the event and its times", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		renderAll(t, poker.JSONRenderer(out), events[1:2])
+
+		want := `{"type":"break-start","level":1,"next":"5m0s"}` + "\n"
+
+		if out.String() != want {
+			t.Errorf("got %s want %s", out.String(), want)
+		}
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		renderAll(t, poker.WebSocketRenderer(out), events[:1])
+
+		want := `{"blind":{"type":"level","level":1,"small_blind":50,"big_blind":100,"next":"10m0s"}}`
+
+		if out.String() != want {
+			t.Errorf("got %s want %s", out.String(), want)
+		}
+	})
+
+	t.Run("alerter", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		out := &bytes.Buffer{}
+		renderAll(t, poker.AlerterRenderer(alerter)(out), events)
+
+		want := []ScheduledAlert{{0, 100}, {0, 200}}
+
+		if !reflect.DeepEqual(alerter.sent(), want) {
+			t.Errorf("got %v sent to the alerter want %v", alerter.sent(), want)
+		}
+
+		if want := "Break for 5m0s\nBreak is over\n"; out.String() != want {
+			t.Errorf("got %q want %q", out.String(), want)
+		}
+	})
+}
+
+func TestRendererFor(t *testing.T) {
+
+	for _, name := range []string{poker.TextAlerts, poker.JSONAlerts} {
+		if _, err := poker.RendererFor(name); err != nil {
+			t.Errorf("got error %v for %s", err, name)
+		}
+	}
+
+	if _, err := poker.RendererFor("morse"); err != poker.ErrUnknownRenderer {
+		t.Errorf("got error %v want %v", err, poker.ErrUnknownRenderer)
+	}
+}
+
+func TestGame_RendersBlindEvents(t *testing.T) {
+	out := &bytes.Buffer{}
+	clock := poker.NewFakeClock(time.Now())
+	game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+	game.SetClock(clock)
+	game.SetBlindStructure(breakStructure)
+	game.SetRenderer(poker.TextRenderer)
+
+	game.Start(3, out)
+	defer game.Finish("Ruth")
+
+	clock.Advance(12 * time.Minute)
+
+	if want := "Blind is now 100\nBreak for 5m0s\n"; out.String() != want {
+		t.Errorf("got %q want %q", out.String(), want)
+	}
+
+	clock.Advance(3 * time.Minute)
+
+	if want := "Blind is now 100\nBreak for 5m0s\nBreak is over\nBlind is now 200, ante 20\n"; out.String() != want {
+		t.Errorf("got %q want %q", out.String(), want)
+	}
+}
+
+func renderAll(t *testing.T, sink poker.BlindSink, events []poker.BlindEvent) {
+	t.Helper()
+	for _, event := range events {
+		poker.AssertNoError(t, sink.Alert(event))
+	}
+}
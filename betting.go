@@ -0,0 +1,674 @@
+package poker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ActionType is what a player does when it's their turn to bet
+type ActionType int
+
+// The actions a player can take
+const (
+	Fold ActionType = iota
+	Check
+	Call
+	Bet
+	Raise
+	AllIn
+)
+
+var actionNames = []string{"fold", "check", "call", "bet", "raise", "all-in"}
+
+func (a ActionType) String() string {
+	if a < Fold || a > AllIn {
+		return "unknown"
+	}
+	return actionNames[a]
+}
+
+// Action is a player's turn to bet. The Amount of a Bet or Raise is what their bet
+// for the street is made up to, not how much is added to it.
+type Action struct {
+	Type   ActionType
+	Amount int
+}
+
+func (a Action) String() string {
+	if a.Type == Bet || a.Type == Raise {
+		return fmt.Sprintf("%s %d", a.Type, a.Amount)
+	}
+	return a.Type.String()
+}
+
+// ParseAction reads an action written the way Action.String writes it, such as "call" or "raise 400".
+// Input that doesn't start with an action gives ErrNotAnAction.
+func ParseAction(input string) (Action, error) {
+
+	fields := strings.Fields(strings.ToLower(input))
+
+	if len(fields) == 0 {
+		return Action{}, ErrNotAnAction
+	}
+
+	for i, name := range actionNames {
+		if fields[0] != name {
+			continue
+		}
+
+		action := Action{Type: ActionType(i)}
+		takesAmount := action.Type == Bet || action.Type == Raise
+
+		if takesAmount != (len(fields) == 2) || len(fields) > 2 {
+			return Action{}, ErrBadAction
+		}
+
+		if takesAmount {
+			amount, err := strconv.Atoi(fields[1])
+			if err != nil || amount <= 0 {
+				return Action{}, ErrBadAction
+			}
+			action.Amount = amount
+		}
+
+		return action, nil
+	}
+
+	return Action{}, ErrNotAnAction
+}
+
+// BettingSeat is a player's chips during a hand
+type BettingSeat struct {
+	Chips     int
+	Bet       int
+	Committed int
+	Folded    bool
+}
+
+// AllIn reports whether the seat has put every chip it had into the pot
+func (s BettingSeat) AllIn() bool {
+	return !s.Folded && s.Chips == 0
+}
+
+// Pot is an amount of chips and the seats that can win it. There is a side pot
+// for each level a player went all in at, which only those who matched it can win.
+type Pot struct {
+	Amount   int
+	Eligible []int
+}
+
+// PotAward is a Pot and the seats that won it, who share it out between them
+type PotAward struct {
+	Pot
+	Winners []int
+}
+
+// Betting is the chips, bets and pots of a single hand. The blinds are posted by the two seats
+// after the button (or by the button and the other seat heads up) and the first to act pre-flop
+// is the seat after the big blind. On later streets the first seat after the button still
+// in the hand acts first. A street's betting is over once everyone who can still bet has acted
+// and matched the highest bet. How much can be bet goes by its BettingStructure, which is no-limit
+// unless it's set to another.
+type Betting struct {
+	seats      []BettingSeat
+	acted      []bool
+	button     int
+	bigBlind   int
+	streets    []Street
+	round      int
+	street     Street
+	toAct      int
+	currentBet int
+	minRaise   int
+	raises     int
+	structure  BettingStructure
+	showing    func(seat int) HandValue
+}
+
+// holdEmStreets are the streets bet on in a hand with a board
+var holdEmStreets = []Street{PreFlop, Flop, Turn, River}
+
+// studStreets are the streets bet on in a hand of stud
+var studStreets = []Street{ThirdStreet, FourthStreet, FifthStreet, SixthStreet, SeventhStreet}
+
+// NewBetting starts the betting for a hand between seats with the chips in stacks, posting the antes and blinds of level
+func NewBetting(stacks []int, button int, level BlindLevel) (*Betting, error) {
+
+	b, err := newBetting(stacks, button, level, holdEmStreets)
+
+	if err != nil {
+		return nil, err
+	}
+
+	smallBlindSeat := b.next(button)
+
+	// heads up the button posts the small blind
+	if len(stacks) == 2 {
+		smallBlindSeat = button
+	}
+
+	bigBlindSeat := b.next(smallBlindSeat)
+
+	b.post(smallBlindSeat, level.SmallBlind)
+	b.post(bigBlindSeat, level.BigBlind)
+
+	// the big blind is the street's first bet
+	b.currentBet = level.BigBlind
+	b.raises = 1
+	b.toAct = b.nextToAct(bigBlindSeat)
+
+	return b, nil
+}
+
+// NewStudBetting starts the betting for a hand of stud between seats with the chips in stacks, posting the antes
+// of level. The bringIn seat brings in the betting for the small blind, or a chip if there's no small blind, which the
+// first raise can complete to the big blind. On later streets the seat with the best hand showing, going by showing,
+// bets first.
+func NewStudBetting(stacks []int, bringIn int, level BlindLevel, showing func(seat int) HandValue) (*Betting, error) {
+
+	if bringIn < 0 || bringIn >= len(stacks) {
+		return nil, ErrDealPlayers
+	}
+
+	// the seat before the bring-in stands in for the button, so odd chips go round from the bring-in
+	b, err := newBetting(stacks, (bringIn+len(stacks)-1)%len(stacks), level, studStreets)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.showing = showing
+
+	// there's always a pot to win, even when the blinds start at the big blind
+	if level.SmallBlind > 0 {
+		b.post(bringIn, level.SmallBlind)
+	} else {
+		b.post(bringIn, 1)
+	}
+
+	b.currentBet = b.seats[bringIn].Bet
+	b.minRaise = level.BigBlind - b.currentBet
+	b.toAct = b.nextToAct(bringIn)
+
+	return b, nil
+}
+
+// newBetting seats stacks for betting on streets and posts the antes of level
+func newBetting(stacks []int, button int, level BlindLevel, streets []Street) (*Betting, error) {
+
+	if len(stacks) < 2 || button < 0 || button >= len(stacks) {
+		return nil, ErrDealPlayers
+	}
+
+	b := &Betting{
+		seats:     make([]BettingSeat, len(stacks)),
+		acted:     make([]bool, len(stacks)),
+		button:    button,
+		bigBlind:  level.BigBlind,
+		minRaise:  level.BigBlind,
+		streets:   streets,
+		street:    streets[0],
+		structure: NoLimit{},
+	}
+
+	for i, chips := range stacks {
+		if chips <= 0 {
+			return nil, ErrNoChips
+		}
+		b.seats[i].Chips = chips
+	}
+
+	// antes go straight into the pot rather than counting towards the street's bet
+	for i := range b.seats {
+		ante := smaller(level.Ante, b.seats[i].Chips)
+		b.seats[i].Chips -= ante
+		b.seats[i].Committed += ante
+	}
+
+	return b, nil
+}
+
+// SetStructure limits the bets and raises to what structure allows
+func (b *Betting) SetStructure(structure BettingStructure) {
+	b.structure = structure
+}
+
+// Structure is the betting structure that limits the bets and raises
+func (b *Betting) Structure() BettingStructure {
+	return b.structure
+}
+
+// MinBet is the least the seat to act can make their bet up to by betting or raising, unless they go all in
+// for less. It's 0 if they can't bet or raise.
+func (b *Betting) MinBet() int {
+	least, _, _ := b.limits()
+	return least
+}
+
+// MaxBet is the most the seat to act can make their bet up to by betting or raising, which is never more
+// than every chip they have. It's 0 if they can't bet or raise.
+func (b *Betting) MaxBet() int {
+	_, most, _ := b.limits()
+	return most
+}
+
+// limits are the least and most the seat to act can bet or raise to, going by the betting structure,
+// and whether they can bet or raise at all
+func (b *Betting) limits() (int, int, bool) {
+
+	if b.toAct < 0 {
+		return 0, 0, false
+	}
+
+	// an all in for less than a full raise doesn't reopen the betting for a seat that's already acted
+	if b.acted[b.toAct] {
+		return 0, 0, false
+	}
+
+	s := b.seats[b.toAct]
+
+	situation := BetSituation{
+		Round:      b.round,
+		CurrentBet: b.currentBet,
+		Raises:     b.raises,
+		LastRaise:  b.minRaise,
+		BigBlind:   b.bigBlind,
+		Bet:        s.Bet,
+		Chips:      s.Chips,
+	}
+
+	for _, seat := range b.seats {
+		situation.Pot += seat.Committed
+	}
+
+	least, most, ok := b.structure.Limits(situation)
+
+	if !ok {
+		return 0, 0, false
+	}
+
+	return least, smaller(most, s.Bet+s.Chips), true
+}
+
+// next is the seat after seat, round the table
+func (b *Betting) next(seat int) int {
+	return (seat + 1) % len(b.seats)
+}
+
+// post puts up to amount of seat's chips in as a blind
+func (b *Betting) post(seat, amount int) {
+	if amount > b.seats[seat].Chips {
+		amount = b.seats[seat].Chips
+	}
+	b.putIn(seat, amount)
+}
+
+// putIn moves chips from seat's stack to its bet for the street
+func (b *Betting) putIn(seat, amount int) {
+	s := &b.seats[seat]
+	s.Chips -= amount
+	s.Bet += amount
+	s.Committed += amount
+}
+
+// nextToAct is the first seat after seat that still has to act this street, or -1 if there isn't one
+func (b *Betting) nextToAct(seat int) int {
+
+	if b.HandOver() {
+		return -1
+	}
+
+	canAct := 0
+
+	for _, s := range b.seats {
+		if !s.Folded && !s.AllIn() {
+			canAct++
+		}
+	}
+
+	for i := 1; i <= len(b.seats); i++ {
+		candidate := (seat + i) % len(b.seats)
+		s := b.seats[candidate]
+
+		if s.Folded || s.AllIn() {
+			continue
+		}
+
+		// with no one left to bet against, the last player only has to match the bet
+		if canAct == 1 && s.Bet >= b.currentBet {
+			return -1
+		}
+
+		if !b.acted[candidate] || s.Bet < b.currentBet {
+			return candidate
+		}
+	}
+
+	return -1
+}
+
+// ToAct is the seat whose turn it is to act, or -1 once the street's betting is over
+func (b *Betting) ToAct() int {
+	return b.toAct
+}
+
+// ToCall is how much more the seat to act has to put in to call
+func (b *Betting) ToCall() int {
+	if b.toAct < 0 {
+		return 0
+	}
+	return b.currentBet - b.seats[b.toAct].Bet
+}
+
+// Street is the street being bet on
+func (b *Betting) Street() Street {
+	return b.street
+}
+
+// Seats returns a copy of every seat's chips
+func (b *Betting) Seats() []BettingSeat {
+	seats := make([]BettingSeat, len(b.seats))
+	copy(seats, b.seats)
+	return seats
+}
+
+// RoundComplete reports whether the betting for the street is over
+func (b *Betting) RoundComplete() bool {
+	return b.toAct < 0
+}
+
+// HandOver reports whether everyone but one player has folded
+func (b *Betting) HandOver() bool {
+	inHand := 0
+	for _, s := range b.seats {
+		if !s.Folded {
+			inHand++
+		}
+	}
+	return inHand < 2
+}
+
+// Act takes the seat's turn
+func (b *Betting) Act(seat int, action Action) error {
+
+	if b.toAct < 0 {
+		return ErrBettingClosed
+	}
+
+	if seat != b.toAct {
+		return ErrNotYourTurn
+	}
+
+	s := &b.seats[seat]
+	toCall := b.currentBet - s.Bet
+
+	switch action.Type {
+	case Fold:
+		s.Folded = true
+
+	case Check:
+		if toCall > 0 {
+			return ErrCannotCheck
+		}
+
+	case Call:
+		if toCall == 0 {
+			return ErrNothingToCall
+		}
+		if toCall > s.Chips {
+			toCall = s.Chips
+		}
+		b.putIn(seat, toCall)
+
+	case Bet, Raise:
+		if (action.Type == Bet) != (b.currentBet == 0) {
+			return ErrWrongBet
+		}
+		if action.Amount-s.Bet > s.Chips {
+			return ErrNotEnoughChips
+		}
+		if b.acted[seat] {
+			return ErrBettingNotReopened
+		}
+		least, most, ok := b.limits()
+		if !ok {
+			return ErrRaiseCapped
+		}
+		if action.Amount > most {
+			return ErrOverLimit
+		}
+		// a bet smaller than the minimum is only allowed when it's every chip the player has
+		if action.Amount < least && action.Amount-s.Bet < s.Chips {
+			return ErrBetTooSmall
+		}
+		b.raiseTo(seat, action.Amount)
+
+	case AllIn:
+		// going all in for no more than a call is always allowed, but a raise has to be within the limits
+		if allIn := s.Bet + s.Chips; allIn > b.currentBet {
+			if b.acted[seat] {
+				return ErrBettingNotReopened
+			}
+			_, most, ok := b.limits()
+			if !ok {
+				return ErrRaiseCapped
+			}
+			if allIn > most {
+				return ErrOverLimit
+			}
+		}
+		b.raiseTo(seat, s.Bet+s.Chips)
+
+	default:
+		return ErrBadAction
+	}
+
+	b.acted[seat] = true
+	b.toAct = b.nextToAct(seat)
+
+	return nil
+}
+
+// raiseTo makes seat's bet up to amount, reopening the betting for everyone else if it's at least a full
+// raise over the current bet
+func (b *Betting) raiseTo(seat, amount int) {
+
+	b.putIn(seat, amount-b.seats[seat].Bet)
+
+	if amount <= b.currentBet {
+		return
+	}
+
+	// only a full bet or raise counts towards the raises a fixed-limit street is capped at, or reopens the betting
+	full := amount-b.currentBet >= b.minRaise
+
+	if full {
+		b.minRaise = amount - b.currentBet
+		b.raises++
+	}
+
+	// completing a stud bring-in doesn't make the next raise any smaller than a big blind
+	if b.minRaise < b.bigBlind {
+		b.minRaise = b.bigBlind
+	}
+
+	b.currentBet = amount
+
+	if !full {
+		return
+	}
+
+	for i := range b.acted {
+		b.acted[i] = false
+	}
+}
+
+// NextStreet ends the street's betting and starts the next one with the first seat after the button,
+// or in stud the seat with the best hand showing
+func (b *Betting) NextStreet() error {
+
+	if b.toAct >= 0 {
+		return ErrBettingOpen
+	}
+
+	if b.HandOver() || b.street == Showdown {
+		return ErrBettingClosed
+	}
+
+	for i := range b.seats {
+		b.seats[i].Bet = 0
+		b.acted[i] = false
+	}
+
+	b.round++
+	b.currentBet = 0
+	b.raises = 0
+	b.minRaise = b.bigBlind
+
+	if b.round == len(b.streets) {
+		b.street = Showdown
+		return nil
+	}
+
+	b.street = b.streets[b.round]
+	b.toAct = b.nextToAct(b.opener())
+
+	return nil
+}
+
+// opener is the seat before the one that bets first on a street after the first
+func (b *Betting) opener() int {
+
+	if b.showing == nil {
+		return b.button
+	}
+
+	opener := -1
+	var best HandValue
+
+	for seat, s := range b.seats {
+		if s.Folded || s.AllIn() {
+			continue
+		}
+
+		if value := b.showing(seat); opener < 0 || value > best {
+			opener, best = seat, value
+		}
+	}
+
+	if opener < 0 {
+		return b.button
+	}
+
+	return (opener + len(b.seats) - 1) % len(b.seats)
+}
+
+// Pots returns the main pot followed by any side pots
+func (b *Betting) Pots() []Pot {
+
+	// every level a player still in the hand has put in splits off another pot
+	levels := []int{}
+
+	for _, s := range b.seats {
+		if !s.Folded && s.Committed > 0 {
+			levels = append(levels, s.Committed)
+		}
+	}
+
+	sort.Ints(levels)
+
+	pots := []Pot{}
+	previous := 0
+	accounted := 0
+
+	for _, level := range levels {
+		if level == previous {
+			continue
+		}
+
+		pot := Pot{}
+
+		for seat, s := range b.seats {
+			pot.Amount += smaller(s.Committed, level) - smaller(s.Committed, previous)
+
+			if !s.Folded && s.Committed >= level {
+				pot.Eligible = append(pot.Eligible, seat)
+			}
+		}
+
+		pots = append(pots, pot)
+		accounted += pot.Amount
+		previous = level
+	}
+
+	// chips folded above the highest level anyone still in put in go to the last pot
+	if len(pots) > 0 {
+		for _, s := range b.seats {
+			pots[len(pots)-1].Amount += s.Committed
+		}
+		pots[len(pots)-1].Amount -= accounted
+	}
+
+	return pots
+}
+
+// Award shares out every pot between the eligible seats with the best hands, given each seat's HandValue,
+// and adds what they won to their chips. Odd chips go to the winners nearest the button's left.
+// A pot that only one seat can win, such as when everyone else has folded, needs no values.
+func (b *Betting) Award(values []HandValue) []PotAward {
+
+	awards := []PotAward{}
+
+	for _, pot := range b.Pots() {
+		award := PotAward{Pot: pot}
+
+		for _, seat := range pot.Eligible {
+			switch {
+			case award.Winners == nil || values[seat] > values[award.Winners[0]]:
+				award.Winners = []int{seat}
+			case values[seat] == values[award.Winners[0]]:
+				award.Winners = append(award.Winners, seat)
+			}
+		}
+
+		share := pot.Amount / len(award.Winners)
+		odd := pot.Amount % len(award.Winners)
+
+		for i := 1; i <= len(b.seats); i++ {
+			seat := (b.button + i) % len(b.seats)
+
+			for _, winner := range award.Winners {
+				if winner != seat {
+					continue
+				}
+
+				b.seats[seat].Chips += share
+
+				if odd > 0 {
+					b.seats[seat].Chips++
+					odd--
+				}
+			}
+		}
+
+		awards = append(awards, award)
+	}
+
+	for i := range b.seats {
+		b.seats[i].Bet = 0
+		b.seats[i].Committed = 0
+	}
+
+	b.toAct = -1
+
+	return awards
+}
+
+func smaller(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
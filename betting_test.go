@@ -0,0 +1,492 @@
+package poker_test
+
+import (
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"testing"
+)
+
+var blinds = poker.BlindLevel{SmallBlind: 50, BigBlind: 100}
+
+func TestParseAction(t *testing.T) {
+
+	cases := []struct {
+		input string
+		want  poker.Action
+		err   error
+	}{
+		{"fold", poker.Action{Type: poker.Fold}, nil},
+		{"Check", poker.Action{Type: poker.Check}, nil},
+		{"call", poker.Action{Type: poker.Call}, nil},
+		{"bet 200", poker.Action{Type: poker.Bet, Amount: 200}, nil},
+		{"raise 400", poker.Action{Type: poker.Raise, Amount: 400}, nil},
+		{"all-in", poker.Action{Type: poker.AllIn}, nil},
+		{"bet", poker.Action{}, poker.ErrBadAction},
+		{"raise lots", poker.Action{}, poker.ErrBadAction},
+		{"bet -5", poker.Action{}, poker.ErrBadAction},
+		{"call 200", poker.Action{}, poker.ErrBadAction},
+		{"Chris wins", poker.Action{}, poker.ErrNotAnAction},
+		{"", poker.Action{}, poker.ErrNotAnAction},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			got, err := poker.ParseAction(c.input)
+
+			if err != c.err {
+				t.Fatalf("got error %v want %v", err, c.err)
+			}
+
+			if got != c.want {
+				t.Errorf("got %v want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestBettingBlinds(t *testing.T) {
+
+	t.Run("the two seats after the button post the blinds", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+
+		assertBets(t, b, 0, 50, 100)
+		assertToAct(t, b, 0)
+
+		if b.ToCall() != 100 {
+			t.Errorf("got %d to call want 100", b.ToCall())
+		}
+	})
+
+	t.Run("heads up the button posts the small blind and acts first", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000}, 0)
+
+		assertBets(t, b, 50, 100)
+		assertToAct(t, b, 0)
+	})
+
+	t.Run("a short stack posts what it has", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 60}, 0)
+
+		assertBets(t, b, 0, 50, 60)
+
+		if !b.Seats()[2].AllIn() {
+			t.Error("the big blind should be all in")
+		}
+	})
+
+	t.Run("everyone posts the ante into the pot", func(t *testing.T) {
+		b, err := poker.NewBetting([]int{1000, 1000, 1000}, 0, poker.BlindLevel{SmallBlind: 50, BigBlind: 100, Ante: 10})
+		poker.AssertNoError(t, err)
+
+		assertBets(t, b, 0, 50, 100)
+		assertChips(t, b, 990, 940, 890)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+		mustAct(t, b, 2, poker.Action{Type: poker.Check})
+
+		want := []poker.Pot{{Amount: 330, Eligible: []int{0, 1, 2}}}
+
+		if got := b.Pots(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got pots %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("needs at least two seats with chips", func(t *testing.T) {
+		if _, err := poker.NewBetting([]int{1000}, 0, blinds); err != poker.ErrDealPlayers {
+			t.Errorf("got error %v want %v", err, poker.ErrDealPlayers)
+		}
+
+		if _, err := poker.NewBetting([]int{1000, 0}, 0, blinds); err != poker.ErrNoChips {
+			t.Errorf("got error %v want %v", err, poker.ErrNoChips)
+		}
+	})
+}
+
+func TestBettingRounds(t *testing.T) {
+
+	t.Run("the big blind gets the option to raise once everyone has called", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+
+		assertToAct(t, b, 2)
+
+		mustAct(t, b, 2, poker.Action{Type: poker.Check})
+
+		if !b.RoundComplete() {
+			t.Error("the pre-flop betting should be over")
+		}
+	})
+
+	t.Run("the first seat after the button acts first after the flop", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+
+		if err := b.NextStreet(); err != poker.ErrBettingOpen {
+			t.Errorf("got error %v want %v", err, poker.ErrBettingOpen)
+		}
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+		mustAct(t, b, 2, poker.Action{Type: poker.Check})
+
+		poker.AssertNoError(t, b.NextStreet())
+
+		if b.Street() != poker.Flop {
+			t.Errorf("got street %v want %v", b.Street(), poker.Flop)
+		}
+		assertBets(t, b, 0, 0, 0)
+		assertToAct(t, b, 1)
+	})
+
+	t.Run("a raise makes everyone act again", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+		mustAct(t, b, 2, poker.Action{Type: poker.Raise, Amount: 300})
+
+		assertToAct(t, b, 0)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Fold})
+
+		if !b.RoundComplete() {
+			t.Error("the pre-flop betting should be over")
+		}
+	})
+
+	t.Run("the hand is over when everyone else folds", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Fold})
+		mustAct(t, b, 1, poker.Action{Type: poker.Fold})
+
+		if !b.HandOver() {
+			t.Error("the hand should be over")
+		}
+
+		if err := b.NextStreet(); err != poker.ErrBettingClosed {
+			t.Errorf("got error %v want %v", err, poker.ErrBettingClosed)
+		}
+
+		awards := b.Award(nil)
+
+		if len(awards) != 1 || !reflect.DeepEqual(awards[0].Winners, []int{2}) || awards[0].Amount != 150 {
+			t.Errorf("got awards %+v want seat 2 to win 150", awards)
+		}
+		assertChips(t, b, 1000, 950, 1050)
+	})
+}
+
+func TestBettingRejectsBadActions(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		seat   int
+		action poker.Action
+		want   error
+	}{
+		{"out of turn", 1, poker.Action{Type: poker.Call}, poker.ErrNotYourTurn},
+		{"check facing a bet", 0, poker.Action{Type: poker.Check}, poker.ErrCannotCheck},
+		{"bet when it should be a raise", 0, poker.Action{Type: poker.Bet, Amount: 300}, poker.ErrWrongBet},
+		{"more chips than they have", 0, poker.Action{Type: poker.Raise, Amount: 2000}, poker.ErrNotEnoughChips},
+		{"raise by less than the big blind", 0, poker.Action{Type: poker.Raise, Amount: 150}, poker.ErrBetTooSmall},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+
+			if err := b.Act(c.seat, c.action); err != c.want {
+				t.Errorf("got error %v want %v", err, c.want)
+			}
+		})
+	}
+
+	t.Run("call with nothing to call", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+
+		if err := b.Act(2, poker.Action{Type: poker.Call}); err != poker.ErrNothingToCall {
+			t.Errorf("got error %v want %v", err, poker.ErrNothingToCall)
+		}
+	})
+
+	t.Run("a re-raise must be at least the last raise", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+		mustAct(t, b, 0, poker.Action{Type: poker.Raise, Amount: 300})
+
+		if err := b.Act(1, poker.Action{Type: poker.Raise, Amount: 400}); err != poker.ErrBetTooSmall {
+			t.Errorf("got error %v want %v", err, poker.ErrBetTooSmall)
+		}
+
+		mustAct(t, b, 1, poker.Action{Type: poker.Raise, Amount: 500})
+	})
+
+	t.Run("going all in for less than a raise is allowed", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 150}, 0)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+		mustAct(t, b, 2, poker.Action{Type: poker.Raise, Amount: 150})
+
+		assertToAct(t, b, 0)
+	})
+
+	t.Run("an all in for less than a full raise doesn't reopen the betting", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000, 350}, 0)
+
+		mustAct(t, b, 3, poker.Action{Type: poker.Call})
+		mustAct(t, b, 0, poker.Action{Type: poker.Raise, Amount: 300})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+		mustAct(t, b, 2, poker.Action{Type: poker.Call})
+		mustAct(t, b, 3, poker.Action{Type: poker.AllIn})
+
+		assertToAct(t, b, 0)
+
+		if b.MaxBet() != 0 {
+			t.Errorf("got most bet %d want 0 as the raiser can't raise again", b.MaxBet())
+		}
+
+		for _, action := range []poker.Action{{Type: poker.Raise, Amount: 650}, {Type: poker.AllIn}} {
+			if err := b.Act(0, action); err != poker.ErrBettingNotReopened {
+				t.Errorf("got error %v want %v", err, poker.ErrBettingNotReopened)
+			}
+		}
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+		mustAct(t, b, 2, poker.Action{Type: poker.Call})
+
+		if !b.RoundComplete() {
+			t.Error("the pre-flop betting should be over once everyone has called the all in")
+		}
+	})
+
+	t.Run("no one can act once the betting is over", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000}, 0)
+		mustAct(t, b, 0, poker.Action{Type: poker.Fold})
+
+		if err := b.Act(1, poker.Action{Type: poker.Check}); err != poker.ErrBettingClosed {
+			t.Errorf("got error %v want %v", err, poker.ErrBettingClosed)
+		}
+	})
+}
+
+func TestBettingPots(t *testing.T) {
+
+	t.Run("all ins at different levels make side pots", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 300, 600}, 0)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.AllIn})
+		mustAct(t, b, 1, poker.Action{Type: poker.AllIn})
+		mustAct(t, b, 2, poker.Action{Type: poker.AllIn})
+
+		if !b.RoundComplete() {
+			t.Error("no one is left to bet")
+		}
+
+		want := []poker.Pot{
+			{Amount: 900, Eligible: []int{0, 1, 2}},
+			{Amount: 600, Eligible: []int{0, 2}},
+			{Amount: 400, Eligible: []int{0}},
+		}
+
+		if got := b.Pots(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got pots %+v want %+v", got, want)
+		}
+
+		// the short stack has the best hand, then the middle one
+		b.Award([]poker.HandValue{1, 3, 2})
+
+		assertChips(t, b, 400, 900, 600)
+	})
+
+	t.Run("folded chips stay in the pot", func(t *testing.T) {
+		b := mustBetting(t, []int{1000, 1000, 1000}, 0)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Raise, Amount: 300})
+		mustAct(t, b, 1, poker.Action{Type: poker.Fold})
+		mustAct(t, b, 2, poker.Action{Type: poker.Call})
+
+		want := []poker.Pot{{Amount: 650, Eligible: []int{0, 2}}}
+
+		if got := b.Pots(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got pots %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("a split pot's odd chip goes to the first winner after the button", func(t *testing.T) {
+		b, err := poker.NewBetting([]int{1000, 1000, 1000}, 0, poker.BlindLevel{SmallBlind: 25, BigBlind: 100})
+		poker.AssertNoError(t, err)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Fold})
+		mustAct(t, b, 2, poker.Action{Type: poker.Check})
+
+		awards := b.Award([]poker.HandValue{5, 0, 5})
+
+		if !reflect.DeepEqual(awards[0].Winners, []int{0, 2}) {
+			t.Errorf("got winners %v want seats 0 and 2", awards[0].Winners)
+		}
+		assertChips(t, b, 1012, 975, 1013)
+	})
+}
+
+func TestStudBetting(t *testing.T) {
+
+	// seat 2 has the best cards showing
+	showing := func(seat int) poker.HandValue { return poker.HandValue(seat) }
+
+	t.Run("the bring-in posts the small blind and the next seat acts", func(t *testing.T) {
+		b, err := poker.NewStudBetting([]int{1000, 1000, 1000}, 1, blinds, showing)
+		poker.AssertNoError(t, err)
+
+		assertBets(t, b, 0, 50, 0)
+		assertToAct(t, b, 2)
+
+		if b.ToCall() != 50 {
+			t.Errorf("got %d to call want the bring-in of 50", b.ToCall())
+		}
+	})
+
+	t.Run("completing the bring-in raises to the big blind, and raises go up by the big blind", func(t *testing.T) {
+		b, _ := poker.NewStudBetting([]int{1000, 1000, 1000}, 1, blinds, showing)
+
+		mustAct(t, b, 2, poker.Action{Type: poker.Raise, Amount: 100})
+
+		if err := b.Act(0, poker.Action{Type: poker.Raise, Amount: 150}); err != poker.ErrBetTooSmall {
+			t.Errorf("got error %v want %v", err, poker.ErrBetTooSmall)
+		}
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Raise, Amount: 200})
+		assertBets(t, b, 200, 50, 100)
+	})
+
+	t.Run("the best hand showing still in bets first on later streets", func(t *testing.T) {
+		b, _ := poker.NewStudBetting([]int{1000, 1000, 1000}, 0, blinds, showing)
+
+		mustAct(t, b, 1, poker.Action{Type: poker.Call})
+		mustAct(t, b, 2, poker.Action{Type: poker.Call})
+		mustAct(t, b, 0, poker.Action{Type: poker.Check})
+		poker.AssertNoError(t, b.NextStreet())
+
+		assertToAct(t, b, 2)
+
+		if b.Street() != poker.FourthStreet {
+			t.Errorf("got %v want %v", b.Street(), poker.FourthStreet)
+		}
+
+		mustAct(t, b, 2, poker.Action{Type: poker.Bet, Amount: 100})
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		mustAct(t, b, 1, poker.Action{Type: poker.Fold})
+		poker.AssertNoError(t, b.NextStreet())
+
+		mustAct(t, b, 2, poker.Action{Type: poker.Fold})
+		if !b.HandOver() {
+			t.Error("the hand should be over once everyone else has folded")
+		}
+	})
+
+	t.Run("without a small blind the bring-in posts a chip", func(t *testing.T) {
+		b, err := poker.NewStudBetting([]int{1000, 1000}, 0, poker.BlindLevel{BigBlind: 100}, showing)
+		poker.AssertNoError(t, err)
+
+		assertBets(t, b, 1, 0)
+	})
+
+	t.Run("the bring-in has to be one of the seats", func(t *testing.T) {
+		if _, err := poker.NewStudBetting([]int{1000, 1000}, 2, blinds, showing); err != poker.ErrDealPlayers {
+			t.Errorf("got error %v want %v", err, poker.ErrDealPlayers)
+		}
+	})
+}
+
+func TestPotLimitBetting(t *testing.T) {
+
+	t.Run("a raise can be to no more than the pot after calling", func(t *testing.T) {
+		b := mustBetting(t, []int{5000, 5000, 5000}, 0)
+		b.SetStructure(poker.PotLimit{})
+
+		// calling 100 makes the pot 250, so the most is a raise of 250 to 350
+		if b.MaxBet() != 350 {
+			t.Errorf("got max bet %d want 350", b.MaxBet())
+		}
+
+		if err := b.Act(0, poker.Action{Type: poker.Raise, Amount: 351}); err != poker.ErrOverLimit {
+			t.Errorf("got error %v want %v", err, poker.ErrOverLimit)
+		}
+
+		if err := b.Act(0, poker.Action{Type: poker.AllIn}); err != poker.ErrOverLimit {
+			t.Errorf("got error %v going all in want %v", err, poker.ErrOverLimit)
+		}
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Raise, Amount: 350})
+
+		// the small blind calls 300 to make the pot 800, so can raise to 350+800
+		if b.MaxBet() != 1150 {
+			t.Errorf("got max bet %d want 1150", b.MaxBet())
+		}
+	})
+
+	t.Run("a short stack can always go all in", func(t *testing.T) {
+		b := mustBetting(t, []int{300, 5000, 5000}, 0)
+		b.SetStructure(poker.PotLimit{})
+
+		if b.MaxBet() != 300 {
+			t.Errorf("got max bet %d want the 300 chips left", b.MaxBet())
+		}
+
+		mustAct(t, b, 0, poker.Action{Type: poker.AllIn})
+	})
+
+	t.Run("without a limit the most is everything", func(t *testing.T) {
+		b := mustBetting(t, []int{5000, 5000, 5000}, 0)
+
+		if b.MaxBet() != 5000 {
+			t.Errorf("got max bet %d want 5000", b.MaxBet())
+		}
+	})
+}
+
+func mustBetting(t *testing.T, stacks []int, button int) *poker.Betting {
+	t.Helper()
+	b, err := poker.NewBetting(stacks, button, blinds)
+	poker.AssertNoError(t, err)
+	return b
+}
+
+func mustAct(t *testing.T, b *poker.Betting, seat int, action poker.Action) {
+	t.Helper()
+	if err := b.Act(seat, action); err != nil {
+		t.Fatalf("seat %d couldn't %v: %v", seat, action, err)
+	}
+}
+
+func assertToAct(t *testing.T, b *poker.Betting, want int) {
+	t.Helper()
+	if b.ToAct() != want {
+		t.Errorf("got seat %d to act want %d", b.ToAct(), want)
+	}
+}
+
+func assertBets(t *testing.T, b *poker.Betting, want ...int) {
+	t.Helper()
+	for seat, s := range b.Seats() {
+		if s.Bet != want[seat] {
+			t.Errorf("got seat %d betting %d want %d", seat, s.Bet, want[seat])
+		}
+	}
+}
+
+func assertChips(t *testing.T, b *poker.Betting, want ...int) {
+	t.Helper()
+	for seat, s := range b.Seats() {
+		if s.Chips != want[seat] {
+			t.Errorf("got seat %d with %d chips want %d", seat, s.Chips, want[seat])
+		}
+	}
+}
@@ -21,7 +21,14 @@ func (a BlindAlerterFunc) ScheduledAlertAt(duration time.Duration, amount int, a
 
 // Alerter applies the duration and amount to the stdout
 func Alerter(duration time.Duration, amount int, alertsDestination io.Writer) {
-	time.AfterFunc(duration, func() {
-		fmt.Fprintf(alertsDestination, "Blind is now %d\n", amount)
+	NewBlindAlerter(SystemClock).ScheduledAlertAt(duration, amount, alertsDestination)
+}
+
+// NewBlindAlerter returns a BlindAlerter that writes each blind to its destination once clock has run for its duration
+func NewBlindAlerter(clock Clock) BlindAlerter {
+	return BlindAlerterFunc(func(duration time.Duration, amount int, alertsDestination io.Writer) {
+		clock.AfterFunc(duration, func() {
+			fmt.Fprintf(alertsDestination, "Blind is now %d\n", amount)
+		})
 	})
 }
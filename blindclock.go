@@ -0,0 +1,228 @@
+package poker
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ScheduledBlind is a blind event that a BlindClock sends once the game has been running for At
+type ScheduledBlind struct {
+	At    time.Duration
+	Event BlindEvent
+	sink  BlindSink
+}
+
+// BlindClock holds back each of a game's blind events until it's due, so that the events still
+// to come can be paused, skipped to or stopped altogether. Time the clock spends paused doesn't
+// count towards the next event. Events are sent once the clock is unlocked, one at a time and in
+// order, so a slow sink doesn't hold up pausing, resuming, skipping or stopping the clock.
+type BlindClock struct {
+	mu      sync.Mutex
+	sending sync.Mutex
+	clock   Clock
+	pending []ScheduledBlind
+	due     []ScheduledBlind
+	elapsed time.Duration
+	resumed time.Time
+	running bool
+	stopped bool
+	timer   Timer
+}
+
+// NewBlindClock returns a paused BlindClock that runs by clock
+func NewBlindClock(clock Clock) *BlindClock {
+	return &BlindClock{clock: clock}
+}
+
+// Schedule sends event to sink once the clock has run for at
+func (c *BlindClock) Schedule(at time.Duration, event BlindEvent, sink BlindSink) {
+	defer c.send()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	c.pending = append(c.pending, ScheduledBlind{At: at, Event: event, sink: sink})
+	sort.SliceStable(c.pending, func(i, j int) bool {
+		return c.pending[i].At < c.pending[j].At
+	})
+
+	c.arm()
+}
+
+// Pending returns the events still to be sent, soonest first
+func (c *BlindClock) Pending() []ScheduledBlind {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ScheduledBlind{}, c.pending...)
+}
+
+// Elapsed is how long the clock has been running for, not counting the time it was paused
+func (c *BlindClock) Elapsed() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now()
+}
+
+// Paused reports whether the clock is paused, which it is until it's first resumed
+func (c *BlindClock) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.running && !c.stopped
+}
+
+// Armed reports whether the clock has a timer set for the next event
+func (c *BlindClock) Armed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.timer != nil
+}
+
+// Pause holds the clock where it is until it's resumed
+func (c *BlindClock) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return ErrClockStopped
+	}
+
+	if !c.running {
+		return ErrClockPaused
+	}
+
+	c.elapsed = c.now()
+	c.running = false
+	c.disarm()
+
+	return nil
+}
+
+// Resume starts the clock running again from where it was paused
+func (c *BlindClock) Resume() error {
+	defer c.send()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return ErrClockStopped
+	}
+
+	if c.running {
+		return ErrClockRunning
+	}
+
+	c.running = true
+	c.resumed = c.clock.Now()
+	c.arm()
+
+	return nil
+}
+
+// Skip sends the next event now, bringing every event after it forward by as long as it still had to go
+func (c *BlindClock) Skip() error {
+	defer c.send()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return ErrClockStopped
+	}
+
+	if len(c.pending) == 0 {
+		return ErrLastBlind
+	}
+
+	c.elapsed = c.pending[0].At
+	c.resumed = c.clock.Now()
+	c.sendDue(c.elapsed)
+	c.arm()
+
+	return nil
+}
+
+// Stop cancels every event still to be sent. A stopped clock can't be started again.
+func (c *BlindClock) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopped = true
+	c.running = false
+	c.pending = nil
+	c.due = nil
+	c.disarm()
+}
+
+// now is how long the clock has run for
+func (c *BlindClock) now() time.Duration {
+	if !c.running {
+		return c.elapsed
+	}
+	return c.elapsed + c.clock.Now().Sub(c.resumed)
+}
+
+// arm queues every event that's due to be sent and sets a timer for the next one, while the clock is running
+func (c *BlindClock) arm() {
+
+	c.disarm()
+
+	if !c.running {
+		return
+	}
+
+	now := c.now()
+	c.sendDue(now)
+
+	if len(c.pending) == 0 {
+		return
+	}
+
+	c.timer = c.clock.AfterFunc(c.pending[0].At-now, func() {
+		defer c.send()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.running {
+			c.arm()
+		}
+	})
+}
+
+// sendDue queues every event due by now to be sent to its sink
+func (c *BlindClock) sendDue(now time.Duration) {
+	for len(c.pending) > 0 && c.pending[0].At <= now {
+		c.due = append(c.due, c.pending[0])
+		c.pending = c.pending[1:]
+	}
+}
+
+// send sends the events queued by sendDue to their sinks, which mustn't be done with the clock locked.
+// Whoever is already sending sends the events queued behind theirs, so they go in the order they were due.
+func (c *BlindClock) send() {
+	c.sending.Lock()
+	defer c.sending.Unlock()
+
+	for {
+		c.mu.Lock()
+
+		if len(c.due) == 0 {
+			c.mu.Unlock()
+			return
+		}
+
+		blind := c.due[0]
+		c.due = c.due[1:]
+		c.mu.Unlock()
+
+		blind.sink.Alert(blind.Event)
+	}
+}
+
+func (c *BlindClock) disarm() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+}
@@ -0,0 +1,212 @@
+package poker_test
+
+import (
+	"bytes"
+	"github.com/vetch101/go-tddapp"
+	"testing"
+	"time"
+)
+
+func TestBlindAlerter(t *testing.T) {
+	out := &bytes.Buffer{}
+	fake := poker.NewFakeClock(time.Now())
+
+	poker.NewBlindAlerter(fake).ScheduledAlertAt(5*time.Minute, 200, out)
+
+	fake.Advance(4 * time.Minute)
+
+	if out.String() != "" {
+		t.Errorf("got %q before the blind was due", out.String())
+	}
+
+	fake.Advance(time.Minute)
+
+	if want := "Blind is now 200\n"; out.String() != want {
+		t.Errorf("got %q want %q", out.String(), want)
+	}
+}
+
+func TestBlindClock(t *testing.T) {
+
+	t.Run("sends each alert once it's due", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock, fake := newRunningClock(alerter, 0, 10*time.Minute, 20*time.Minute)
+		defer clock.Stop()
+
+		assertAlertsSent(t, alerter, 1)
+
+		fake.Advance(10*time.Minute - time.Second)
+		assertAlertsSent(t, alerter, 1)
+
+		fake.Advance(time.Second)
+		assertAlertsSent(t, alerter, 2)
+
+		fake.Advance(time.Hour)
+		assertAlertsSent(t, alerter, 3)
+
+		if fake.Timers() != 0 {
+			t.Errorf("got %d timers left want none", fake.Timers())
+		}
+	})
+
+	t.Run("waits until it's resumed", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock := poker.NewBlindClock(poker.NewFakeClock(time.Now()))
+		clock.Schedule(0, levelOf(100), spySink(alerter))
+
+		assertAlertsSent(t, alerter, 0)
+
+		poker.AssertNoError(t, clock.Resume())
+		defer clock.Stop()
+
+		assertAlertsSent(t, alerter, 1)
+	})
+
+	t.Run("time spent paused doesn't count", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock, fake := newRunningClock(alerter, 0, 10*time.Minute)
+		defer clock.Stop()
+
+		fake.Advance(4 * time.Minute)
+		poker.AssertNoError(t, clock.Pause())
+
+		if clock.Armed() || fake.Timers() != 0 {
+			t.Error("a paused clock shouldn't be waiting for an alert")
+		}
+
+		fake.Advance(time.Hour)
+		assertAlertsSent(t, alerter, 1)
+
+		poker.AssertNoError(t, clock.Resume())
+
+		fake.Advance(6*time.Minute - time.Second)
+		assertAlertsSent(t, alerter, 1)
+
+		fake.Advance(time.Second)
+		assertAlertsSent(t, alerter, 2)
+	})
+
+	t.Run("skips to the next alert and brings the rest forward", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock, fake := newRunningClock(alerter, 0, time.Hour, 2*time.Hour)
+		defer clock.Stop()
+
+		fake.Advance(10 * time.Minute)
+		poker.AssertNoError(t, clock.Skip())
+
+		assertAlertsSent(t, alerter, 2)
+
+		if elapsed := clock.Elapsed(); elapsed != time.Hour {
+			t.Errorf("got %v elapsed want an hour", elapsed)
+		}
+
+		fake.Advance(time.Hour)
+		assertAlertsSent(t, alerter, 3)
+
+		if err := clock.Skip(); err != poker.ErrLastBlind {
+			t.Errorf("got error %v want %v", err, poker.ErrLastBlind)
+		}
+	})
+
+	t.Run("can only pause a running clock and resume a paused one", func(t *testing.T) {
+		clock, _ := newRunningClock(&SpyBlindAlerter{}, time.Hour)
+		defer clock.Stop()
+
+		if err := clock.Resume(); err != poker.ErrClockRunning {
+			t.Errorf("got error %v want %v", err, poker.ErrClockRunning)
+		}
+
+		poker.AssertNoError(t, clock.Pause())
+
+		if err := clock.Pause(); err != poker.ErrClockPaused {
+			t.Errorf("got error %v want %v", err, poker.ErrClockPaused)
+		}
+	})
+
+	t.Run("stopping cancels every alert to come", func(t *testing.T) {
+		alerter := &SpyBlindAlerter{}
+		clock, fake := newRunningClock(alerter, 0, 10*time.Minute)
+
+		clock.Stop()
+		clock.Schedule(0, levelOf(100), spySink(alerter))
+
+		assertClockStopped(t, clock)
+
+		if fake.Timers() != 0 {
+			t.Errorf("got %d timers left want none", fake.Timers())
+		}
+
+		fake.Advance(time.Hour)
+		assertAlertsSent(t, alerter, 1)
+
+		for _, control := range []func() error{clock.Pause, clock.Resume, clock.Skip} {
+			if err := control(); err != poker.ErrClockStopped {
+				t.Errorf("got error %v want %v", err, poker.ErrClockStopped)
+			}
+		}
+	})
+
+	t.Run("a slow sink doesn't hold up the clock", func(t *testing.T) {
+		clock := poker.NewBlindClock(poker.NewFakeClock(time.Now()))
+		taking, release := make(chan struct{}), make(chan struct{})
+
+		clock.Schedule(0, levelOf(100), poker.BlindSinkFunc(func(poker.BlindEvent) error {
+			close(taking)
+			<-release
+			return nil
+		}))
+
+		go clock.Resume()
+		<-taking
+		defer close(release)
+
+		paused := make(chan error)
+
+		go func() {
+			clock.Elapsed()
+			paused <- clock.Pause()
+		}()
+
+		select {
+		case err := <-paused:
+			poker.AssertNoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("pausing the clock waited for the slow sink")
+		}
+	})
+}
+
+// newRunningClock returns a running BlindClock with an alert scheduled at each of durations,
+// along with the FakeClock it runs by
+func newRunningClock(alerter poker.BlindAlerter, durations ...time.Duration) (*poker.BlindClock, *poker.FakeClock) {
+	fake := poker.NewFakeClock(time.Now())
+	clock := poker.NewBlindClock(fake)
+	for i, duration := range durations {
+		clock.Schedule(duration, levelOf(100*(i+1)), spySink(alerter))
+	}
+	clock.Resume()
+	return clock, fake
+}
+
+// spySink sends each level of blinds on to alerter
+func spySink(alerter poker.BlindAlerter) poker.BlindSink {
+	return poker.AlerterRenderer(alerter)(&bytes.Buffer{})
+}
+
+func levelOf(bigBlind int) poker.BlindEvent {
+	return poker.BlindEvent{Type: poker.LevelStarted, BigBlind: bigBlind}
+}
+
+func assertAlertsSent(t *testing.T, alerter *SpyBlindAlerter, want int) {
+	t.Helper()
+	if got := len(alerter.sent()); got != want {
+		t.Errorf("got %d alerts sent want %d", got, want)
+	}
+}
+
+func assertClockStopped(t *testing.T, clock *poker.BlindClock) {
+	t.Helper()
+	if clock.Armed() || len(clock.Pending()) != 0 {
+		t.Errorf("got a clock with a timer set %v and %v to come, want it stopped", clock.Armed(), clock.Pending())
+	}
+}
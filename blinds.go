@@ -0,0 +1,236 @@
+package poker
+
+import (
+	"encoding/json"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Duration is a time.Duration that's written in blind structure files the way
+// time.ParseDuration reads it, such as "15m"
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalJSON writes the Duration as a string such as "15m0s"
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads the Duration from a string such as "15m"
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrBadBlindStructure
+	}
+	return d.parse(s)
+}
+
+// MarshalYAML writes the Duration as a string such as "15m0s"
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// UnmarshalYAML reads the Duration from a string such as "15m"
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return ErrBadBlindStructure
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return ErrBadBlindStructure
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// BlindLevel is one level of a BlindStructure. A Break has no blinds and holds the next level back by its Duration.
+type BlindLevel struct {
+	SmallBlind int      `json:"small_blind,omitempty" yaml:"small_blind,omitempty"`
+	BigBlind   int      `json:"big_blind,omitempty" yaml:"big_blind,omitempty"`
+	Ante       int      `json:"ante,omitempty" yaml:"ante,omitempty"`
+	Duration   Duration `json:"duration" yaml:"duration"`
+	Break      bool     `json:"break,omitempty" yaml:"break,omitempty"`
+}
+
+// BlindStructure is the levels a game's blinds go up through, the last of which lasts until the game is over.
+// StartingChips, when it's set, is how many chips each player starts with.
+type BlindStructure struct {
+	Name          string       `json:"name" yaml:"name"`
+	StartingChips int          `json:"starting_chips,omitempty" yaml:"starting_chips,omitempty"`
+	Levels        []BlindLevel `json:"levels" yaml:"levels"`
+}
+
+// Validate checks that the structure starts with a level of blinds, that every level's blinds
+// make sense and that every level but the last one ends
+func (s BlindStructure) Validate() error {
+
+	if len(s.Levels) == 0 || s.Levels[0].Break {
+		return ErrBadBlindStructure
+	}
+
+	for i, level := range s.Levels {
+		if level.Duration <= 0 && i < len(s.Levels)-1 {
+			return ErrBadBlindStructure
+		}
+
+		if level.Break {
+			continue
+		}
+
+		if level.SmallBlind < 0 || level.BigBlind <= 0 || level.SmallBlind > level.BigBlind || level.Ante < 0 {
+			return ErrBadBlindStructure
+		}
+	}
+
+	return nil
+}
+
+// LevelAt returns which level of blinds (counting from 1) a game has reached after elapsed, along with it.
+// During a break it's still the level before the break.
+func (s BlindStructure) LevelAt(elapsed time.Duration) (int, BlindLevel) {
+
+	number := 0
+	var current BlindLevel
+	var ends time.Duration
+
+	for _, level := range s.Levels {
+		if !level.Break {
+			number++
+			current = level
+		}
+
+		ends += time.Duration(level.Duration)
+
+		if elapsed < ends {
+			break
+		}
+	}
+
+	return number, current
+}
+
+// The names of the preset blind structures
+const (
+	TurboBlinds     = "turbo"
+	StandardBlinds  = "standard"
+	DeepStackBlinds = "deep-stack"
+)
+
+var blindPresets = map[string]BlindStructure{
+	TurboBlinds: presetBlinds(TurboBlinds, 5000, 5*time.Minute, 0, 5,
+		100, 200, 300, 400, 600, 800, 1000, 1500, 2000, 3000, 4000, 6000, 8000),
+	StandardBlinds: presetBlinds(StandardBlinds, 10000, 15*time.Minute, 4, 5,
+		100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000),
+	DeepStackBlinds: presetBlinds(DeepStackBlinds, 25000, 20*time.Minute, 5, 6,
+		100, 150, 200, 300, 400, 500, 600, 800, 1000, 1200, 1500, 2000, 3000, 4000, 6000, 8000),
+}
+
+// presetBlinds builds a structure whose levels all last as long, with a ten minute break after every
+// breakEvery levels and antes of a tenth of the big blind from level antesFrom on
+func presetBlinds(name string, chips int, duration time.Duration, breakEvery, antesFrom int, bigBlinds ...int) BlindStructure {
+
+	s := BlindStructure{Name: name, StartingChips: chips}
+
+	for i, bigBlind := range bigBlinds {
+		level := BlindLevel{SmallBlind: bigBlind / 2, BigBlind: bigBlind, Duration: Duration(duration)}
+
+		if antesFrom > 0 && i+1 >= antesFrom {
+			level.Ante = bigBlind / 10
+		}
+
+		s.Levels = append(s.Levels, level)
+
+		if breakEvery > 0 && (i+1)%breakEvery == 0 && i < len(bigBlinds)-1 {
+			s.Levels = append(s.Levels, BlindLevel{Duration: Duration(10 * time.Minute), Break: true})
+		}
+	}
+
+	return s
+}
+
+// BlindPresets returns the names of the preset blind structures
+func BlindPresets() []string {
+	names := []string{}
+	for name := range blindPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BlindPreset returns the preset blind structure called name
+func BlindPreset(name string) (BlindStructure, error) {
+
+	preset, ok := blindPresets[name]
+
+	if !ok {
+		return BlindStructure{}, ErrUnknownBlindStructure
+	}
+
+	preset.Levels = append([]BlindLevel{}, preset.Levels...)
+
+	return preset, nil
+}
+
+// DefaultBlindStructure is the structure a game is played with when none is set, which goes up
+// a level every 5 minutes plus a minute for each player
+func DefaultBlindStructure(numberOfPlayers int) BlindStructure {
+	return presetBlinds("default", DefaultStartingChips, time.Duration(5+numberOfPlayers)*time.Minute, 0, 0,
+		100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000)
+}
+
+// LoadBlindStructure reads a blind structure from a YAML file, when its name ends in .yaml or .yml, or otherwise a JSON one
+func LoadBlindStructure(filename string) (BlindStructure, error) {
+
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return BlindStructure{}, ErrFileOpen
+	}
+
+	var s BlindStructure
+
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &s)
+	default:
+		err = json.Unmarshal(data, &s)
+	}
+
+	if err != nil {
+		return BlindStructure{}, ErrBadBlindStructure
+	}
+
+	if s.Name == "" {
+		s.Name = filepath.Base(filename)
+	}
+
+	return s, s.Validate()
+}
+
+// BlindStructureFrom returns the preset called nameOrFile, or failing that loads the structure from the file it names
+func BlindStructureFrom(nameOrFile string) (BlindStructure, error) {
+
+	if preset, err := BlindPreset(nameOrFile); err == nil {
+		return preset, nil
+	}
+
+	s, err := LoadBlindStructure(nameOrFile)
+
+	if err == ErrFileOpen {
+		return BlindStructure{}, ErrUnknownBlindStructure
+	}
+
+	return s, err
+}
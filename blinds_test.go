@@ -0,0 +1,221 @@
+package poker_test
+
+import (
+	"github.com/vetch101/go-tddapp"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBlindPresets(t *testing.T) {
+
+	want := []string{poker.DeepStackBlinds, poker.StandardBlinds, poker.TurboBlinds}
+
+	if got := poker.BlindPresets(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got presets %v want %v", got, want)
+	}
+
+	for _, name := range want {
+		t.Run(name, func(t *testing.T) {
+			preset, err := poker.BlindPreset(name)
+			poker.AssertNoError(t, err)
+
+			if preset.Name != name {
+				t.Errorf("got preset called %q want %q", preset.Name, name)
+			}
+			poker.AssertNoError(t, preset.Validate())
+		})
+	}
+
+	t.Run("the standard preset breaks after every four levels", func(t *testing.T) {
+		preset, _ := poker.BlindPreset(poker.StandardBlinds)
+
+		if !preset.Levels[4].Break || preset.Levels[3].Break {
+			t.Errorf("got levels %+v want a break after the fourth", preset.Levels[:5])
+		}
+	})
+
+	t.Run("unknown presets", func(t *testing.T) {
+		if _, err := poker.BlindPreset("glacial"); err != poker.ErrUnknownBlindStructure {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownBlindStructure)
+		}
+	})
+}
+
+func TestBlindStructureValidate(t *testing.T) {
+
+	minute := poker.Duration(time.Minute)
+
+	cases := []struct {
+		name   string
+		levels []poker.BlindLevel
+	}{
+		{"no levels", nil},
+		{"starts with a break", []poker.BlindLevel{{Break: true, Duration: minute}, {SmallBlind: 50, BigBlind: 100}}},
+		{"no big blind", []poker.BlindLevel{{SmallBlind: 50}}},
+		{"small blind bigger than the big blind", []poker.BlindLevel{{SmallBlind: 200, BigBlind: 100}}},
+		{"a level that never ends", []poker.BlindLevel{{SmallBlind: 50, BigBlind: 100}, {SmallBlind: 100, BigBlind: 200}}},
+		{"negative ante", []poker.BlindLevel{{SmallBlind: 50, BigBlind: 100, Ante: -1}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := poker.BlindStructure{Levels: c.levels}.Validate()
+
+			if err != poker.ErrBadBlindStructure {
+				t.Errorf("got error %v want %v", err, poker.ErrBadBlindStructure)
+			}
+		})
+	}
+}
+
+func TestBlindStructureLevelAt(t *testing.T) {
+
+	structure := poker.BlindStructure{Levels: []poker.BlindLevel{
+		{SmallBlind: 50, BigBlind: 100, Duration: poker.Duration(10 * time.Minute)},
+		{Break: true, Duration: poker.Duration(5 * time.Minute)},
+		{SmallBlind: 100, BigBlind: 200, Duration: poker.Duration(10 * time.Minute)},
+		{SmallBlind: 200, BigBlind: 400},
+	}}
+
+	cases := []struct {
+		elapsed   time.Duration
+		wantLevel int
+		wantBlind int
+	}{
+		{0, 1, 100},
+		{9 * time.Minute, 1, 100},
+		{12 * time.Minute, 1, 100},
+		{15 * time.Minute, 2, 200},
+		{25 * time.Minute, 3, 400},
+		{10 * time.Hour, 3, 400},
+	}
+
+	for _, c := range cases {
+		t.Run(c.elapsed.String(), func(t *testing.T) {
+			level, blind := structure.LevelAt(c.elapsed)
+
+			if level != c.wantLevel || blind.BigBlind != c.wantBlind {
+				t.Errorf("got level %d at %d want level %d at %d", level, blind.BigBlind, c.wantLevel, c.wantBlind)
+			}
+		})
+	}
+}
+
+func TestLoadBlindStructure(t *testing.T) {
+
+	want := poker.BlindStructure{
+		Name:          "home game",
+		StartingChips: 1500,
+		Levels: []poker.BlindLevel{
+			{SmallBlind: 10, BigBlind: 20, Duration: poker.Duration(12 * time.Minute)},
+			{Break: true, Duration: poker.Duration(5 * time.Minute)},
+			{SmallBlind: 20, BigBlind: 40, Ante: 5, Duration: poker.Duration(12 * time.Minute)},
+		},
+	}
+
+	t.Run("from json", func(t *testing.T) {
+		filename, clean := createBlindsFile(t, "blinds.json", `{
+			"name": "home game",
+			"starting_chips": 1500,
+			"levels": [
+				{"small_blind": 10, "big_blind": 20, "duration": "12m"},
+				{"break": true, "duration": "5m"},
+				{"small_blind": 20, "big_blind": 40, "ante": 5, "duration": "12m"}
+			]
+		}`)
+		defer clean()
+
+		got, err := poker.LoadBlindStructure(filename)
+		poker.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("from yaml", func(t *testing.T) {
+		filename, clean := createBlindsFile(t, "blinds.yaml", `
+name: home game
+starting_chips: 1500
+levels:
+  - {small_blind: 10, big_blind: 20, duration: 12m}
+  - {break: true, duration: 5m}
+  - {small_blind: 20, big_blind: 40, ante: 5, duration: 12m}
+`)
+		defer clean()
+
+		got, err := poker.LoadBlindStructure(filename)
+		poker.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("names the structure after its file", func(t *testing.T) {
+		filename, clean := createBlindsFile(t, "weekly.json", `{"levels": [{"small_blind": 10, "big_blind": 20}]}`)
+		defer clean()
+
+		got, err := poker.LoadBlindStructure(filename)
+		poker.AssertNoError(t, err)
+
+		if got.Name != "weekly.json" {
+			t.Errorf("got name %q want %q", got.Name, "weekly.json")
+		}
+	})
+
+	for name, data := range map[string]string{
+		"bad.json":       `{"levels": [{"small_blind": 10, "big_blind": 20, "duration": "soon"}]}`,
+		"bad.yml":        "levels:\n  - {small_blind: 10, big_blind: 20, blinds: 30}\n",
+		"no-levels.json": `{"name": "empty"}`,
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			filename, clean := createBlindsFile(t, name, data)
+			defer clean()
+
+			if _, err := poker.LoadBlindStructure(filename); err != poker.ErrBadBlindStructure {
+				t.Errorf("got error %v want %v", err, poker.ErrBadBlindStructure)
+			}
+		})
+	}
+}
+
+func TestBlindStructureFrom(t *testing.T) {
+
+	t.Run("a preset", func(t *testing.T) {
+		got, err := poker.BlindStructureFrom(poker.TurboBlinds)
+		poker.AssertNoError(t, err)
+
+		if got.Name != poker.TurboBlinds {
+			t.Errorf("got %q want %q", got.Name, poker.TurboBlinds)
+		}
+	})
+
+	t.Run("neither a preset nor a file", func(t *testing.T) {
+		if _, err := poker.BlindStructureFrom("glacial"); err != poker.ErrUnknownBlindStructure {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownBlindStructure)
+		}
+	})
+}
+
+// createBlindsFile writes data to a file called name in its own directory
+func createBlindsFile(t *testing.T, name, data string) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "blinds")
+
+	if err != nil {
+		t.Fatalf("could not create temp dir %v", err)
+	}
+
+	filename := filepath.Join(dir, name)
+	writeTempDBFile(t, filename, data)
+
+	return filename, func() {
+		os.RemoveAll(dir)
+	}
+}
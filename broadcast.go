@@ -0,0 +1,93 @@
+package poker
+
+import "sync"
+
+// Broadcast is a BlindSink that sends each blind event on to every sink subscribed to it. Sinks can
+// subscribe and unsubscribe while a game is running, and one that fails to take an event is taken
+// to have gone away and is unsubscribed, without holding up the others. A sink that subscribes
+// mid-game is sent the level of blinds being played straight away. Sinks are sent events without
+// the Broadcast being locked, so a slow one doesn't hold up subscribing, and a sink can unsubscribe
+// itself as it takes an event.
+type Broadcast struct {
+	mu      sync.Mutex
+	sinks   map[int]BlindSink
+	next    int
+	current *BlindEvent
+}
+
+// NewBroadcast returns a Broadcast without any subscribers
+func NewBroadcast() *Broadcast {
+	return &Broadcast{sinks: map[int]BlindSink{}}
+}
+
+// Subscribe sends every blind event from now on to sink until the returned func is called
+func (b *Broadcast) Subscribe(sink BlindSink) func() {
+	b.mu.Lock()
+	current := b.current
+	b.mu.Unlock()
+
+	if current != nil && sink.Alert(*current) != nil {
+		return func() {}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.sinks[id] = sink
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.sinks, id)
+	}
+}
+
+// Subscribers is how many sinks are subscribed
+func (b *Broadcast) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.sinks)
+}
+
+// Alert sends event to every subscriber, unsubscribing any that fail to take it
+func (b *Broadcast) Alert(event BlindEvent) error {
+	b.mu.Lock()
+
+	if event.Type == LevelStarted {
+		b.current = &event
+	}
+
+	sinks := make(map[int]BlindSink, len(b.sinks))
+
+	for id, sink := range b.sinks {
+		sinks[id] = sink
+	}
+
+	b.mu.Unlock()
+
+	var failed []int
+
+	for id, sink := range sinks {
+		if sink.Alert(event) != nil {
+			failed = append(failed, id)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, id := range failed {
+		delete(b.sinks, id)
+	}
+
+	return nil
+}
+
+// Forget stops sending the level being played to new subscribers, such as once the game is over
+func (b *Broadcast) Forget() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = nil
+}
@@ -0,0 +1,176 @@
+package poker_test
+
+import (
+	"bytes"
+	"github.com/vetch101/go-tddapp"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestBroadcast(t *testing.T) {
+
+	level := poker.BlindEvent{Type: poker.LevelStarted, Level: 1, BigBlind: 100}
+
+	t.Run("sends every event to every subscriber", func(t *testing.T) {
+		broadcast := poker.NewBroadcast()
+		first, second := &bytes.Buffer{}, &bytes.Buffer{}
+
+		broadcast.Subscribe(poker.TextRenderer(first))
+		broadcast.Subscribe(poker.TextRenderer(second))
+
+		poker.AssertNoError(t, broadcast.Alert(level))
+
+		for _, out := range []*bytes.Buffer{first, second} {
+			if want := "Blind is now 100\n"; out.String() != want {
+				t.Errorf("got %q want %q", out.String(), want)
+			}
+		}
+	})
+
+	t.Run("subscribers can leave without disturbing the others", func(t *testing.T) {
+		broadcast := poker.NewBroadcast()
+		leaving, staying := &bytes.Buffer{}, &bytes.Buffer{}
+
+		unsubscribe := broadcast.Subscribe(poker.TextRenderer(leaving))
+		broadcast.Subscribe(poker.TextRenderer(staying))
+
+		unsubscribe()
+		broadcast.Alert(level)
+
+		if leaving.String() != "" || staying.String() == "" {
+			t.Errorf("got %q sent to who left and %q to who stayed", leaving.String(), staying.String())
+		}
+		assertSubscribers(t, broadcast, 1)
+	})
+
+	t.Run("drops subscribers that have gone away", func(t *testing.T) {
+		broadcast := poker.NewBroadcast()
+		staying := &bytes.Buffer{}
+
+		broadcast.Subscribe(poker.TextRenderer(brokenWriter{}))
+		broadcast.Subscribe(poker.TextRenderer(staying))
+
+		broadcast.Alert(level)
+
+		assertSubscribers(t, broadcast, 1)
+
+		if staying.String() == "" {
+			t.Error("the subscriber still there should have got the alert")
+		}
+	})
+
+	t.Run("sends the level being played to subscribers joining mid-game", func(t *testing.T) {
+		broadcast := poker.NewBroadcast()
+		broadcast.Alert(level)
+		broadcast.Alert(poker.BlindEvent{Type: poker.BreakStarted, Level: 1})
+
+		late := &bytes.Buffer{}
+		broadcast.Subscribe(poker.TextRenderer(late))
+
+		if want := "Blind is now 100\n"; late.String() != want {
+			t.Errorf("got %q want %q", late.String(), want)
+		}
+
+		broadcast.Forget()
+
+		afterwards := &bytes.Buffer{}
+		broadcast.Subscribe(poker.TextRenderer(afterwards))
+
+		if afterwards.String() != "" {
+			t.Errorf("got %q sent once the game was forgotten", afterwards.String())
+		}
+	})
+
+	t.Run("a subscriber can unsubscribe as it takes an event", func(t *testing.T) {
+		broadcast := poker.NewBroadcast()
+		var unsubscribe func()
+
+		unsubscribe = broadcast.Subscribe(poker.BlindSinkFunc(func(poker.BlindEvent) error {
+			unsubscribe()
+			return nil
+		}))
+
+		broadcast.Alert(level)
+
+		assertSubscribers(t, broadcast, 0)
+	})
+
+	t.Run("a slow subscriber doesn't hold up others subscribing", func(t *testing.T) {
+		broadcast := poker.NewBroadcast()
+		taking, release := make(chan struct{}), make(chan struct{})
+
+		broadcast.Subscribe(poker.BlindSinkFunc(func(poker.BlindEvent) error {
+			close(taking)
+			<-release
+			return nil
+		}))
+
+		go broadcast.Alert(level)
+		<-taking
+		defer close(release)
+
+		subscribed := make(chan struct{})
+
+		go func() {
+			broadcast.Subscribe(poker.TextRenderer(&bytes.Buffer{}))
+			close(subscribed)
+		}()
+
+		select {
+		case <-subscribed:
+		case <-time.After(time.Second):
+			t.Fatal("subscribing waited for the slow subscriber")
+		}
+	})
+}
+
+func TestGame_BroadcastsBlinds(t *testing.T) {
+	clock := poker.NewFakeClock(time.Now())
+	game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+	game.SetClock(clock)
+	game.SetRenderer(poker.TextRenderer)
+
+	started := &bytes.Buffer{}
+	game.Start(5, started)
+
+	log := &bytes.Buffer{}
+	unsubscribe := game.Alerts().Subscribe(poker.JSONRenderer(log))
+
+	clock.Advance(10 * time.Minute)
+	unsubscribe()
+	clock.Advance(10 * time.Minute)
+
+	if want := "Blind is now 100\nBlind is now 200\nBlind is now 300\n"; started.String() != want {
+		t.Errorf("got %q where the game started want %q", started.String(), want)
+	}
+
+	want := `{"type":"level","level":1,"small_blind":50,"big_blind":100,"next":"10m0s"}` + "\n" +
+		`{"type":"level","level":2,"small_blind":100,"big_blind":200,"next":"10m0s"}` + "\n"
+
+	if log.String() != want {
+		t.Errorf("got %q logged want %q", log.String(), want)
+	}
+
+	game.Finish("Ruth")
+	game.Start(5, &bytes.Buffer{})
+	defer game.Finish("Ruth")
+	clock.Advance(10 * time.Minute)
+
+	if started.String() != "Blind is now 100\nBlind is now 200\nBlind is now 300\n" {
+		t.Errorf("got %q, the last game's destination should have left", started.String())
+	}
+}
+
+type brokenWriter struct{}
+
+func (brokenWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func assertSubscribers(t *testing.T, broadcast *poker.Broadcast, want int) {
+	t.Helper()
+	if got := broadcast.Subscribers(); got != want {
+		t.Errorf("got %d subscribers want %d", got, want)
+	}
+}
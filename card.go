@@ -0,0 +1,107 @@
+package poker
+
+import "strings"
+
+// Rank is the rank of a Card, from Two up to Ace
+type Rank int
+
+// The ranks of a Card, valued so that a higher Rank beats a lower one
+const (
+	Two Rank = iota + 2
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Ace
+)
+
+// Suit is the suit of a Card
+type Suit int
+
+// The four suits, which are never used to break ties
+const (
+	Clubs Suit = iota
+	Diamonds
+	Hearts
+	Spades
+)
+
+const rankLetters = "23456789TJQKA"
+
+const suitLetters = "cdhs"
+
+// Card is a single playing card
+type Card struct {
+	Rank Rank
+	Suit Suit
+}
+
+// String writes the card as its rank then suit, such as "As" or "Td"
+func (c Card) String() string {
+	return c.Rank.String() + c.Suit.String()
+}
+
+func (r Rank) String() string {
+	if r < Two || r > Ace {
+		return "?"
+	}
+	return string(rankLetters[r-Two])
+}
+
+func (s Suit) String() string {
+	if s < Clubs || s > Spades {
+		return "?"
+	}
+	return string(suitLetters[s])
+}
+
+// ParseCard reads a card written the way Card.String writes it
+func ParseCard(s string) (Card, error) {
+
+	if len(s) != 2 {
+		return Card{}, ErrBadCard
+	}
+
+	rank := strings.IndexByte(rankLetters, strings.ToUpper(s)[0])
+	suit := strings.IndexByte(suitLetters, strings.ToLower(s)[1])
+
+	if rank < 0 || suit < 0 {
+		return Card{}, ErrBadCard
+	}
+
+	return Card{Rank: Two + Rank(rank), Suit: Suit(suit)}, nil
+}
+
+// ParseCards reads space separated cards, such as "As Kd 7c"
+func ParseCards(s string) ([]Card, error) {
+
+	cards := []Card{}
+
+	for _, field := range strings.Fields(s) {
+		card, err := ParseCard(field)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// formatCards writes cards the way ParseCards reads them
+func formatCards(cards []Card) string {
+	names := make([]string, len(cards))
+	for i, card := range cards {
+		names[i] = card.String()
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,168 @@
+package poker_test
+
+import (
+	"fmt"
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"testing"
+)
+
+func TestCard(t *testing.T) {
+
+	t.Run("every card in a deck reads back as itself", func(t *testing.T) {
+		deck := poker.NewDeck()
+
+		for deck.Remaining() > 0 {
+			card, _ := deck.Deal()
+
+			got, err := poker.ParseCard(card.String())
+			poker.AssertNoError(t, err)
+
+			if got != card {
+				t.Errorf("got %v reading %q want %v", got, card.String(), card)
+			}
+		}
+	})
+
+	t.Run("reads ranks and suits in either case", func(t *testing.T) {
+		got, err := poker.ParseCard("tD")
+		poker.AssertNoError(t, err)
+
+		if want := (poker.Card{Rank: poker.Ten, Suit: poker.Diamonds}); got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("rejects what isn't a card", func(t *testing.T) {
+		for _, input := range []string{"", "A", "1s", "Ax", "10s"} {
+			_, err := poker.ParseCard(input)
+
+			if err != poker.ErrBadCard {
+				t.Errorf("got error %v reading %q want %v", err, input, poker.ErrBadCard)
+			}
+		}
+	})
+}
+
+func TestDeck(t *testing.T) {
+
+	t.Run("has 52 different cards", func(t *testing.T) {
+		deck := poker.NewDeck()
+		seen := map[poker.Card]bool{}
+
+		for deck.Remaining() > 0 {
+			card, err := deck.Deal()
+			poker.AssertNoError(t, err)
+			seen[card] = true
+		}
+
+		if len(seen) != 52 {
+			t.Errorf("got %d different cards want 52", len(seen))
+		}
+	})
+
+	t.Run("can't deal from an empty deck", func(t *testing.T) {
+		deck := poker.NewDeck()
+
+		for deck.Remaining() > 0 {
+			deck.Burn()
+		}
+
+		_, err := deck.Deal()
+
+		if err != poker.ErrDeckEmpty {
+			t.Errorf("got error %v want %v", err, poker.ErrDeckEmpty)
+		}
+	})
+
+	t.Run("shuffles the same way for the same seed", func(t *testing.T) {
+		first, second, other := poker.NewDeck(), poker.NewDeck(), poker.NewDeck()
+
+		first.Shuffle(poker.NewRandom(42))
+		second.Shuffle(poker.NewRandom(42))
+		other.Shuffle(poker.NewRandom(7))
+
+		firstCards, secondCards, otherCards := dealAll(first), dealAll(second), dealAll(other)
+
+		if firstCards != secondCards {
+			t.Errorf("got different shuffles for the same seed\n%s\n%s", firstCards, secondCards)
+		}
+
+		if firstCards == otherCards {
+			t.Errorf("got the same shuffle for different seeds %s", firstCards)
+		}
+	})
+}
+
+func TestDeal(t *testing.T) {
+
+	// an unshuffled deck starts 2c 3c 4c ... Ac 2d 3d
+	t.Run("deals hole cards round the table then burns before each street", func(t *testing.T) {
+		deal, err := poker.NewDeal(poker.NewDeck(), 3)
+		poker.AssertNoError(t, err)
+
+		assertCards(t, deal.Hole(0), "2c 5c")
+		assertCards(t, deal.Hole(1), "3c 6c")
+		assertCards(t, deal.Hole(2), "4c 7c")
+
+		streets := []struct {
+			street poker.Street
+			board  string
+		}{
+			{poker.Flop, "9c Tc Jc"},
+			{poker.Turn, "9c Tc Jc Kc"},
+			{poker.River, "9c Tc Jc Kc 2d"},
+			{poker.Showdown, "9c Tc Jc Kc 2d"},
+		}
+
+		for _, s := range streets {
+			poker.AssertNoError(t, deal.Next())
+
+			if deal.Street() != s.street {
+				t.Errorf("got street %v want %v", deal.Street(), s.street)
+			}
+			assertCards(t, deal.Board(), s.board)
+		}
+
+		if err := deal.Next(); err != poker.ErrDealFinished {
+			t.Errorf("got error %v want %v", err, poker.ErrDealFinished)
+		}
+	})
+
+	t.Run("needs between 2 and 22 players", func(t *testing.T) {
+		for _, players := range []int{1, 23} {
+			_, err := poker.NewDeal(poker.NewDeck(), players)
+
+			if err != poker.ErrDealPlayers {
+				t.Errorf("got error %v dealing %d players want %v", err, players, poker.ErrDealPlayers)
+			}
+		}
+
+		deal, err := poker.NewDeal(poker.NewDeck(), poker.MaxPlayers)
+		poker.AssertNoError(t, err)
+
+		for deal.Street() != poker.Showdown {
+			poker.AssertNoError(t, deal.Next())
+		}
+	})
+}
+
+func dealAll(deck *poker.Deck) string {
+	cards := []poker.Card{}
+	for deck.Remaining() > 0 {
+		card, _ := deck.Deal()
+		cards = append(cards, card)
+	}
+	return fmt.Sprint(cards)
+}
+
+func assertCards(t *testing.T, got []poker.Card, want string) {
+	t.Helper()
+
+	wantCards, err := poker.ParseCards(want)
+	poker.AssertNoError(t, err)
+
+	if !reflect.DeepEqual(got, wantCards) {
+		t.Errorf("got cards %v want %v", got, wantCards)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"strconv"
 	"strings"
 )
 
@@ -16,8 +15,8 @@ type CLI struct {
 	game Game
 }
 
-// PlayerPrompt is the prompt for number of players
-const PlayerPrompt = "Please enter the number of players: "
+// PlayerPrompt is the prompt for the number of players, or their names
+const PlayerPrompt = "Please enter the number of players or their names separated by commas: "
 
 // BadWinnerInputMsg is the prompt for a bad winner input
 const BadWinnerInputMsg = "You entered an incorrect value. Please enter '{Playername} wins'"
@@ -37,24 +36,44 @@ func (cli *CLI) PlayPoker() {
 	fmt.Fprint(cli.out, PlayerPrompt)
 
 	numberOfPlayersInput := cli.readLine()
-	numberOfPlayers, err := strconv.Atoi(strings.Trim(numberOfPlayersInput, "\n"))
+	numberOfPlayers, players, err := ParsePlayers(numberOfPlayersInput)
 
 	if err != nil {
 		fmt.Fprint(cli.out, ErrBadPlayerInput)
 		return
 	}
 
-	cli.game.Start(numberOfPlayers, cli.out)
+	if err := cli.game.Start(numberOfPlayers, cli.out, players...); err != nil {
+		fmt.Fprintln(cli.out, err)
+		return
+	}
 
 	winnerInput := cli.readLine()
 
+	for playTurn(cli.game, cli.out, winnerInput) {
+		// a hand with a single winner finishes the game without asking who won
+		if winners := cli.game.Winners(); len(winners) == 1 {
+			cli.finish(winners[0])
+			return
+		}
+
+		winnerInput = cli.readLine()
+	}
+
 	if strings.Contains(winnerInput, " wins") == false {
 		fmt.Fprint(cli.out, BadWinnerInputMsg)
 	}
 
 	winner := extractWinner(winnerInput)
 
-	cli.game.Finish(winner)
+	cli.finish(winner)
+}
+
+// finish finishes the game with winner, telling the user if it couldn't be
+func (cli *CLI) finish(winner string) {
+	if err := cli.game.Finish(winner); err != nil {
+		fmt.Fprintln(cli.out, err)
+	}
 }
 
 func extractWinner(userInput string) string {
@@ -66,3 +85,52 @@ func (cli *CLI) readLine() string {
 	cli.in.Scan()
 	return cli.in.Text()
 }
+
+// PrintRatings writes each player's rating, highest first, one per line
+func PrintRatings(out io.Writer, ratings Ratings) {
+	for _, rating := range ratings {
+		fmt.Fprintf(out, "%s %.0f (%d games)\n", rating.Name, rating.Rating, rating.Games)
+	}
+}
+
+// PrintSeasons writes each season, in the order they started, one per line
+func PrintSeasons(out io.Writer, seasons []Season) {
+	for _, season := range seasons {
+		state := "open"
+		if !season.Open() {
+			state = "closed " + season.Closed.Format(historyDateFormat)
+		}
+		fmt.Fprintf(out, "%s started %s, %s\n", season.Name, season.Started.Format(historyDateFormat), state)
+	}
+}
+
+// PrintStandings writes where each player finished in a tournament, along with their rebuys and add-ons, one per line
+func PrintStandings(out io.Writer, standings []Standing) {
+	for _, standing := range standings {
+		line := standing.Name
+
+		if standing.Position > 0 {
+			line = ordinal(standing.Position) + " " + line
+		}
+
+		switch {
+		case standing.Rebuys == 1:
+			line += ", 1 rebuy"
+		case standing.Rebuys > 1:
+			line += fmt.Sprintf(", %d rebuys", standing.Rebuys)
+		}
+
+		if standing.AddOn {
+			line += ", add-on"
+		}
+
+		fmt.Fprintln(out, line)
+	}
+}
+
+// PrintLeague writes each player's wins, losses and games played, one per line
+func PrintLeague(out io.Writer, league League) {
+	for _, player := range league {
+		fmt.Fprintf(out, "%s %d won %d lost of %d\n", player.Name, player.Wins, player.Losses, player.GamesPlayed)
+	}
+}
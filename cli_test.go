@@ -4,28 +4,149 @@ import (
 	"bytes"
 	"github.com/vetch101/go-tddapp"
 	"io"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type GameSpy struct {
-	StartCalled bool
-	StartedWith int
-	BlindAlert  []byte
+	mu        sync.Mutex
+	lifecycle poker.Lifecycle
+
+	StartCalled        bool
+	StartedWith        int
+	StartedWithPlayers []string
+	BlindAlert         []byte
+
+	Clock           *poker.BlindClock
+	Broadcast       *poker.Broadcast
+	Tourney         *poker.Tournament
+	Actions         []poker.Action
+	DealtStreets    int
+	ShowdownWinners []string
 
 	FinishCalled bool
 	FinishedWith string
+	AbortCalled  bool
 }
 
-func (g *GameSpy) Start(numberOfPlayers int, out io.Writer) {
+func (g *GameSpy) Start(numberOfPlayers int, out io.Writer, players ...string) error {
+	if err := g.lifecycle.Start(); err != nil {
+		return err
+	}
+	g.mu.Lock()
 	g.StartCalled = true
 	g.StartedWith = numberOfPlayers
+	g.StartedWithPlayers = players
+	g.mu.Unlock()
 	out.Write(g.BlindAlert)
+	return nil
+}
+
+func (g *GameSpy) Pause() error {
+	if err := g.lifecycle.Pause(); err != nil {
+		return err
+	}
+	if g.Clock != nil {
+		g.Clock.Pause()
+	}
+	return nil
+}
+
+func (g *GameSpy) Resume() error {
+	if err := g.lifecycle.Resume(); err != nil {
+		return err
+	}
+	if g.Clock != nil {
+		g.Clock.Resume()
+	}
+	return nil
 }
 
-func (g *GameSpy) Finish(winner string) {
+func (g *GameSpy) State() poker.GameState {
+	return g.lifecycle.State()
+}
+
+func (g *GameSpy) Act(action poker.Action) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.Actions = append(g.Actions, action)
+	return nil
+}
+
+func (g *GameSpy) NextStreet() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.DealtStreets++
+	return nil
+}
+
+func (g *GameSpy) BlindClock() *poker.BlindClock {
+	return g.Clock
+}
+
+func (g *GameSpy) Alerts() *poker.Broadcast {
+	return g.Broadcast
+}
+
+func (g *GameSpy) Tournament() *poker.Tournament {
+	return g.Tourney
+}
+
+func (g *GameSpy) Winners() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.DealtStreets < 4 {
+		return nil
+	}
+	return g.ShowdownWinners
+}
+
+func (g *GameSpy) Finish(winner string) error {
+	if err := g.lifecycle.Finish(); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	g.FinishCalled = true
 	g.FinishedWith = winner
+	return nil
+}
+
+func (g *GameSpy) Abort() error {
+	if err := g.lifecycle.Abort(); err != nil {
+		return err
+	}
+	if g.Clock != nil {
+		g.Clock.Stop()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.AbortCalled = true
+	return nil
+}
+
+// started reports the Start call, safe to use while a server is driving the spy
+func (g *GameSpy) started() (bool, int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.StartCalled, g.StartedWith
+}
+
+// finished reports the Finish call, safe to use while a server is driving the spy
+func (g *GameSpy) finished() (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.FinishCalled, g.FinishedWith
+}
+
+// dealt reports the NextStreet calls, safe to use while a server is driving the spy
+func (g *GameSpy) dealt() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.DealtStreets
 }
 
 func userSends(messages ...string) io.Reader {
@@ -52,6 +173,20 @@ func TestCLI(t *testing.T) {
 
 	})
 
+	t.Run("it starts the game with the players' names", func(t *testing.T) {
+		in := userSends("Chris, Cleo,Bob", "Bob wins")
+		game := &GameSpy{}
+
+		cli := poker.NewCLI(in, dummyStdOut, game)
+		cli.PlayPoker()
+
+		assertNumberOfPlayers(t, game.StartedWith, 3)
+
+		if !reflect.DeepEqual(game.StartedWithPlayers, []string{"Chris", "Cleo", "Bob"}) {
+			t.Errorf("wanted Start called with Chris, Cleo and Bob but got %v", game.StartedWithPlayers)
+		}
+	})
+
 	t.Run("finish game with Chris as winner", func(t *testing.T) {
 
 		in := userSends("1", "Chris wins")
@@ -65,6 +200,76 @@ func TestCLI(t *testing.T) {
 
 	})
 
+	t.Run("deals a street each time deal is entered before the winner", func(t *testing.T) {
+		in := userSends("3", "deal", "deal", "Chris wins")
+		game := &GameSpy{}
+
+		cli := poker.NewCLI(in, dummyStdOut, game)
+		cli.PlayPoker()
+
+		if game.DealtStreets != 2 {
+			t.Errorf("got %d streets dealt want 2", game.DealtStreets)
+		}
+		assertGameWonBy(t, "Chris", game.FinishedWith)
+	})
+
+	t.Run("takes betting actions until the winner", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		in := userSends("3", "call", "raise 400", "bet", "fold", "Chris wins")
+		game := &GameSpy{}
+
+		cli := poker.NewCLI(in, stdout, game)
+		cli.PlayPoker()
+
+		want := []poker.Action{{Type: poker.Call}, {Type: poker.Raise, Amount: 400}, {Type: poker.Fold}}
+
+		if !reflect.DeepEqual(game.Actions, want) {
+			t.Errorf("got actions %v want %v", game.Actions, want)
+		}
+		assertMessageSentToUser(t, stdout, poker.PlayerPrompt, poker.ErrBadAction.Error()+"\n")
+		assertGameWonBy(t, "Chris", game.FinishedWith)
+	})
+
+	t.Run("pauses, resumes and skips the blind clock", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		in := userSends("3", "pause", "skip", "pause", "resume", "Chris wins")
+		clock := poker.NewBlindClock(poker.NewFakeClock(time.Now()))
+		clock.Schedule(time.Hour, levelOf(200), spySink(&SpyBlindAlerter{}))
+		clock.Resume()
+		defer clock.Stop()
+		game := &GameSpy{Clock: clock}
+
+		cli := poker.NewCLI(in, stdout, game)
+		cli.PlayPoker()
+
+		assertMessageSentToUser(t, stdout, poker.PlayerPrompt, poker.StateError{State: poker.GamePaused, Action: "paused"}.Error()+"\n")
+
+		if clock.Paused() || len(clock.Pending()) != 0 {
+			t.Errorf("got the clock paused %v with %v to come, want it running with the blind sent", clock.Paused(), clock.Pending())
+		}
+		assertGameWonBy(t, "Chris", game.FinishedWith)
+	})
+
+	t.Run("finishes the game with the winner of the showdown", func(t *testing.T) {
+		in := userSends("Chris,Cleo", "deal", "deal", "deal", "deal")
+		game := &GameSpy{ShowdownWinners: []string{"Cleo"}}
+
+		cli := poker.NewCLI(in, dummyStdOut, game)
+		cli.PlayPoker()
+
+		assertGameWonBy(t, "Cleo", game.FinishedWith)
+	})
+
+	t.Run("asks who won when the showdown splits the pot", func(t *testing.T) {
+		in := userSends("Chris,Cleo", "deal", "deal", "deal", "deal", "Chris wins")
+		game := &GameSpy{ShowdownWinners: []string{"Chris", "Cleo"}}
+
+		cli := poker.NewCLI(in, dummyStdOut, game)
+		cli.PlayPoker()
+
+		assertGameWonBy(t, "Chris", game.FinishedWith)
+	})
+
 	t.Run("prints error on non-numeric value entered + does not start", func(t *testing.T) {
 		stdout := &bytes.Buffer{}
 		in := userSends("blah")
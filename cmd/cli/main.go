@@ -1,28 +1,296 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/vetch101/go-tddapp"
 	"log"
 	"os"
+	"strconv"
 )
 
-const dbFileName = "game.db.json"
+var dbFileNames = map[string]string{
+	poker.FileBackend: "game.db.json",
+	poker.LogBackend:  "game.db.log",
+	poker.BoltBackend: "game.db",
+}
+
+var backend = flag.String("store", poker.FileBackend, "player store backend: file, log or bolt")
+
+var alertLog = flag.String("alert-log", "", "file to also log every game's blind alerts to as json")
+
+var blinds = flag.String("blinds", "", "blind structure: turbo, standard, deep-stack or a json or yaml file, "+
+	"going up with the number of players if it's empty")
+
+var alerts = flag.String("alerts", poker.TextAlerts, "how blind alerts are written: text or json")
+
+var tournament = flag.String("tournament", "", "play tournaments: freeze-out or rebuys={levels},max-rebuys={number},add-on={level},table-size={players}, "+
+	"or single games if it's empty")
+
+var payouts = flag.String("payouts", poker.StandardPayouts, "how tournaments pay out: standard, winner-takes-all or a json or yaml file")
+
+var buyIn = flag.Int("buy-in", 0, "what it costs to enter a tournament, whose prize pool isn't paid out if it's 0")
+
+var rebuy = flag.Int("rebuy", 0, "what a tournament rebuy costs")
+
+var addOn = flag.Int("add-on", 0, "what a tournament add-on costs")
+
+var variant = flag.String("variant", poker.TexasHoldEmName, "kind of poker: texas-holdem, omaha, pot-limit-omaha or seven-card-stud")
+
+var betting = flag.String("betting", "", "betting structure: no-limit, pot-limit or fixed-limit,small-bet={chips},big-bet={chips},cap={raises}, "+
+	"or the variant's own if it's empty")
+
+var league = flag.String("league", "", "league to play in or manage, the main league if it's empty")
+
+const usage = `usage: cli [-store backend] [-league name] [-variant name] [-betting structure] [-blinds structure] [-tournament rules] [-buy-in cost] [-rebuy cost] [-add-on cost]
+       [-payouts structure] [-alerts format] [-alert-log file] [command]
+
+With no command a game is played. The commands are:
+  ratings              print every player's rating
+  league [season]      print the league's table, for one season or across all of them
+  seasons              print the league's seasons
+  start-season name    start a new season of the league
+  close-season name    close a season of the league
+  payouts [-rebuys n] [-add-ons n] [-chop stacks] entrants
+                       print a tournament's prize pool and payouts, and how ICM chops
+                       them between the players left with the comma separated stacks
+`
 
 func main() {
 
-	store, close, err := poker.FileSystemStoreFromFile(dbFileName)
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	store, close, err := poker.StoreFromFile(*backend, dbFileNames[*backend])
 
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer close()
 
+	if flag.NArg() > 0 {
+		err = runCommand(store, flag.Args())
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	fmt.Println("Let's play poker")
+	fmt.Println("Type fold, check, call, bet {amount}, raise {amount} or all-in to bet")
+	fmt.Println("Type deal to deal the next street")
+	fmt.Println("Type pause, resume or skip to control the blinds")
 	fmt.Println("Type {Name} wins to record a win")
+	if *tournament != "" {
+		fmt.Println("Type {Name} out, rebuy {Name}, add-on {Name} or enter {Name} to run the tournament, and tables to see the seating")
+	}
 	alerter := poker.BlindAlerterFunc(poker.Alerter)
 
 	game := poker.NewTexasHoldEm(alerter, store)
+	game.SetLeague(*league)
+	setVariant(game)
+	setBettingStructure(game)
+	setBlindStructure(game)
+	setTournament(game)
+	setPayouts(game)
+
+	renderer, err := poker.RendererFor(*alerts)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	game.SetRenderer(renderer)
+
+	closeLog := logAlerts(game)
+	defer closeLog()
 	cli := poker.NewCLI(os.Stdin, os.Stdout, game)
 	cli.PlayPoker()
 }
+
+func runCommand(store poker.PlayerStore, args []string) error {
+
+	switch {
+	case args[0] == "ratings":
+		poker.PrintRatings(os.Stdout, store.GetRatings())
+	case args[0] == "league":
+		season := ""
+		if len(args) > 1 {
+			season = args[1]
+		}
+		table, err := store.GetLeagueTable(*league, season)
+		if err != nil {
+			return err
+		}
+		poker.PrintLeague(os.Stdout, table)
+	case args[0] == "seasons":
+		poker.PrintSeasons(os.Stdout, store.GetSeasons(*league))
+	case args[0] == "start-season" && len(args) == 2:
+		return store.StartSeason(*league, args[1])
+	case args[0] == "close-season" && len(args) == 2:
+		return store.CloseSeason(*league, args[1])
+	case args[0] == "payouts":
+		return printPayouts(args[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	return nil
+}
+
+// setVariant plays game as the kind of poker chosen by the variant flag
+func setVariant(game *poker.TexasHoldEm) {
+
+	v, err := poker.VariantFor(*variant)
+
+	if err != nil {
+		log.Fatalf("could not play %s, %v", *variant, err)
+	}
+
+	game.SetVariant(v)
+}
+
+// setBettingStructure plays game with the betting structure chosen by the betting flag, if there is one
+func setBettingStructure(game *poker.TexasHoldEm) {
+
+	if *betting == "" {
+		return
+	}
+
+	structure, err := poker.ParseBettingStructure(*betting)
+
+	if err != nil {
+		log.Fatalf("could not bet %s, %v", *betting, err)
+	}
+
+	game.SetBettingStructure(structure)
+}
+
+// setBlindStructure plays game with the structure chosen by the blinds flag, if there is one
+func setBlindStructure(game *poker.TexasHoldEm) {
+
+	if *blinds == "" {
+		return
+	}
+
+	structure, err := poker.BlindStructureFrom(*blinds)
+
+	if err != nil {
+		log.Fatalf("could not use blind structure %s, %v", *blinds, err)
+	}
+
+	game.SetBlindStructure(structure)
+}
+
+// logAlerts appends the blinds of every game to the file named by the alert-log flag, if there is one,
+// and returns the func that closes it
+func logAlerts(game *poker.TexasHoldEm) func() {
+
+	if *alertLog == "" {
+		return func() {}
+	}
+
+	file, err := os.OpenFile(*alertLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		log.Fatalf("could not open alert log %s, %v", *alertLog, err)
+	}
+
+	game.Alerts().Subscribe(poker.JSONRenderer(file))
+
+	return func() {
+		file.Close()
+	}
+}
+
+// setTournament plays game as a tournament with the rules chosen by the tournament flag, if there are any
+func setTournament(game *poker.TexasHoldEm) {
+
+	if *tournament == "" {
+		return
+	}
+
+	rules, err := poker.ParseTournamentRules(*tournament)
+
+	if err != nil {
+		log.Fatalf("could not play tournament %s, %v", *tournament, err)
+	}
+
+	game.SetTournament(rules)
+}
+
+// setPayouts pays out tournaments that have a buy-in with the structure chosen by the payouts flag
+func setPayouts(game *poker.TexasHoldEm) {
+
+	if *tournament == "" || *buyIn == 0 {
+		return
+	}
+
+	game.SetPayouts(buyIns(), payoutStructure())
+}
+
+// payoutStructure is the structure chosen by the payouts flag
+func payoutStructure() poker.PayoutStructure {
+
+	structure, err := poker.PayoutStructureFrom(*payouts)
+
+	if err != nil {
+		log.Fatalf("could not use payout structure %s, %v", *payouts, err)
+	}
+
+	return structure
+}
+
+// buyIns are the costs set by the buy-in, rebuy and add-on flags
+func buyIns() poker.BuyIns {
+	return poker.BuyIns{BuyIn: *buyIn, Rebuy: *rebuy, AddOn: *addOn}
+}
+
+// printPayouts prints the payouts of a tournament with the entrants, rebuys and add-ons in args,
+// along with how ICM chops them if it's given the stacks of the players left
+func printPayouts(args []string) error {
+
+	command := flag.NewFlagSet("payouts", flag.ExitOnError)
+	command.Usage = flag.Usage
+	rebuys := command.Int("rebuys", 0, "number of rebuys")
+	addOns := command.Int("add-ons", 0, "number of add-ons")
+	chop := command.String("chop", "", "comma separated stacks of the players chopping")
+	command.Parse(args)
+
+	if command.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	entrants, err := strconv.Atoi(command.Arg(0))
+
+	if err != nil {
+		return poker.ErrBadPayoutRequest
+	}
+
+	request := poker.PayoutRequest{Entrants: entrants, Rebuys: *rebuys, AddOns: *addOns, BuyIns: buyIns()}
+
+	if *chop != "" {
+		if request.Chop, err = poker.ParseChop(*chop); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := payoutStructure().Sheet(request)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("prize pool %d\n", sheet.PrizePool)
+	poker.PrintPayouts(os.Stdout, sheet.Payouts)
+
+	for i, amount := range sheet.Chop {
+		fmt.Printf("chop %d %d\n", request.Chop[i], amount)
+	}
+
+	return nil
+}
@@ -1,16 +1,50 @@
 package main
 
 import (
+	"flag"
 	"github.com/vetch101/go-tddapp"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 )
 
-const dbFileName = "game.db.json"
+var dbFileNames = map[string]string{
+	poker.FileBackend: "game.db.json",
+	poker.LogBackend:  "game.db.log",
+	poker.BoltBackend: "game.db",
+}
+
+var backend = flag.String("store", poker.FileBackend, "player store backend: file, log or bolt")
+
+var alertLog = flag.String("alert-log", "", "file to also log every game's blind alerts to as json")
+
+var blinds = flag.String("blinds", "", "blind structure: turbo, standard, deep-stack or a json or yaml file, "+
+	"going up with the number of players if it's empty")
+
+var tournament = flag.String("tournament", "", "play tournaments: freeze-out or rebuys={levels},max-rebuys={number},add-on={level},table-size={players}, "+
+	"or single games if it's empty")
+
+var variant = flag.String("variant", poker.TexasHoldEmName, "kind of poker: texas-holdem, omaha, pot-limit-omaha or seven-card-stud")
+
+var betting = flag.String("betting", "", "betting structure: no-limit, pot-limit or fixed-limit,small-bet={chips},big-bet={chips},cap={raises}, "+
+	"or the variant's own if it's empty")
+
+var payouts = flag.String("payouts", poker.StandardPayouts, "how tournaments pay out: standard, winner-takes-all or a json or yaml file")
+
+var buyIn = flag.Int("buy-in", 0, "what it costs to enter a tournament, whose prize pool isn't paid out if it's 0")
+
+var rebuy = flag.Int("rebuy", 0, "what a tournament rebuy costs")
+
+var addOn = flag.Int("add-on", 0, "what a tournament add-on costs")
 
 func main() {
 
-	store, close, err := poker.FileSystemStoreFromFile(dbFileName)
+	flag.Parse()
+
+	store, close, err := poker.StoreFromFile(*backend, dbFileNames[*backend])
 
 	if err != nil {
 		log.Fatal(err)
@@ -18,11 +52,163 @@ func main() {
 	defer close()
 
 	alerter := poker.BlindAlerterFunc(poker.Alerter)
-	game := poker.NewTexasHoldEm(alerter, store)
+	structure := blindStructure()
+	rules, isTournament := tournamentRules()
+	payoutStructure := payoutStructure()
+	logFile, closeLog := openAlertLog()
+	defer closeLog()
+
+	defaultVariant, err := poker.VariantFor(*variant)
+
+	if err != nil {
+		log.Fatalf("could not play %s, %v", *variant, err)
+	}
+
+	bettingStructure := bettingStructure()
+
+	newGame := func(variant poker.Variant) *poker.TexasHoldEm {
+		game := poker.NewTexasHoldEm(alerter, store)
+		game.SetVariant(variant)
+		if bettingStructure != nil {
+			game.SetBettingStructure(bettingStructure)
+		}
+		game.SetBlindStructure(structure)
+
+		if isTournament {
+			game.SetTournament(rules)
+
+			if *buyIn > 0 {
+				game.SetPayouts(buyIns(), payoutStructure)
+			}
+		}
+		game.SetRenderer(poker.WebSocketRenderer)
 
-	server, _ := poker.NewPlayerServer(store, game)
+		if logFile != nil {
+			game.Alerts().Subscribe(poker.JSONRenderer(logFile))
+		}
+
+		return game
+	}
+
+	games := poker.NewGameManager(func() poker.Game {
+		return newGame(defaultVariant)
+	})
+	games.SetVariants(func(name string) (poker.Game, error) {
+		variant, err := poker.VariantFor(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return newGame(variant), nil
+	})
+
+	stopCleanup := games.CleanupEvery(time.Minute)
+	defer stopCleanup()
+
+	server, _ := poker.NewPlayerServer(store, games)
 
 	if err := http.ListenAndServe(":5000", server); err != nil {
 		log.Fatalf("could not listen on port 5000 %v", err)
 	}
 }
+
+// blindStructure is the structure chosen by the blinds flag, which has no levels if there isn't one
+// so that games are played with the default structure for their number of players
+func blindStructure() poker.BlindStructure {
+
+	if *blinds == "" {
+		return poker.BlindStructure{}
+	}
+
+	structure, err := poker.BlindStructureFrom(*blinds)
+
+	if err != nil {
+		log.Fatalf("could not use blind structure %s, %v", *blinds, err)
+	}
+
+	return structure
+}
+
+// tournamentRules are the rules chosen by the tournament flag, reporting whether there are any
+func tournamentRules() (poker.TournamentRules, bool) {
+
+	if *tournament == "" {
+		return poker.TournamentRules{}, false
+	}
+
+	rules, err := poker.ParseTournamentRules(*tournament)
+
+	if err != nil {
+		log.Fatalf("could not play tournament %s, %v", *tournament, err)
+	}
+
+	return rules, true
+}
+
+// bettingStructure is the betting structure chosen by the betting flag, or nil to play each variant's own
+func bettingStructure() poker.BettingStructure {
+
+	if *betting == "" {
+		return nil
+	}
+
+	structure, err := poker.ParseBettingStructure(*betting)
+
+	if err != nil {
+		log.Fatalf("could not bet %s, %v", *betting, err)
+	}
+
+	return structure
+}
+
+// payoutStructure is the structure chosen by the payouts flag
+func payoutStructure() poker.PayoutStructure {
+
+	structure, err := poker.PayoutStructureFrom(*payouts)
+
+	if err != nil {
+		log.Fatalf("could not use payout structure %s, %v", *payouts, err)
+	}
+
+	return structure
+}
+
+// buyIns are the costs set by the buy-in, rebuy and add-on flags
+func buyIns() poker.BuyIns {
+	return poker.BuyIns{BuyIn: *buyIn, Rebuy: *rebuy, AddOn: *addOn}
+}
+
+// openAlertLog opens the file named by the alert-log flag for the blinds of every game to be appended to,
+// if there is one, and returns the func that closes it
+func openAlertLog() (io.Writer, func()) {
+
+	if *alertLog == "" {
+		return nil, func() {}
+	}
+
+	file, err := os.OpenFile(*alertLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		log.Fatalf("could not open alert log %s, %v", *alertLog, err)
+	}
+
+	// games are played at once, so their blinds are logged a line at a time
+	out := &lockedWriter{out: file}
+
+	return out, func() {
+		file.Close()
+	}
+}
+
+// lockedWriter takes writes to out one at a time
+type lockedWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.out.Write(p)
+}
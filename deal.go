@@ -0,0 +1,147 @@
+package poker
+
+// Street is a stage of a hand, each of which is bet on in turn
+type Street int
+
+// The streets of a hand of Texas Hold'em or Omaha, in the order they're dealt, followed by the showdown
+// that every variant ends in, and then the streets of a hand of stud
+const (
+	PreFlop Street = iota
+	Flop
+	Turn
+	River
+	Showdown
+	ThirdStreet
+	FourthStreet
+	FifthStreet
+	SixthStreet
+	SeventhStreet
+)
+
+var streetNames = []string{
+	"pre-flop", "flop", "turn", "river", "showdown",
+	"third street", "fourth street", "fifth street", "sixth street", "seventh street",
+}
+
+func (s Street) String() string {
+	if s < PreFlop || s > SeventhStreet {
+		return "unknown"
+	}
+	return streetNames[s]
+}
+
+// MaxPlayers is the most players a hand of Texas Hold'em can be dealt to from one deck
+const MaxPlayers = 22
+
+// Deal is a single hand dealt from a Deck the way its Variant says: cards for each seat, face down
+// or face up, and cards to the board, a street at a time
+type Deal struct {
+	deck    *Deck
+	variant Variant
+	round   int
+	street  Street
+	hole    [][]Card
+	up      [][]Card
+	board   []Card
+}
+
+// NewDeal deals the hole cards of a hand of Texas Hold'em from deck to numberOfPlayers seats,
+// one card at a time round the table, starting at seat 0
+func NewDeal(deck *Deck, numberOfPlayers int) (*Deal, error) {
+	return TexasHoldEmVariant.Deal(deck, numberOfPlayers)
+}
+
+// Next burns a card and deals the next street, such as three cards to the board for the flop. After
+// the last street it moves on to the showdown, where there is nothing left to deal.
+func (d *Deal) Next() error {
+
+	if d.street == Showdown {
+		return ErrDealFinished
+	}
+
+	if d.round == len(d.variant.streets)-1 {
+		d.street = Showdown
+		return nil
+	}
+
+	err := d.deck.Burn()
+
+	if err != nil {
+		return err
+	}
+
+	d.round++
+
+	return d.deal()
+}
+
+// deal deals the street of the round the hand has reached
+func (d *Deal) deal() error {
+
+	dealing := d.variant.streets[d.round]
+	d.street = dealing.street
+
+	for i := 0; i < dealing.down+dealing.up; i++ {
+		for seat := range d.hole {
+			card, err := d.deck.Deal()
+
+			if err != nil {
+				return err
+			}
+
+			d.hole[seat] = append(d.hole[seat], card)
+
+			if i >= dealing.down {
+				d.up[seat] = append(d.up[seat], card)
+			}
+		}
+	}
+
+	for i := 0; i < dealing.board; i++ {
+		card, err := d.deck.Deal()
+
+		if err != nil {
+			return err
+		}
+
+		d.board = append(d.board, card)
+	}
+
+	return nil
+}
+
+// Street is how far the hand has been dealt
+func (d *Deal) Street() Street {
+	return d.street
+}
+
+// Hole returns a copy of every card dealt to seat, face down or face up, in the order they were dealt
+func (d *Deal) Hole(seat int) []Card {
+	return copyCards(d.hole[seat])
+}
+
+// Showing returns a copy of the cards dealt face up to seat, which everyone can see
+func (d *Deal) Showing(seat int) []Card {
+	return copyCards(d.up[seat])
+}
+
+// Variant is the kind of poker being dealt
+func (d *Deal) Variant() Variant {
+	return d.variant
+}
+
+// Board returns a copy of the community cards dealt so far
+func (d *Deal) Board() []Card {
+	return copyCards(d.board)
+}
+
+// Seats is the number of seats dealt in
+func (d *Deal) Seats() int {
+	return len(d.hole)
+}
+
+func copyCards(cards []Card) []Card {
+	c := make([]Card, len(cards))
+	copy(c, cards)
+	return c
+}
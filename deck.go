@@ -0,0 +1,65 @@
+package poker
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Deck is a pack of cards that are dealt from the top
+type Deck struct {
+	cards []Card
+}
+
+// NewDeck returns the 52 cards in order, by suit and then by rank
+func NewDeck() *Deck {
+
+	cards := make([]Card, 0, 52)
+
+	for suit := Clubs; suit <= Spades; suit++ {
+		for rank := Two; rank <= Ace; rank++ {
+			cards = append(cards, Card{Rank: rank, Suit: suit})
+		}
+	}
+
+	return &Deck{cards: cards}
+}
+
+// NewRandom returns a source of randomness for shuffling seeded by seed, so that the same seed
+// always gives the same shuffle. A seed of 0 means seed it from the time.
+func NewRandom(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// Shuffle puts the cards left in the deck into an order picked by random
+func (d *Deck) Shuffle(random *rand.Rand) {
+	random.Shuffle(len(d.cards), func(i, j int) {
+		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+	})
+}
+
+// Deal takes the top card from the deck
+func (d *Deck) Deal() (Card, error) {
+
+	if len(d.cards) == 0 {
+		return Card{}, ErrDeckEmpty
+	}
+
+	card := d.cards[0]
+	d.cards = d.cards[1:]
+
+	return card, nil
+}
+
+// Burn throws away the top card, as is done before each street is dealt
+func (d *Deck) Burn() error {
+	_, err := d.Deal()
+	return err
+}
+
+// Remaining is the number of cards left in the deck
+func (d *Deck) Remaining() int {
+	return len(d.cards)
+}
@@ -11,9 +11,6 @@ const (
 	// ErrFileSeek means that there was an error seeking on file
 	ErrFileSeek = Err("problem seeking on file")
 
-	// ErrFileInfo means that there was an error getting file info from file
-	ErrFileInfo = Err("problem getting file info from file")
-
 	// ErrFileWrite means that there was an error writing to file
 	ErrFileWrite = Err("problem writing to file")
 
@@ -29,8 +26,230 @@ const (
 	// ErrEncode means there was an error during json encoding
 	ErrEncode = Err("problem encoding json")
 
+	// ErrFileRead means that there was an error reading from file
+	ErrFileRead = Err("problem reading from file")
+
+	// ErrFileSync means that there was an error flushing a file to disk
+	ErrFileSync = Err("problem syncing file")
+
+	// ErrFileRename means that there was an error renaming a file
+	ErrFileRename = Err("problem renaming file")
+
+	// ErrDirSync means that there was an error flushing a directory to disk
+	ErrDirSync = Err("problem syncing directory")
+
+	// ErrRecover means that a half-written db file could not be repaired
+	ErrRecover = Err("problem recovering player db file")
+
+	// ErrDBVersion means that the db file was written by a newer version than this one
+	ErrDBVersion = Err("player db file is from a newer version")
+
+	// ErrMigration means that the db file could not be migrated to the current version
+	ErrMigration = Err("problem migrating player db file")
+
+	// ErrUnknownBackend means that no PlayerStore backend has the name asked for
+	ErrUnknownBackend = Err("unknown player store backend")
+
+	// ErrBadDate means that a date in a query could not be parsed
+	ErrBadDate = Err("dates must be YYYY-MM-DD or RFC 3339")
+
+	// ErrBadPlayers means that the players could not be read from the input
+	ErrBadPlayers = Err("players must be a number or a comma separated list of names")
+
+	// ErrUnknownLeague means that no wins have been recorded in the league and it has no seasons
+	ErrUnknownLeague = Err("unknown league")
+
+	// ErrUnknownSeason means that the league has no season with the name asked for
+	ErrUnknownSeason = Err("unknown season")
+
+	// ErrSeasonName means that a season was started without a name
+	ErrSeasonName = Err("seasons must have a name")
+
+	// ErrSeasonExists means that the league already has a season with the name asked for
+	ErrSeasonExists = Err("season already exists")
+
+	// ErrSeasonOpen means that a season was started while the league's last season was still open
+	ErrSeasonOpen = Err("league already has an open season")
+
+	// ErrSeasonClosed means that the season has been closed and can't be changed
+	ErrSeasonClosed = Err("season is closed")
+
+	// ErrBadCard means that a card could not be read, as it wasn't a rank followed by a suit such as "As"
+	ErrBadCard = Err("cards must be a rank and a suit, such as As or Td")
+
+	// ErrDeckEmpty means that a card was dealt from a deck with none left
+	ErrDeckEmpty = Err("no cards left in the deck")
+
+	// ErrDealPlayers means that a hand was dealt to too few or too many players
+	ErrDealPlayers = Err("a hand needs at least 2 players, and no more than its variant can deal cards to")
+
+	// ErrDealFinished means that every street of the hand has already been dealt
+	ErrDealFinished = Err("the hand has been dealt")
+
+	// ErrNotShowdown means that a hand was asked for its winners before every street was dealt
+	ErrNotShowdown = Err("the hand hasn't reached the showdown")
+
+	// ErrHandSize means that a hand to evaluate didn't have between five and seven cards
+	ErrHandSize = Err("a hand must have between 5 and 7 cards")
+
+	// ErrNoHand means that no hand has been dealt
+	ErrNoHand = Err("no hand has been dealt")
+
+	// ErrNotAnAction means that input didn't start with the name of an action
+	ErrNotAnAction = Err("not an action")
+
+	// ErrBadAction means that an action was missing its amount, or had one it shouldn't
+	ErrBadAction = Err("actions are fold, check, call, all-in, bet {amount} or raise {amount}")
+
+	// ErrNoChips means that a player was dealt in without any chips
+	ErrNoChips = Err("every player needs chips to be dealt in")
+
+	// ErrNotYourTurn means that a seat acted out of turn
+	ErrNotYourTurn = Err("it isn't that player's turn")
+
+	// ErrBettingOpen means that the next street was dealt before the betting on this one was over
+	ErrBettingOpen = Err("the betting on this street isn't over")
+
+	// ErrBettingClosed means that someone acted once the betting was over
+	ErrBettingClosed = Err("the betting is over")
+
+	// ErrCannotCheck means that a player checked when they had a bet to call
+	ErrCannotCheck = Err("can't check when there is a bet to call")
+
+	// ErrNothingToCall means that a player called when there was no bet to call
+	ErrNothingToCall = Err("there is no bet to call")
+
+	// ErrWrongBet means that a player raised when there was no bet, or bet when there already was one
+	ErrWrongBet = Err("bet when no one has bet on this street, otherwise raise")
+
+	// ErrBetTooSmall means that a bet was less than the big blind or a raise less than the last raise
+	ErrBetTooSmall = Err("bets must be at least the big blind and raises at least the last raise")
+
+	// ErrNotEnoughChips means that a player bet more chips than they have
+	ErrNotEnoughChips = Err("not enough chips")
+
+	// ErrUnknownBlindStructure means that a blind structure was neither a preset nor a file
+	ErrUnknownBlindStructure = Err("unknown blind structure, use a preset or a json or yaml file")
+
+	// ErrBadBlindStructure means that a blind structure couldn't be read or its levels don't make sense
+	ErrBadBlindStructure = Err("blind structures need levels of blinds, each of which ends but the last")
+
+	// ErrClockStopped means that a blind clock was used after it was stopped
+	ErrClockStopped = Err("the blind clock has been stopped")
+
+	// ErrClockPaused means that a blind clock was paused when it already was
+	ErrClockPaused = Err("the blind clock is already paused")
+
+	// ErrClockRunning means that a blind clock was resumed when it wasn't paused
+	ErrClockRunning = Err("the blind clock isn't paused")
+
+	// ErrLastBlind means that a blind clock was skipped on when there were no more blinds to come
+	ErrLastBlind = Err("there are no more blinds to skip to")
+
+	// ErrUnknownBlindEvent means that a blind event's type wasn't one of the known ones
+	ErrUnknownBlindEvent = Err("unknown blind event")
+
+	// ErrUnknownRenderer means that alerts were asked for in a format there's no renderer for
+	ErrUnknownRenderer = Err("unknown alert format, use text or json")
+
+	// ErrUnknownGame means that there's no game with the ID asked for
+	ErrUnknownGame = Err("unknown game")
+
+	// ErrGameTaken means that a game was asked to be played when someone was already playing it
+	ErrGameTaken = Err("the game is already being played")
+
+	// ErrUnknownGameState means that a game's state wasn't one of the known ones
+	ErrUnknownGameState = Err("unknown game state")
+
+	// ErrNoGame means that a game wasn't named when it needed to be
+	ErrNoGame = Err("a game must be given with game={id}")
+
+	// ErrTournamentPlayers means that a tournament was started without the names of at least two players
+	ErrTournamentPlayers = Err("a tournament needs the names of at least two players")
+
+	// ErrNotTournament means that a tournament command was sent to a game that isn't a tournament
+	ErrNotTournament = Err("the game isn't a tournament")
+
+	// ErrUnknownEntrant means that no one by the name given entered the tournament
+	ErrUnknownEntrant = Err("no one by that name entered the tournament")
+
+	// ErrAlreadyEntered means that a player entered a tournament they'd already entered
+	ErrAlreadyEntered = Err("the player has already entered the tournament")
+
+	// ErrEntriesClosed means that a player entered a tournament after its rebuy period
+	ErrEntriesClosed = Err("the tournament has stopped taking entries")
+
+	// ErrEliminated means that a player who had been eliminated was eliminated again or took an add-on
+	ErrEliminated = Err("the player has been eliminated")
+
+	// ErrNotEliminated means that a player rebought while they were still in the tournament
+	ErrNotEliminated = Err("only players who have been eliminated can rebuy")
+
+	// ErrRebuysClosed means that a player rebought after the tournament's rebuy period
+	ErrRebuysClosed = Err("the rebuy period is over")
+
+	// ErrRebuyLimit means that a player rebought more times than the tournament allows
+	ErrRebuyLimit = Err("the player has used all of their rebuys")
+
+	// ErrAddOnClosed means that a player took an add-on outside of the tournament's add-on level
+	ErrAddOnClosed = Err("add-ons can only be taken during the add-on level")
+
+	// ErrAddOnTaken means that a player took a second add-on
+	ErrAddOnTaken = Err("the player has already taken their add-on")
+
+	// ErrTournamentOver means that a player was eliminated from a tournament that was already over
+	ErrTournamentOver = Err("the tournament is over")
+
+	// ErrTournamentNotOver means that a tournament was finished while more than one player was left in it
+	ErrTournamentNotOver = Err("the tournament has more than one player left")
+
+	// ErrWrongWinner means that a tournament was finished with a winner who wasn't the last player left
+	ErrWrongWinner = Err("the winner of a tournament is the last player left in it")
+
+	// ErrBadTournamentRules means that tournament rules weren't freeze-out or rebuys, max-rebuys and add-on settings
+	ErrBadTournamentRules = Err("tournaments are freeze-out or rebuys={levels},max-rebuys={number},add-on={level},table-size={players}")
+
+	// ErrTableSize means that a tournament's tables were to seat fewer than two players
+	ErrTableSize = Err("tables need to seat at least two players")
+
+	// ErrNotSeated means that a player who wasn't seated at any table was taken away from one
+	ErrNotSeated = Err("the player isn't seated at a table")
+
+	// ErrUnknownPayouts means that there's no preset payout structure with the name asked for
+	ErrUnknownPayouts = Err("unknown payout structure, use standard, winner-takes-all or a json or yaml file")
+
+	// ErrBadPayouts means that a payout structure's tiers didn't each pay out the whole prize pool
+	ErrBadPayouts = Err("payout structures need tiers of percentages adding up to 100, the last for any number of entrants")
+
+	// ErrBadPayoutRequest means that payouts were asked for without entrants, with negative counts or costs,
+	// or with more players chopping than entered
+	ErrBadPayoutRequest = Err("payouts need at least one entrant, no negative rebuys, add-ons or costs, and no more players chopping than entrants")
+
+	// ErrChopPlayers means that a chop was asked for between too few or too many players
+	ErrChopPlayers = Err("a chop needs between 2 and 10 players")
+
+	// ErrBadChop means that a player chopping the prize pool had no chips, or their stack wasn't a number
+	ErrBadChop = Err("everyone chopping needs a stack of chips, written as a comma separated list of numbers")
+
+	// ErrUnknownVariant means that a game was asked for as a variant of poker there isn't
+	ErrUnknownVariant = Err("unknown variant, use texas-holdem, omaha, pot-limit-omaha or seven-card-stud")
+
+	// ErrOverLimit means that a player bet or raised more than the betting structure allows, such as more
+	// than the pot in a pot-limit game or more than the fixed bet in a fixed-limit one
+	ErrOverLimit = Err("that's more than the betting limit allows")
+
+	// ErrRaiseCapped means that a player bet or raised on a fixed-limit street that's had as many raises as it allows
+	ErrRaiseCapped = Err("the betting is capped, so all you can do is call or fold")
+
+	// ErrBettingNotReopened means that a player who'd already acted raised when all that's been put in since is an
+	// all in for less than a full raise
+	ErrBettingNotReopened = Err("an all in for less than a full raise doesn't reopen the betting, so all you can do is call or fold")
+
+	// ErrBadBettingStructure means that a betting structure wasn't no-limit, pot-limit or fixed-limit with bet sizes and a raise cap
+	ErrBadBettingStructure = Err("betting structures are no-limit, pot-limit or fixed-limit,small-bet={chips},big-bet={chips},cap={raises}")
+
 	// ErrBadPlayerInput is an error for bad inputs
-	ErrBadPlayerInput = "Bad value received for number of players, please try again with a number"
+	ErrBadPlayerInput = "Bad value received for number of players, please try again with a number or their names"
 )
 
 // Err are errors that can happen when interacting with FSPS
@@ -0,0 +1,147 @@
+package poker
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileSystem is the set of file operations the stores need, so that failures can be injected
+type FileSystem interface {
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+}
+
+// File is an open file on a FileSystem
+type File interface {
+	io.ReadWriteCloser
+	Sync() error
+}
+
+// OSFileSystem is a FileSystem backed by the os package
+type OSFileSystem struct{}
+
+// OpenFile opens the named file with os.OpenFile
+func (OSFileSystem) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	f, err := os.OpenFile(name, flag, perm)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Rename renames the file with os.Rename
+func (OSFileSystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Remove removes the file with os.Remove
+func (OSFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// tempFileName is where writeFileAtomic stages the new contents of filename
+func tempFileName(filename string) string {
+	return filename + ".tmp"
+}
+
+// readFile returns the contents of filename (or nothing if it doesn't exist)
+func readFile(fsys FileSystem, filename string) ([]byte, error) {
+
+	file, err := fsys.OpenFile(filename, os.O_RDONLY, 0)
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, ErrFileOpen
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+
+	if err != nil {
+		return nil, ErrFileRead
+	}
+
+	return data, nil
+}
+
+// writeFileAtomic replaces filename with data so that a crash at any point
+// leaves either the old or the new contents on disk, never a mix of both
+func writeFileAtomic(fsys FileSystem, filename string, data []byte) error {
+
+	tmpName := tempFileName(filename)
+
+	tmp, err := fsys.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+
+	if err != nil {
+		return ErrFileOpen
+	}
+
+	_, err = tmp.Write(data)
+
+	if err != nil {
+		tmp.Close()
+		fsys.Remove(tmpName)
+		return ErrFileWrite
+	}
+
+	err = tmp.Sync()
+
+	if err != nil {
+		tmp.Close()
+		fsys.Remove(tmpName)
+		return ErrFileSync
+	}
+
+	err = tmp.Close()
+
+	if err != nil {
+		fsys.Remove(tmpName)
+		return ErrFileClose
+	}
+
+	err = fsys.Rename(tmpName, filename)
+
+	if err != nil {
+		fsys.Remove(tmpName)
+		return ErrFileRename
+	}
+
+	return syncDir(fsys, filepath.Dir(filename))
+}
+
+// syncDir flushes a directory so that a rename within it survives a crash
+func syncDir(fsys FileSystem, dir string) error {
+
+	d, err := fsys.OpenFile(dir, os.O_RDONLY, 0)
+
+	if err != nil {
+		return ErrDirSync
+	}
+	defer d.Close()
+
+	if d.Sync() != nil {
+		return ErrDirSync
+	}
+
+	return nil
+}
+
+// removeTempFile throws away anything left staged for filename by an interrupted writeFileAtomic
+func removeTempFile(fsys FileSystem, filename string) error {
+
+	err := fsys.Remove(tempFileName(filename))
+
+	if err != nil && !os.IsNotExist(err) {
+		return ErrRecover
+	}
+
+	return nil
+}
@@ -1,11 +1,118 @@
 package poker
 
 import (
+	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
-// Game interface is what starts and finishes games within the CLI
+// The commands players send to deal the next street of the hand and to control the blind clock
+const (
+	DealCommand   = "deal"
+	PauseCommand  = "pause"
+	ResumeCommand = "resume"
+	SkipCommand   = "skip"
+)
+
+// Game interface is what starts, deals, bets on and finishes games within the CLI. Games go through the
+// states of a Lifecycle, and are sent a StateError when asked to do something they can't in the state they're in.
 type Game interface {
-	Start(numberOfPlayers int, alertsDestination io.Writer)
-	Finish(winner string)
+	Start(numberOfPlayers int, alertsDestination io.Writer, players ...string) error
+	Act(action Action) error
+	NextStreet() error
+	Pause() error
+	Resume() error
+	BlindClock() *BlindClock
+	Alerts() *Broadcast
+	Tournament() *Tournament
+	Winners() []string
+	State() GameState
+	Finish(winner string) error
+	Abort() error
+}
+
+// ParsePlayers reads who is playing from either a number of players or a comma separated list
+// of at least two names
+func ParsePlayers(input string) (int, []string, error) {
+
+	input = strings.TrimSpace(input)
+
+	numberOfPlayers, err := strconv.Atoi(input)
+
+	if err == nil {
+		return numberOfPlayers, nil, nil
+	}
+
+	var players []string
+
+	for _, name := range strings.Split(input, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return 0, nil, ErrBadPlayers
+		}
+		players = append(players, name)
+	}
+
+	if len(players) < 2 {
+		return 0, nil, ErrBadPlayers
+	}
+
+	return len(players), players, nil
+}
+
+// playTurn deals the next street, pauses or resumes the game, skips to the next blind, runs the tournament
+// and its tables or takes a betting action from the input of a CLI or websocket, writing what went wrong to out
+// if it couldn't be done. It reports false if the input was none of them, so names the winner.
+func playTurn(game Game, out io.Writer, input string) bool {
+
+	var err error
+
+	command, name, isTournament := parseTournamentCommand(game.Tournament(), input)
+
+	switch {
+	case isTournament:
+		err = runTournamentCommand(game.Tournament(), command, name)
+		if err == nil {
+			announceSeating(game.Tournament(), out)
+		}
+	case input == TablesCommand:
+		err = printTables(game.Tournament(), out)
+	case input == DealCommand:
+		err = game.NextStreet()
+	case input == PauseCommand:
+		err = game.Pause()
+	case input == ResumeCommand:
+		err = game.Resume()
+	case input == SkipCommand:
+		err = skipBlind(game.BlindClock())
+	default:
+		action, parseErr := ParseAction(input)
+
+		if parseErr == ErrNotAnAction {
+			return false
+		}
+
+		err = parseErr
+
+		if err == nil {
+			err = game.Act(action)
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(out, err)
+	}
+
+	return true
+}
+
+// skipBlind skips clock on to the next blind
+func skipBlind(clock *BlindClock) error {
+
+	if clock == nil {
+		return ErrNoHand
+	}
+
+	return clock.Skip()
 }
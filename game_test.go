@@ -4,6 +4,7 @@ import (
 	"github.com/vetch101/go-tddapp"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -11,7 +12,7 @@ import (
 
 func TestGame(t *testing.T) {
 	t.Run("GET /game returns 200", func(t *testing.T) {
-		server, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, dummyGame)
+		server, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, managerFor(dummyGame))
 
 		request := newGameRequest()
 		response := httptest.NewRecorder()
@@ -23,8 +24,8 @@ func TestGame(t *testing.T) {
 	t.Run("when we get a message over a websocket it is a winner", func(t *testing.T) {
 		store := &poker.StubPlayerStore{}
 		winner := "Ruth"
-		game := dummyGame
-		playerServer := mustMakePlayerServer(t, store, dummyGame)
+		game := &GameSpy{}
+		playerServer := mustMakePlayerServer(t, store, game)
 		server := httptest.NewServer(playerServer)
 		defer server.Close()
 
@@ -61,4 +62,100 @@ func TestGame(t *testing.T) {
 			timeout := (time.Duration(10) * time.Millisecond)
 			within(t, timeout, func() { assertWebsocketGotMsg(t, ws, wantedBlindAlert) })
 		})
+	t.Run("deal messages deal the next street before the winner", func(t *testing.T) {
+		game := &GameSpy{}
+		server := httptest.NewServer(mustMakePlayerServer(t, &poker.StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		writeWSMessage(t, ws, "3")
+		writeWSMessage(t, ws, poker.DealCommand)
+		writeWSMessage(t, ws, "Ruth")
+
+		assertFinishCalledWith(t, game, "Ruth")
+
+		if dealt := game.dealt(); dealt != 1 {
+			t.Errorf("got %d streets dealt want 1", dealt)
+		}
+	})
+	t.Run("watchers are sent the blinds until they leave", func(t *testing.T) {
+		broadcast := poker.NewBroadcast()
+		game := &GameSpy{Broadcast: broadcast}
+		server := httptest.NewServer(mustMakePlayerServer(t, &poker.StubPlayerStore{}, game))
+		defer server.Close()
+
+		created := createGame(t, server.URL)
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/watch?game="+created.ID)
+
+		if !retryUntil(time.Second, func() bool { return broadcast.Subscribers() == 1 }) {
+			t.Fatal("the watcher never subscribed")
+		}
+
+		broadcast.Alert(poker.BlindEvent{Type: poker.LevelStarted, Level: 2, BigBlind: 200})
+
+		within(t, time.Second, func() {
+			assertWebsocketGotMsg(t, ws, `{"blind":{"type":"level","level":2,"big_blind":200}}`)
+		})
+
+		ws.Close()
+
+		if !retryUntil(time.Second, func() bool { return broadcast.Subscribers() == 0 }) {
+			t.Error("the watcher should have been unsubscribed when it left")
+		}
+	})
+	t.Run("betting messages act for the player whose turn it is", func(t *testing.T) {
+		game := &GameSpy{}
+		server := httptest.NewServer(mustMakePlayerServer(t, &poker.StubPlayerStore{}, game))
+		defer server.Close()
+
+		ws := mustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer ws.Close()
+
+		writeWSMessage(t, ws, "3")
+		writeWSMessage(t, ws, "raise 400")
+		writeWSMessage(t, ws, "Ruth")
+
+		assertFinishCalledWith(t, game, "Ruth")
+
+		game.mu.Lock()
+		defer game.mu.Unlock()
+
+		if want := []poker.Action{{Type: poker.Raise, Amount: 400}}; !reflect.DeepEqual(game.Actions, want) {
+			t.Errorf("got actions %v want %v", game.Actions, want)
+		}
+	})
+}
+
+func TestParsePlayers(t *testing.T) {
+
+	cases := []struct {
+		input       string
+		wantNumber  int
+		wantPlayers []string
+		wantErr     error
+	}{
+		{"7", 7, nil, nil},
+		{" 3\n", 3, nil, nil},
+		{"Chris,Cleo", 2, []string{"Chris", "Cleo"}, nil},
+		{" Chris , Cleo, Bob ", 3, []string{"Chris", "Cleo", "Bob"}, nil},
+		{"blah", 0, nil, poker.ErrBadPlayers},
+		{"Chris,,Cleo", 0, nil, poker.ErrBadPlayers},
+		{"", 0, nil, poker.ErrBadPlayers},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			number, players, err := poker.ParsePlayers(c.input)
+
+			if err != c.wantErr {
+				t.Fatalf("got error %v want %v", err, c.wantErr)
+			}
+
+			if number != c.wantNumber || !reflect.DeepEqual(players, c.wantPlayers) {
+				t.Errorf("got %d players %v want %d players %v", number, players, c.wantNumber, c.wantPlayers)
+			}
+		})
+	}
 }
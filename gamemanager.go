@@ -0,0 +1,377 @@
+package poker
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultGameTimeout is how long a game can go without being played before it's abandoned,
+// and how long a game that's over is kept for before it's forgotten
+const DefaultGameTimeout = 30 * time.Minute
+
+// GameInfo is what a GameManager knows about one of its games
+type GameInfo struct {
+	ID              string
+	State           GameState
+	NumberOfPlayers int
+	Players         []string
+	Variant         string `json:",omitempty"`
+	Winner          string `json:",omitempty"`
+	Created         time.Time
+	LastPlayed      time.Time
+}
+
+// GameManager creates games, each under its own ID, and keeps track of them while they're played,
+// so that any number of tables can play at once. Games no one plays for the timeout are abandoned,
+// and games that have been over for as long are forgotten.
+type GameManager struct {
+	mu         sync.Mutex
+	newGame    func() Game
+	newVariant func(variant string) (Game, error)
+	clock      Clock
+	timeout    time.Duration
+	games      map[string]*managedGame
+}
+
+// NewGameManager returns a GameManager that creates its games with newGame
+func NewGameManager(newGame func() Game) *GameManager {
+	return &GameManager{
+		newGame: newGame,
+		clock:   SystemClock,
+		timeout: DefaultGameTimeout,
+		games:   map[string]*managedGame{},
+	}
+}
+
+// SetClock sets the clock that the manager times games out by
+func (m *GameManager) SetClock(clock Clock) {
+	m.clock = clock
+}
+
+// SetTimeout sets how long games can go without being played, and are kept for once they're over
+func (m *GameManager) SetTimeout(timeout time.Duration) {
+	m.timeout = timeout
+}
+
+// SetVariants lets games be created as other variants of poker, which newVariant creates by name
+func (m *GameManager) SetVariants(newVariant func(variant string) (Game, error)) {
+	m.newVariant = newVariant
+}
+
+// Create creates a new game that's waiting for someone to play it
+func (m *GameManager) Create() GameInfo {
+	return m.add(m.newGame(), "")
+}
+
+// CreateVariant creates a new game of the variant called name that's waiting for someone to play it,
+// or the usual game if name is empty
+func (m *GameManager) CreateVariant(name string) (GameInfo, error) {
+
+	if name == "" {
+		return m.Create(), nil
+	}
+
+	if m.newVariant == nil {
+		return GameInfo{}, ErrUnknownVariant
+	}
+
+	game, err := m.newVariant(name)
+
+	if err != nil {
+		return GameInfo{}, err
+	}
+
+	return m.add(game, name), nil
+}
+
+// add starts keeping track of game, a new game of variant
+func (m *GameManager) add(newGame Game, variant string) GameInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+
+	game := &managedGame{
+		Game:    newGame,
+		manager: m,
+		info:    GameInfo{ID: NewGameID(), Variant: variant, Created: now, LastPlayed: now},
+	}
+
+	m.games[game.info.ID] = game
+
+	return game.current()
+}
+
+// Games returns what's known about every game, oldest first
+func (m *GameManager) Games() []GameInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	games := make([]GameInfo, 0, len(m.games))
+
+	for _, game := range m.games {
+		games = append(games, game.current())
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Created.Equal(games[j].Created) {
+			return games[i].ID < games[j].ID
+		}
+		return games[i].Created.Before(games[j].Created)
+	})
+
+	return games
+}
+
+// Info returns what's known about the game with id
+func (m *GameManager) Info(id string) (GameInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, ok := m.games[id]
+
+	if !ok {
+		return GameInfo{}, ErrUnknownGame
+	}
+
+	return game.current(), nil
+}
+
+// Play hands the game with id to whoever is going to play it, which only one player can do. Starting,
+// playing and finishing the Game it returns is tracked in the game's GameInfo.
+func (m *GameManager) Play(id string) (Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	game, ok := m.games[id]
+
+	if !ok {
+		return nil, ErrUnknownGame
+	}
+
+	if game.taken || game.State() != GameCreated {
+		return nil, ErrGameTaken
+	}
+
+	game.taken = true
+	game.info.LastPlayed = m.clock.Now()
+
+	return game, nil
+}
+
+// Alerts is where the blinds of the game with id are broadcast
+func (m *GameManager) Alerts(id string) (*Broadcast, error) {
+	m.mu.Lock()
+	game, ok := m.games[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return nil, ErrUnknownGame
+	}
+
+	return game.Alerts(), nil
+}
+
+// Abandon aborts the game with id, such as when its player goes away before it's finished.
+// Games that are already over are left as they are.
+func (m *GameManager) Abandon(id string) error {
+	m.mu.Lock()
+	game, ok := m.games[id]
+
+	if ok {
+		game.info.LastPlayed = m.clock.Now()
+	}
+
+	m.mu.Unlock()
+
+	if !ok {
+		return ErrUnknownGame
+	}
+
+	if !game.State().Over() {
+		game.Abort()
+	}
+
+	return nil
+}
+
+// Cleanup abandons games that haven't been played for the timeout and forgets games that have been over for as long
+func (m *GameManager) Cleanup() {
+	m.mu.Lock()
+
+	now := m.clock.Now()
+	var abandoned []*managedGame
+
+	for id, game := range m.games {
+		if now.Sub(game.info.LastPlayed) < m.timeout {
+			continue
+		}
+
+		if game.State().Over() {
+			delete(m.games, id)
+			continue
+		}
+
+		game.info.LastPlayed = now
+		abandoned = append(abandoned, game)
+	}
+
+	// aborting a game waits for its player to finish their turn, which mustn't hold up the other games
+	m.mu.Unlock()
+
+	for _, game := range abandoned {
+		game.Abort()
+	}
+}
+
+// CleanupEvery cleans up the manager's games every interval until the returned func is called
+func (m *GameManager) CleanupEvery(interval time.Duration) func() {
+
+	var mu sync.Mutex
+	var timer Timer
+	stopped := false
+
+	var cleanup func()
+	cleanup = func() {
+		m.Cleanup()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			timer = m.clock.AfterFunc(interval, cleanup)
+		}
+	}
+
+	mu.Lock()
+	timer = m.clock.AfterFunc(interval, cleanup)
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		timer.Stop()
+	}
+}
+
+// managedGame is a Game that keeps its GameManager up to date with how it's being played. Its player and
+// the manager abandoning it can get to it at once, so it's only used by one of them at a time.
+type managedGame struct {
+	Game
+	mu      sync.Mutex
+	manager *GameManager
+	info    GameInfo
+	taken   bool
+}
+
+// current is what's known about the game, in the state it's in now
+func (g *managedGame) current() GameInfo {
+	info := g.info
+	info.State = g.State()
+	return info
+}
+
+// played records that the game was just played, letting update change the rest of what's known about it
+func (g *managedGame) played(update func(info *GameInfo)) {
+	g.manager.mu.Lock()
+	defer g.manager.mu.Unlock()
+
+	g.info.LastPlayed = g.manager.clock.Now()
+	update(&g.info)
+}
+
+func (g *managedGame) Start(numberOfPlayers int, alertsDestination io.Writer, players ...string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	err := g.Game.Start(numberOfPlayers, alertsDestination, players...)
+
+	if err == nil {
+		g.played(func(info *GameInfo) {
+			info.NumberOfPlayers = numberOfPlayers
+			info.Players = players
+		})
+	}
+
+	return err
+}
+
+func (g *managedGame) Act(action Action) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.played(func(*GameInfo) {})
+	return g.Game.Act(action)
+}
+
+func (g *managedGame) NextStreet() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.played(func(*GameInfo) {})
+	return g.Game.NextStreet()
+}
+
+func (g *managedGame) Pause() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.played(func(*GameInfo) {})
+	return g.Game.Pause()
+}
+
+func (g *managedGame) Resume() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.played(func(*GameInfo) {})
+	return g.Game.Resume()
+}
+
+func (g *managedGame) BlindClock() *BlindClock {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.Game.BlindClock()
+}
+
+func (g *managedGame) Tournament() *Tournament {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.Game.Tournament()
+}
+
+func (g *managedGame) Winners() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.Game.Winners()
+}
+
+func (g *managedGame) Finish(winner string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	err := g.Game.Finish(winner)
+
+	if err == nil {
+		g.played(func(info *GameInfo) {
+			info.Winner = winner
+		})
+	}
+
+	return err
+}
+
+func (g *managedGame) Abort() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	err := g.Game.Abort()
+
+	if err == nil {
+		g.played(func(*GameInfo) {})
+	}
+
+	return err
+}
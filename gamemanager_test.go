@@ -0,0 +1,477 @@
+package poker_test
+
+import (
+	"encoding/json"
+	websocket "github.com/gorilla/websocket"
+	"github.com/vetch101/go-tddapp"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameManager(t *testing.T) {
+
+	t.Run("creates games under their own IDs, listed oldest first", func(t *testing.T) {
+		clock := poker.NewFakeClock(time.Now())
+		games := managerFor(&GameSpy{})
+		games.SetClock(clock)
+
+		first := games.Create()
+		clock.Advance(time.Second)
+		second := games.Create()
+
+		if first.ID == second.ID {
+			t.Fatalf("both games were given the ID %s", first.ID)
+		}
+
+		if first.State != poker.GameCreated {
+			t.Errorf("got state %v want %v", first.State, poker.GameCreated)
+		}
+
+		if got := games.Games(); !reflect.DeepEqual(got, []poker.GameInfo{first, second}) {
+			t.Errorf("got %+v want %+v", got, []poker.GameInfo{first, second})
+		}
+	})
+
+	t.Run("only one player can play a game", func(t *testing.T) {
+		games := managerFor(&GameSpy{})
+		id := games.Create().ID
+
+		_, err := games.Play(id)
+		poker.AssertNoError(t, err)
+
+		if _, err := games.Play(id); err != poker.ErrGameTaken {
+			t.Errorf("got error %v want %v", err, poker.ErrGameTaken)
+		}
+
+		if _, err := games.Play("no-such-game"); err != poker.ErrUnknownGame {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownGame)
+		}
+
+		if _, err := games.Info("no-such-game"); err != poker.ErrUnknownGame {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownGame)
+		}
+	})
+
+	t.Run("keeps track of the game as it's played", func(t *testing.T) {
+		spy := &GameSpy{}
+		games := managerFor(spy)
+		id := games.Create().ID
+
+		game, _ := games.Play(id)
+		game.Start(2, ioutil.Discard, "Ruth", "Chris")
+
+		assertGameState(t, games, id, poker.GameRunning)
+		game.Finish("Ruth")
+
+		info, _ := games.Info(id)
+
+		if info.State != poker.GameFinished || info.Winner != "Ruth" || info.NumberOfPlayers != 2 {
+			t.Errorf("got %+v want a finished game of 2 won by Ruth", info)
+		}
+
+		if !reflect.DeepEqual(info.Players, []string{"Ruth", "Chris"}) {
+			t.Errorf("got players %v want [Ruth Chris]", info.Players)
+		}
+
+		assertFinishCalledWith(t, spy, "Ruth")
+	})
+
+	t.Run("abandoning a game stops its blinds", func(t *testing.T) {
+		blindClock := poker.NewBlindClock(poker.NewFakeClock(time.Now()))
+		games := managerFor(&GameSpy{Clock: blindClock})
+		id := games.Create().ID
+
+		poker.AssertNoError(t, games.Abandon(id))
+		assertGameState(t, games, id, poker.GameAborted)
+
+		if err := blindClock.Resume(); err != poker.ErrClockStopped {
+			t.Errorf("got error %v want the blind clock to have been stopped", err)
+		}
+	})
+
+	t.Run("abandons games no one plays and forgets games that are over", func(t *testing.T) {
+		clock := poker.NewFakeClock(time.Now())
+		games := poker.NewGameManager(func() poker.Game { return &GameSpy{} })
+		games.SetClock(clock)
+		games.SetTimeout(10 * time.Minute)
+
+		idle := games.Create().ID
+		finished := games.Create().ID
+
+		game, _ := games.Play(finished)
+		game.Start(2, ioutil.Discard)
+		game.Finish("Ruth")
+
+		clock.Advance(10 * time.Minute)
+		games.Cleanup()
+
+		assertGameState(t, games, idle, poker.GameAborted)
+
+		if _, err := games.Info(finished); err != poker.ErrUnknownGame {
+			t.Errorf("got error %v want the finished game to have been forgotten", err)
+		}
+
+		clock.Advance(10 * time.Minute)
+		games.Cleanup()
+
+		if got := games.Games(); len(got) != 0 {
+			t.Errorf("got %+v want no games left", got)
+		}
+	})
+
+	t.Run("cleans up every interval until it's stopped", func(t *testing.T) {
+		clock := poker.NewFakeClock(time.Now())
+		games := managerFor(&GameSpy{})
+		games.SetClock(clock)
+		games.SetTimeout(time.Minute)
+
+		id := games.Create().ID
+
+		stop := games.CleanupEvery(time.Minute)
+
+		clock.Advance(time.Minute)
+		assertGameState(t, games, id, poker.GameAborted)
+
+		stop()
+		clock.Advance(5 * time.Minute)
+		assertGameState(t, games, id, poker.GameAborted)
+
+		if clock.Timers() != 0 {
+			t.Errorf("got %d timers want none once cleaning up is stopped", clock.Timers())
+		}
+	})
+
+	t.Run("pausing and resuming a game counts as playing it", func(t *testing.T) {
+		clock := poker.NewFakeClock(time.Now())
+		games := managerFor(&GameSpy{})
+		games.SetClock(clock)
+		id := games.Create().ID
+
+		game, _ := games.Play(id)
+		game.Start(2, ioutil.Discard)
+
+		for _, play := range []func() error{game.Pause, game.Resume, game.Abort} {
+			clock.Advance(time.Minute)
+			poker.AssertNoError(t, play())
+
+			if info, _ := games.Info(id); !info.LastPlayed.Equal(clock.Now()) {
+				t.Errorf("got the game last played at %v want %v", info.LastPlayed, clock.Now())
+			}
+		}
+	})
+
+	t.Run("a game can be abandoned while it's being played", func(t *testing.T) {
+		games := poker.NewGameManager(func() poker.Game {
+			return poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		})
+
+		for i := 0; i < 20; i++ {
+			id := games.Create().ID
+			game, _ := games.Play(id)
+
+			done := make(chan struct{})
+
+			go func() {
+				games.Abandon(id)
+				close(done)
+			}()
+
+			if game.Start(3, ioutil.Discard) == nil {
+				for game.Act(poker.Action{Type: poker.Call}) == nil {
+				}
+			}
+
+			<-done
+			poker.AssertNoError(t, games.Abandon(id))
+			assertGameState(t, games, id, poker.GameAborted)
+		}
+	})
+}
+
+func TestGames(t *testing.T) {
+
+	t.Run("POST /games creates a game that GET /games lists", func(t *testing.T) {
+		server := mustMakePlayerServer(t, &poker.StubPlayerStore{}, &GameSpy{})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGamesRequest(http.MethodPost, "/games"))
+
+		poker.AssertStatus(t, response.Code, http.StatusCreated)
+		poker.AssertContentType(t, response.Header().Get("content-type"), jsonContentType)
+
+		var created poker.GameInfo
+		poker.AssertNoError(t, json.NewDecoder(response.Body).Decode(&created))
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGamesRequest(http.MethodGet, "/games"))
+
+		var listed []poker.GameInfo
+		poker.AssertNoError(t, json.NewDecoder(response.Body).Decode(&listed))
+
+		if len(listed) != 1 || listed[0].ID != created.ID || listed[0].State != poker.GameCreated {
+			t.Errorf("got %+v want just the created game %s", listed, created.ID)
+		}
+	})
+
+	t.Run("GET /games/{id} returns the game, or 404 if there isn't one", func(t *testing.T) {
+		games := managerFor(&GameSpy{})
+		server, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+		id := games.Create().ID
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGamesRequest(http.MethodGet, "/games/"+id))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+
+		var got poker.GameInfo
+		poker.AssertNoError(t, json.NewDecoder(response.Body).Decode(&got))
+
+		if got.ID != id {
+			t.Errorf("got game %s want %s", got.ID, id)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGamesRequest(http.MethodGet, "/games/no-such-game"))
+
+		poker.AssertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("POST /games?variant= creates a game of that variant, or 400 if there's no such variant", func(t *testing.T) {
+		games := managerFor(&GameSpy{})
+		games.SetVariants(func(name string) (poker.Game, error) {
+			if _, err := poker.VariantFor(name); err != nil {
+				return nil, err
+			}
+			return &GameSpy{}, nil
+		})
+		server, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGamesRequest(http.MethodPost, "/games?variant="+poker.OmahaName))
+
+		poker.AssertStatus(t, response.Code, http.StatusCreated)
+
+		var created poker.GameInfo
+		poker.AssertNoError(t, json.NewDecoder(response.Body).Decode(&created))
+
+		if created.Variant != poker.OmahaName {
+			t.Errorf("got %+v want a game of %s", created, poker.OmahaName)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGamesRequest(http.MethodPost, "/games?variant=razz"))
+
+		poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("only games that can be played as variants are", func(t *testing.T) {
+		games := managerFor(&GameSpy{})
+
+		if _, err := games.CreateVariant(poker.OmahaName); err != poker.ErrUnknownVariant {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownVariant)
+		}
+	})
+
+	t.Run("games are played at once over their own websockets", func(t *testing.T) {
+		spies := []*GameSpy{{}, {}}
+		next := 0
+		games := poker.NewGameManager(func() poker.Game {
+			spy := spies[next]
+			next++
+			return spy
+		})
+		playerServer, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		first := mustDialWS(t, wsURL(server, "/ws?game="+games.Create().ID))
+		defer first.Close()
+		second := mustDialWS(t, wsURL(server, "/ws?game="+games.Create().ID))
+		defer second.Close()
+
+		writeWSMessage(t, first, "3")
+		writeWSMessage(t, second, "5")
+		writeWSMessage(t, second, "Chris")
+		writeWSMessage(t, first, "Ruth")
+
+		assertGameStartedWith(t, spies[0], 3)
+		assertGameStartedWith(t, spies[1], 5)
+		assertFinishCalledWith(t, spies[0], "Ruth")
+		assertFinishCalledWith(t, spies[1], "Chris")
+	})
+
+	t.Run("a game someone is playing can't be joined, nor one that doesn't exist", func(t *testing.T) {
+		games := managerFor(&GameSpy{})
+		playerServer, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		id := games.Create().ID
+		ws := mustDialWS(t, wsURL(server, "/ws?game="+id))
+		defer ws.Close()
+
+		assertDialStatus(t, wsURL(server, "/ws?game="+id), http.StatusConflict)
+		assertDialStatus(t, wsURL(server, "/ws?game=no-such-game"), http.StatusNotFound)
+		assertDialStatus(t, wsURL(server, "/watch"), http.StatusBadRequest)
+	})
+
+	t.Run("a game that can't be started is abandoned", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetStartingChips(0)
+		games := managerFor(game)
+		playerServer, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		id := games.Create().ID
+		ws := mustDialWS(t, wsURL(server, "/ws?game="+id))
+		defer ws.Close()
+
+		writeWSMessage(t, ws, "3")
+		within(t, time.Second, func() { assertWebsocketGotMsg(t, ws, poker.ErrNoChips.Error()+"\n") })
+
+		abandoned := retryUntil(time.Second, func() bool {
+			info, _ := games.Info(id)
+			return info.State == poker.GameAborted
+		})
+
+		if !abandoned {
+			t.Error("the game should have been abandoned when it couldn't be started")
+		}
+	})
+
+	t.Run("a game whose connection can't be upgraded is abandoned", func(t *testing.T) {
+		games := managerFor(&GameSpy{})
+		playerServer, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		id := games.Create().ID
+		response, err := http.Get(server.URL + "/ws?game=" + id)
+		poker.AssertNoError(t, err)
+		response.Body.Close()
+
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("got status %d want %d", response.StatusCode, http.StatusBadRequest)
+		}
+
+		abandoned := retryUntil(time.Second, func() bool {
+			info, _ := games.Info(id)
+			return info.State == poker.GameAborted
+		})
+
+		if !abandoned {
+			t.Error("the game should have been abandoned when its connection couldn't be upgraded")
+		}
+	})
+
+	t.Run("a game sent players it can't read is abandoned", func(t *testing.T) {
+		spy := &GameSpy{}
+		games := managerFor(spy)
+		playerServer, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		id := games.Create().ID
+		ws := mustDialWS(t, wsURL(server, "/ws?game="+id))
+		defer ws.Close()
+
+		writeWSMessage(t, ws, "Ruth")
+		within(t, time.Second, func() { assertWebsocketGotMsg(t, ws, poker.ErrBadPlayers.Error()+"\n") })
+
+		abandoned := retryUntil(time.Second, func() bool {
+			info, _ := games.Info(id)
+			return info.State == poker.GameAborted
+		})
+
+		if !abandoned {
+			t.Error("the game should have been abandoned when its players couldn't be read")
+		}
+
+		if started, _ := spy.started(); started {
+			t.Error("the game shouldn't have been started")
+		}
+	})
+
+	t.Run("leaving a game before it's finished abandons it", func(t *testing.T) {
+		spy := &GameSpy{}
+		games := managerFor(spy)
+		playerServer, _ := poker.NewPlayerServer(&poker.StubPlayerStore{}, games)
+		server := httptest.NewServer(playerServer)
+		defer server.Close()
+
+		id := games.Create().ID
+		ws := mustDialWS(t, wsURL(server, "/ws?game="+id))
+		writeWSMessage(t, ws, "3")
+		assertGameStartedWith(t, spy, 3)
+		ws.Close()
+
+		abandoned := retryUntil(time.Second, func() bool {
+			info, _ := games.Info(id)
+			return info.State == poker.GameAborted
+		})
+
+		if !abandoned {
+			t.Error("the game should have been abandoned when its player left")
+		}
+
+		if finished, _ := spy.finished(); finished {
+			t.Error("an abandoned game shouldn't be finished")
+		}
+	})
+}
+
+func newGamesRequest(method, path string) *http.Request {
+	request, _ := http.NewRequest(method, path, nil)
+	return request
+}
+
+func wsURL(server *httptest.Server, path string) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + path
+}
+
+// createGame creates a game with POST /games on the server at url
+func createGame(t *testing.T, url string) poker.GameInfo {
+	t.Helper()
+
+	response, err := http.Post(url+"/games", "application/json", nil)
+
+	if err != nil {
+		t.Fatalf("could not create a game %v", err)
+	}
+	defer response.Body.Close()
+
+	var info poker.GameInfo
+	poker.AssertNoError(t, json.NewDecoder(response.Body).Decode(&info))
+
+	return info
+}
+
+func assertGameState(t *testing.T, games *poker.GameManager, id string, want poker.GameState) {
+	t.Helper()
+
+	info, err := games.Info(id)
+	poker.AssertNoError(t, err)
+
+	if info.State != want {
+		t.Errorf("got state %v want %v", info.State, want)
+	}
+}
+
+func assertDialStatus(t *testing.T, url string, want int) {
+	t.Helper()
+
+	ws, response, err := websocket.DefaultDialer.Dial(url, nil)
+
+	if err == nil {
+		ws.Close()
+		t.Fatalf("dialled %s when it should have been refused", url)
+	}
+
+	poker.AssertStatus(t, response.StatusCode, want)
+}
@@ -0,0 +1,142 @@
+package poker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+)
+
+// GameRecord is the history of a single finished game
+type GameRecord struct {
+	ID              string
+	Started         time.Time
+	Finished        time.Time
+	NumberOfPlayers int
+	Players         []string
+	Winner          string
+	BlindLevel      int
+	Blind           int
+	League          string     `json:",omitempty"`
+	Season          string     `json:",omitempty"`
+	Standings       []Standing `json:",omitempty"`
+	PrizePool       int        `json:",omitempty"`
+	Payouts         []Payout   `json:",omitempty"`
+	Variant         string     `json:",omitempty"`
+}
+
+// GameQuery picks out GameRecords. Zero fields match every game.
+type GameQuery struct {
+	Player string
+	From   time.Time
+	To     time.Time
+}
+
+// Duration is how long the game lasted
+func (g GameRecord) Duration() time.Duration {
+	return g.Finished.Sub(g.Started)
+}
+
+// Matches reports whether the game was played by the query's player and started within its dates
+func (g GameRecord) Matches(q GameQuery) bool {
+
+	if q.Player != "" && !g.playedBy(q.Player) {
+		return false
+	}
+
+	if !q.From.IsZero() && g.Started.Before(q.From) {
+		return false
+	}
+
+	if !q.To.IsZero() && g.Started.After(q.To) {
+		return false
+	}
+
+	return true
+}
+
+func (g GameRecord) playedBy(name string) bool {
+
+	if g.Winner == name {
+		return true
+	}
+
+	for _, player := range g.Players {
+		if player == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// score is what player earned against opponent, winning against everyone they finished ahead of in a
+// tournament, and otherwise the winner beating everyone else and everyone else drawing
+func (g GameRecord) score(player, opponent string) float64 {
+
+	if len(g.Standings) == 0 {
+		return score(player, opponent, g.Winner)
+	}
+
+	playerPosition, opponentPosition := g.position(player), g.position(opponent)
+
+	switch {
+	case playerPosition < opponentPosition:
+		return 1
+	case playerPosition > opponentPosition:
+		return 0
+	}
+	return 0.5
+}
+
+// position is where name finished in a tournament, with anyone missing from its standings coming last
+func (g GameRecord) position(name string) int {
+	for _, standing := range g.Standings {
+		if standing.Name == name && standing.Position > 0 {
+			return standing.Position
+		}
+	}
+	return len(g.Standings) + 1
+}
+
+// participants is everyone who played, once each, including the winner even if Players doesn't list them.
+// A game finished without naming its winner has no one nameless playing in it.
+func (g GameRecord) participants() []string {
+
+	var names []string
+	seen := map[string]bool{}
+
+	for _, name := range append(g.Players[:len(g.Players):len(g.Players)], g.Winner) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// filterGames returns the games matching q in the order they started
+func filterGames(games []GameRecord, q GameQuery) []GameRecord {
+
+	matched := []GameRecord{}
+
+	for _, game := range games {
+		if game.Matches(q) {
+			matched = append(matched, game)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Started.Before(matched[j].Started)
+	})
+
+	return matched
+}
+
+// NewGameID returns a random id for a GameRecord
+func NewGameID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
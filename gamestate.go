@@ -0,0 +1,183 @@
+package poker
+
+import (
+	"fmt"
+	"sync"
+)
+
+// GameState is how far through being played a game is
+type GameState int
+
+// The states a game goes through. A game is created, runs, maybe pauses and resumes, and is either
+// finished with a winner or aborted without one.
+const (
+	GameCreated GameState = iota
+	GameRunning
+	GamePaused
+	GameFinished
+	GameAborted
+)
+
+var gameStateNames = []string{"created", "running", "paused", "finished", "aborted"}
+
+func (s GameState) String() string {
+	if s < GameCreated || s > GameAborted {
+		return "unknown"
+	}
+	return gameStateNames[s]
+}
+
+// MarshalText writes the GameState as its name, such as "running"
+func (s GameState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads the GameState from its name
+func (s *GameState) UnmarshalText(text []byte) error {
+	for i, name := range gameStateNames {
+		if string(text) == name {
+			*s = GameState(i)
+			return nil
+		}
+	}
+	return ErrUnknownGameState
+}
+
+// Over reports whether the game has been finished or aborted
+func (s GameState) Over() bool {
+	return s == GameFinished || s == GameAborted
+}
+
+// StateError means that a game was asked to do something it can't in the state it's in,
+// such as being finished before it was started
+type StateError struct {
+	State  GameState
+	Action string
+}
+
+func (e StateError) Error() string {
+	return fmt.Sprintf("a %s game can't be %s", e.State, e.Action)
+}
+
+// gameMoves are the states a game can move to from each state. A game that's over can be started
+// again, as a table plays one game after another.
+var gameMoves = map[GameState][]GameState{
+	GameCreated:  {GameRunning, GameAborted},
+	GameRunning:  {GamePaused, GameFinished, GameAborted},
+	GamePaused:   {GameRunning, GameFinished, GameAborted},
+	GameFinished: {GameRunning},
+	GameAborted:  {GameRunning},
+}
+
+// Lifecycle keeps track of the state of a game, only letting it move between states the way games are played.
+// The zero Lifecycle is a created game.
+type Lifecycle struct {
+	mu    sync.Mutex
+	state GameState
+}
+
+// State is the state the game is in
+func (l *Lifecycle) State() GameState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+// Start starts the game running, as long as it isn't already
+func (l *Lifecycle) Start() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.canStart(); err != nil {
+		return err
+	}
+
+	return l.move(GameRunning, "started")
+}
+
+// CanStart returns the StateError that starting the game would, without starting it
+func (l *Lifecycle) CanStart() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.canStart()
+}
+
+func (l *Lifecycle) canStart() error {
+	if l.state == GamePaused {
+		return StateError{l.state, "started"}
+	}
+	return l.can(GameRunning, "started")
+}
+
+// Pause pauses the running game
+func (l *Lifecycle) Pause() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.move(GamePaused, "paused")
+}
+
+// Resume starts the paused game running again
+func (l *Lifecycle) Resume() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.state != GamePaused {
+		return StateError{l.state, "resumed"}
+	}
+
+	return l.move(GameRunning, "resumed")
+}
+
+// Finish finishes the game once it's been started
+func (l *Lifecycle) Finish() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.move(GameFinished, "finished")
+}
+
+// CanFinish returns the StateError that finishing the game would, without finishing it
+func (l *Lifecycle) CanFinish() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.can(GameFinished, "finished")
+}
+
+// Abort aborts the game before it's over
+func (l *Lifecycle) Abort() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.move(GameAborted, "aborted")
+}
+
+// Playing returns a StateError unless the game is running, so can be bet on and dealt
+func (l *Lifecycle) Playing() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.state != GameRunning {
+		return StateError{l.state, "played"}
+	}
+
+	return nil
+}
+
+func (l *Lifecycle) move(to GameState, action string) error {
+
+	if err := l.can(to, action); err != nil {
+		return err
+	}
+
+	l.state = to
+
+	return nil
+}
+
+// can returns a StateError unless the game can move to the state
+func (l *Lifecycle) can(to GameState, action string) error {
+	for _, next := range gameMoves[l.state] {
+		if next == to {
+			return nil
+		}
+	}
+	return StateError{l.state, action}
+}
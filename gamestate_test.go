@@ -0,0 +1,91 @@
+package poker_test
+
+import (
+	"encoding/json"
+	"github.com/vetch101/go-tddapp"
+	"testing"
+)
+
+func TestLifecycle(t *testing.T) {
+
+	// each case takes a new game through moves, the last of which is the one being tested
+	cases := []struct {
+		name  string
+		moves []func(*poker.Lifecycle) error
+		want  error
+		state poker.GameState
+	}{
+		{"starts", moves(start), nil, poker.GameRunning},
+		{"can't start twice", moves(start, start), poker.StateError{State: poker.GameRunning, Action: "started"}, poker.GameRunning},
+		{"can't finish before it starts", moves(finish), poker.StateError{State: poker.GameCreated, Action: "finished"}, poker.GameCreated},
+		{"can't pause before it starts", moves(pause), poker.StateError{State: poker.GameCreated, Action: "paused"}, poker.GameCreated},
+		{"pauses", moves(start, pause), nil, poker.GamePaused},
+		{"resumes", moves(start, pause, resume), nil, poker.GameRunning},
+		{"can't resume when running", moves(start, resume), poker.StateError{State: poker.GameRunning, Action: "resumed"}, poker.GameRunning},
+		{"can't start when paused", moves(start, pause, start), poker.StateError{State: poker.GamePaused, Action: "started"}, poker.GamePaused},
+		{"finishes when paused", moves(start, pause, finish), nil, poker.GameFinished},
+		{"can't finish twice", moves(start, finish, finish), poker.StateError{State: poker.GameFinished, Action: "finished"}, poker.GameFinished},
+		{"aborts before it starts", moves(abort), nil, poker.GameAborted},
+		{"can't abort once it's finished", moves(start, finish, abort), poker.StateError{State: poker.GameFinished, Action: "aborted"}, poker.GameFinished},
+		{"starts again once it's over", moves(start, abort, start), nil, poker.GameRunning},
+		{"checks it can start without starting", moves(canStart), nil, poker.GameCreated},
+		{"checks it can't start when paused", moves(start, pause, canStart), poker.StateError{State: poker.GamePaused, Action: "started"}, poker.GamePaused},
+		{"checks it can finish without finishing", moves(start, canFinish), nil, poker.GameRunning},
+		{"checks it can't finish once it's aborted", moves(abort, canFinish), poker.StateError{State: poker.GameAborted, Action: "finished"}, poker.GameAborted},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			lifecycle := &poker.Lifecycle{}
+
+			var err error
+			for _, move := range c.moves {
+				err = move(lifecycle)
+			}
+
+			if err != c.want {
+				t.Errorf("got error %v want %v", err, c.want)
+			}
+
+			if lifecycle.State() != c.state {
+				t.Errorf("got state %v want %v", lifecycle.State(), c.state)
+			}
+		})
+	}
+
+	t.Run("only a running game can be played", func(t *testing.T) {
+		lifecycle := &poker.Lifecycle{}
+		assertStateError(t, lifecycle.Playing(), poker.GameCreated, "played")
+
+		lifecycle.Start()
+		poker.AssertNoError(t, lifecycle.Playing())
+	})
+}
+
+func TestGameStateJSON(t *testing.T) {
+
+	data, err := json.Marshal(poker.GamePaused)
+	poker.AssertNoError(t, err)
+
+	if string(data) != `"paused"` {
+		t.Errorf("got %s want %q", data, "paused")
+	}
+
+	var state poker.GameState
+
+	if err := json.Unmarshal([]byte(`"sideways"`), &state); err == nil {
+		t.Error("should not have read an unknown state")
+	}
+}
+
+func moves(moves ...func(*poker.Lifecycle) error) []func(*poker.Lifecycle) error {
+	return moves
+}
+
+func start(l *poker.Lifecycle) error     { return l.Start() }
+func pause(l *poker.Lifecycle) error     { return l.Pause() }
+func resume(l *poker.Lifecycle) error    { return l.Resume() }
+func finish(l *poker.Lifecycle) error    { return l.Finish() }
+func abort(l *poker.Lifecycle) error     { return l.Abort() }
+func canStart(l *poker.Lifecycle) error  { return l.CanStart() }
+func canFinish(l *poker.Lifecycle) error { return l.CanFinish() }
@@ -0,0 +1,230 @@
+package poker
+
+import "math/bits"
+
+// HandCategory is the kind of a poker hand, from HighCard up to RoyalFlush
+type HandCategory int
+
+// The categories of hand, each of which beats every hand in the categories before it
+const (
+	HighCard HandCategory = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+	RoyalFlush
+)
+
+var handCategoryNames = []string{
+	"high card", "pair", "two pair", "three of a kind", "straight",
+	"flush", "full house", "four of a kind", "straight flush", "royal flush",
+}
+
+func (c HandCategory) String() string {
+	if c < HighCard || c > RoyalFlush {
+		return "unknown"
+	}
+	return handCategoryNames[c]
+}
+
+// HandValue is how good the best five cards of a hand are: its category followed by the ranks
+// that break ties within it, packed so that a better hand has a higher HandValue and hands
+// that split the pot have equal ones
+type HandValue uint32
+
+// Category is the kind of hand the HandValue is for
+func (v HandValue) Category() HandCategory {
+	return HandCategory(v >> 20)
+}
+
+func (v HandValue) String() string {
+	return v.Category().String()
+}
+
+// handValue packs a category and up to five tie breaking ranks, most important first
+func handValue(category HandCategory, ranks ...Rank) HandValue {
+	v := HandValue(category) << 20
+	for i, rank := range ranks {
+		v |= HandValue(rank) << uint(16-4*i)
+	}
+	return v
+}
+
+// Evaluate returns the value of the best five card hand that can be made from five to seven cards
+func Evaluate(cards []Card) (HandValue, error) {
+
+	if len(cards) < 5 || len(cards) > 7 {
+		return 0, ErrHandSize
+	}
+
+	// each mask has bit r set for every Rank r held
+	var counts [Ace + 1]int
+	var suits [Spades + 1]uint16
+	var ranks uint16
+
+	for _, card := range cards {
+		counts[card.Rank]++
+		suits[card.Suit] |= 1 << uint(card.Rank)
+		ranks |= 1 << uint(card.Rank)
+	}
+
+	var flush uint16
+
+	for _, suit := range suits {
+		if bits.OnesCount16(suit) >= 5 {
+			flush = suit
+		}
+	}
+
+	if flush != 0 {
+		if high := straightHigh(flush); high == Ace {
+			return handValue(RoyalFlush), nil
+		} else if high != 0 {
+			return handValue(StraightFlush, high), nil
+		}
+	}
+
+	// groups of the same rank, highest rank first
+	var quads, trips, pairs, singles []Rank
+
+	for rank := Ace; rank >= Two; rank-- {
+		switch counts[rank] {
+		case 4:
+			quads = append(quads, rank)
+		case 3:
+			trips = append(trips, rank)
+		case 2:
+			pairs = append(pairs, rank)
+		case 1:
+			singles = append(singles, rank)
+		}
+	}
+
+	switch {
+	case len(quads) > 0:
+		return handValue(FourOfAKind, append([]Rank{quads[0]}, highest(ranks&^(1<<uint(quads[0])), 1)...)...), nil
+
+	case len(trips) > 0 && len(trips)+len(pairs) > 1:
+		// a second set of trips fills the house as well as a pair does
+		pair := Rank(0)
+		if len(trips) > 1 {
+			pair = trips[1]
+		}
+		if len(pairs) > 0 && pairs[0] > pair {
+			pair = pairs[0]
+		}
+		return handValue(FullHouse, trips[0], pair), nil
+
+	case flush != 0:
+		return handValue(Flush, highest(flush, 5)...), nil
+	}
+
+	if high := straightHigh(ranks); high != 0 {
+		return handValue(Straight, high), nil
+	}
+
+	switch {
+	case len(trips) > 0:
+		return handValue(ThreeOfAKind, append([]Rank{trips[0]}, singles[:2]...)...), nil
+
+	case len(pairs) > 1:
+		// a third pair can still play as the kicker
+		kickers := ranks &^ (1<<uint(pairs[0]) | 1<<uint(pairs[1]))
+		return handValue(TwoPair, append([]Rank{pairs[0], pairs[1]}, highest(kickers, 1)...)...), nil
+
+	case len(pairs) > 0:
+		return handValue(OnePair, append([]Rank{pairs[0]}, singles[:3]...)...), nil
+	}
+
+	return handValue(HighCard, singles[:5]...), nil
+}
+
+// straightHigh returns the high card of the best straight in a mask of ranks, or 0 if there isn't one.
+// An ace also plays low, under a Two, to make the five high straight.
+func straightHigh(ranks uint16) Rank {
+
+	if ranks&(1<<uint(Ace)) != 0 {
+		ranks |= 1 << 1
+	}
+
+	for high := Ace; high >= Five; high-- {
+		run := uint16(0x1f) << uint(high-4)
+		if ranks&run == run {
+			return high
+		}
+	}
+
+	return 0
+}
+
+// highest returns the n highest ranks in a mask of ranks, highest first
+func highest(ranks uint16, n int) []Rank {
+
+	found := make([]Rank, 0, n)
+
+	for rank := Ace; rank >= Two && len(found) < n; rank-- {
+		if ranks&(1<<uint(rank)) != 0 {
+			found = append(found, rank)
+		}
+	}
+
+	return found
+}
+
+// BestHands evaluates each hand and returns the indexes of those that win, more than one
+// when they split the pot, along with the winning HandValue
+func BestHands(hands [][]Card) ([]int, HandValue, error) {
+
+	var winners []int
+	var best HandValue
+
+	for i, hand := range hands {
+		value, err := Evaluate(hand)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch {
+		case winners == nil || value > best:
+			winners, best = []int{i}, value
+		case value == best:
+			winners = append(winners, i)
+		}
+	}
+
+	return winners, best, nil
+}
+
+// Showdown returns the seats that win the hand, valued the way its variant says, more than one when
+// they split the pot, along with the winning HandValue
+func (d *Deal) Showdown() ([]int, HandValue, error) {
+
+	if d.street != Showdown {
+		return nil, 0, ErrNotShowdown
+	}
+
+	var winners []int
+	var best HandValue
+
+	for seat, hole := range d.hole {
+		value, err := d.variant.Value(hole, d.board)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch {
+		case winners == nil || value > best:
+			winners, best = []int{seat}, value
+		case value == best:
+			winners = append(winners, seat)
+		}
+	}
+
+	return winners, best, nil
+}
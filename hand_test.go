@@ -0,0 +1,203 @@
+package poker_test
+
+import (
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		cards string
+		want  poker.HandCategory
+	}{
+		{"high card", "As Jd 9c 6h 3s", poker.HighCard},
+		{"pair", "As Ad 9c 6h 3s", poker.OnePair},
+		{"two pair", "As Ad 9c 9h 3s", poker.TwoPair},
+		{"three of a kind", "As Ad Ac 9h 3s", poker.ThreeOfAKind},
+		{"straight", "9s 8d 7c 6h 5s", poker.Straight},
+		{"ace low straight", "As 2d 3c 4h 5s", poker.Straight},
+		{"flush", "As Js 9s 6s 3s", poker.Flush},
+		{"full house", "As Ad Ac 9h 9s", poker.FullHouse},
+		{"four of a kind", "As Ad Ac Ah 9s", poker.FourOfAKind},
+		{"straight flush", "9h 8h 7h 6h 5h", poker.StraightFlush},
+		{"ace low straight flush", "Ah 2h 3h 4h 5h", poker.StraightFlush},
+		{"royal flush", "Ah Kh Qh Jh Th", poker.RoyalFlush},
+		{"best five of six", "As Ad 9c 9h 3s 3d", poker.TwoPair},
+		{"best five of seven", "2c 9h 8h 7h 6h 5h Kd", poker.StraightFlush},
+		{"flush beats the straight in seven", "Ts 9s 8d 7s 6c 2s 3s", poker.Flush},
+		{"two sets of trips are a full house", "As Ad Ac 9h 9s 9d 2c", poker.FullHouse},
+		{"three pairs are two pair", "As Ad Kc Kh 9s 9d 2c", poker.TwoPair},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mustEvaluate(t, c.cards)
+
+			if got.Category() != c.want {
+				t.Errorf("got %v for %s want %v", got.Category(), c.cards, c.want)
+			}
+		})
+	}
+
+	t.Run("needs five to seven cards", func(t *testing.T) {
+		for _, cards := range []string{"As Ad 9c 6h", "As Ad 9c 6h 3s 2c 4d 7h"} {
+			parsed, _ := poker.ParseCards(cards)
+
+			_, err := poker.Evaluate(parsed)
+
+			if err != poker.ErrHandSize {
+				t.Errorf("got error %v for %s want %v", err, cards, poker.ErrHandSize)
+			}
+		}
+	})
+}
+
+func TestEvaluateOrdersHands(t *testing.T) {
+
+	// each hand beats the one after it
+	ordered := []string{
+		"Ah Kh Qh Jh Th",
+		"9h 8h 7h 6h 5h",
+		"Ah 2h 3h 4h 5h",
+		"As Ad Ac Ah Ks",
+		"As Ad Ac Ah Qs",
+		"Ks Kd Kc Kh As",
+		"As Ad Ac 3h 3s",
+		"Ks Kd Kc Ah As",
+		"As Js 9s 6s 4s",
+		"As Js 9s 6s 3s",
+		"As Ks Qd Jc Th",
+		"6s 5d 4c 3h 2s",
+		"As 2d 3c 4h 5s",
+		"Qs Qd Qc 9h 3s",
+		"Qs Qd Qc 9h 2s",
+		"As Ad 9c 9h 3s",
+		"As Ad 8c 8h Ks",
+		"Ks Kd Qc Qh As",
+		"As Ad Kc 6h 3s",
+		"As Ad Qc Jh Ts",
+		"Ks Kd Ac Qh Js",
+		"As Kd 9c 6h 4s",
+		"As Kd 9c 6h 3s",
+		"As Qd Jc Th 8s",
+		"7s 5d 4c 3h 2s",
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		better, worse := mustEvaluate(t, ordered[i-1]), mustEvaluate(t, ordered[i])
+
+		if better <= worse {
+			t.Errorf("%s (%v) should beat %s (%v)", ordered[i-1], better, ordered[i], worse)
+		}
+	}
+
+	t.Run("only the best five cards count", func(t *testing.T) {
+		a := mustEvaluate(t, "As Ad Kc Qh Js 3c 2d")
+		b := mustEvaluate(t, "Ah Ac Kd Qs Jh 4c 3d")
+
+		if a != b {
+			t.Errorf("got %v and %v, want a split", a, b)
+		}
+	})
+
+	t.Run("suits don't break ties", func(t *testing.T) {
+		a := mustEvaluate(t, "As Ks Qs Js 9s")
+		b := mustEvaluate(t, "Ah Kh Qh Jh 9h")
+
+		if a != b {
+			t.Errorf("got %v and %v, want a split", a, b)
+		}
+	})
+}
+
+func TestBestHands(t *testing.T) {
+
+	t.Run("picks the single best hand", func(t *testing.T) {
+		winners, value, err := poker.BestHands(mustParseHands(t, "As Ad 9c 6h 3s", "Ks Kd Kc 6d 3c", "Qs Jd 9h 6c 3d"))
+		poker.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(winners, []int{1}) || value.Category() != poker.ThreeOfAKind {
+			t.Errorf("got %v winning with %v want [1] with three of a kind", winners, value)
+		}
+	})
+
+	t.Run("splits the pot between equal hands", func(t *testing.T) {
+		board := " Ts Jd Qc Kh 2s"
+		winners, value, err := poker.BestHands(mustParseHands(t, "Ac 3d"+board, "4h 5h"+board, "Ad 3c"+board))
+		poker.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(winners, []int{0, 2}) || value.Category() != poker.Straight {
+			t.Errorf("got %v winning with %v want [0 2] with a straight", winners, value)
+		}
+	})
+}
+
+func TestDealShowdown(t *testing.T) {
+	deal, _ := poker.NewDeal(poker.NewDeck(), 3)
+
+	if _, _, err := deal.Showdown(); err != poker.ErrNotShowdown {
+		t.Errorf("got error %v want %v", err, poker.ErrNotShowdown)
+	}
+
+	for deal.Street() != poker.Showdown {
+		deal.Next()
+	}
+
+	// the board of an unshuffled deck is 9c Tc Jc Kc 2d, so every seat makes a king high flush
+	// and seat 2's 7c is the best fifth card
+	winners, value, err := deal.Showdown()
+	poker.AssertNoError(t, err)
+
+	if !reflect.DeepEqual(winners, []int{2}) || value.Category() != poker.Flush {
+		t.Errorf("got %v winning with %v want [2] with a flush", winners, value)
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	deck := poker.NewDeck()
+	deck.Shuffle(poker.NewRandom(1))
+
+	hands := make([][]poker.Card, 7)
+
+	for i := range hands {
+		for j := 0; j < 7; j++ {
+			card, _ := deck.Deal()
+			hands[i] = append(hands[i], card)
+		}
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		poker.Evaluate(hands[i%len(hands)])
+	}
+}
+
+func mustEvaluate(t *testing.T, cards string) poker.HandValue {
+	t.Helper()
+
+	parsed, err := poker.ParseCards(cards)
+	poker.AssertNoError(t, err)
+
+	value, err := poker.Evaluate(parsed)
+	poker.AssertNoError(t, err)
+
+	return value
+}
+
+func mustParseHands(t *testing.T, hands ...string) [][]poker.Card {
+	t.Helper()
+
+	parsed := make([][]poker.Card, len(hands))
+
+	for i, hand := range hands {
+		cards, err := poker.ParseCards(hand)
+		poker.AssertNoError(t, err)
+		parsed[i] = cards
+	}
+
+	return parsed
+}
@@ -0,0 +1,108 @@
+package poker_test
+
+import (
+	"encoding/json"
+	"github.com/vetch101/go-tddapp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+
+	day := time.Date(2019, time.July, 1, 20, 0, 0, 0, time.UTC)
+
+	games := []poker.GameRecord{
+		{ID: "1", Started: day, Finished: day.Add(time.Hour), NumberOfPlayers: 2, Players: []string{"Cleo", "Chris"}, Winner: "Cleo"},
+		{ID: "2", Started: day.Add(24 * time.Hour), Finished: day.Add(25 * time.Hour), NumberOfPlayers: 3, Winner: "Chris"},
+		{ID: "3", Started: day.Add(48 * time.Hour), Finished: day.Add(49 * time.Hour), NumberOfPlayers: 4, Winner: "Trevor"},
+	}
+
+	store := &poker.StubPlayerStore{Games: games}
+	server := mustMakePlayerServer(t, store, dummyGame)
+
+	t.Run("returns every game as JSON", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest(""))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertContentType(t, response.Result().Header.Get("content-type"), jsonContentType)
+		poker.AssertGames(t, getGamesFromResponse(t, response), games)
+	})
+
+	t.Run("filters by player", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("?player=Chris"))
+
+		poker.AssertGames(t, getGamesFromResponse(t, response), games[:2])
+	})
+
+	t.Run("filters by whole days", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("?from=2019-07-02&to=2019-07-02"))
+
+		poker.AssertGames(t, getGamesFromResponse(t, response), games[1:2])
+	})
+
+	t.Run("filters by times", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("?from=2019-07-01T21:00:00Z"))
+
+		poker.AssertGames(t, getGamesFromResponse(t, response), games[1:])
+	})
+
+	t.Run("rejects a bad date", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newHistoryRequest("?from=last-tuesday"))
+
+		poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestGameRecordMatches(t *testing.T) {
+
+	day := time.Date(2019, time.July, 1, 20, 0, 0, 0, time.UTC)
+	game := poker.GameRecord{Started: day, Players: []string{"Cleo", "Chris"}, Winner: "Chris"}
+
+	cases := []struct {
+		name  string
+		query poker.GameQuery
+		want  bool
+	}{
+		{"empty query", poker.GameQuery{}, true},
+		{"participant", poker.GameQuery{Player: "Cleo"}, true},
+		{"winner", poker.GameQuery{Player: "Chris"}, true},
+		{"someone else", poker.GameQuery{Player: "Trevor"}, false},
+		{"from the start", poker.GameQuery{From: day}, true},
+		{"from later", poker.GameQuery{From: day.Add(time.Second)}, false},
+		{"to the start", poker.GameQuery{To: day}, true},
+		{"to earlier", poker.GameQuery{To: day.Add(-time.Second)}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := game.Matches(c.query); got != c.want {
+				t.Errorf("got %v want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func newHistoryRequest(query string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "/history"+query, nil)
+	return req
+}
+
+func getGamesFromResponse(t *testing.T, response *httptest.ResponseRecorder) []poker.GameRecord {
+	t.Helper()
+
+	var games []poker.GameRecord
+	err := json.NewDecoder(response.Body).Decode(&games)
+
+	if err != nil {
+		t.Fatalf("could not decode games from %q, %v", response.Body.String(), err)
+	}
+
+	return games
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 )
 
 // League is an array of Players
@@ -31,3 +32,90 @@ func (l League) Find(name string) *Player {
 	}
 	return nil
 }
+
+// recordWin counts a game won by name, whose other players aren't known, in place
+// (adding them if they're new) and returns the League, which may have been reallocated like the result of append
+func (l League) recordWin(name string) League {
+	l, player := l.findOrAdd(name)
+
+	player.Wins++
+	player.GamesPlayed++
+
+	return l
+}
+
+// recordGame counts a finished game for everyone who played in it, in place like recordWin
+func (l League) recordGame(game GameRecord) League {
+	for _, name := range game.participants() {
+		var player *Player
+		l, player = l.findOrAdd(name)
+
+		player.GamesPlayed++
+
+		if name == game.Winner {
+			player.Wins++
+		} else {
+			player.Losses++
+		}
+	}
+
+	return l
+}
+
+// findOrAdd returns the League and the named Player, who is added if they're new
+func (l League) findOrAdd(name string) (League, *Player) {
+	player := l.Find(name)
+
+	if player != nil {
+		return l, player
+	}
+
+	l = append(l, Player{Name: name})
+	return l, &l[len(l)-1]
+}
+
+// copyLeague returns a League that can be changed without affecting l
+func (l League) copyLeague() League {
+	league := make(League, len(l))
+	copy(league, l)
+	return league
+}
+
+// sorted returns a copy of the League ordered by wins, with ties ordered by name
+// so that every PlayerStore agrees on the table
+func (l League) sorted() League {
+	league := l.copyLeague()
+
+	sort.Slice(league, func(i, j int) bool {
+		if league[i].Wins != league[j].Wins {
+			return league[i].Wins > league[j].Wins
+		}
+		return league[i].Name < league[j].Name
+	})
+	return league
+}
+
+// RankedByWinRate returns a copy of the League ordered by win rate. Players who have played fewer
+// than minGames come after everyone who has, so one lucky game doesn't top the table.
+func (l League) RankedByWinRate(minGames int) League {
+	league := l.copyLeague()
+
+	sort.Slice(league, func(i, j int) bool {
+		a, b := league[i], league[j]
+
+		if qualifiedA, qualifiedB := a.GamesPlayed >= minGames, b.GamesPlayed >= minGames; qualifiedA != qualifiedB {
+			return qualifiedA
+		}
+
+		if a.WinRate() != b.WinRate() {
+			return a.WinRate() > b.WinRate()
+		}
+
+		if a.GamesPlayed != b.GamesPlayed {
+			return a.GamesPlayed > b.GamesPlayed
+		}
+
+		return a.Name < b.Name
+	})
+	return league
+}
@@ -7,6 +7,13 @@ import (
 	"testing"
 )
 
+var rankingLeague = []poker.Player{
+	{Name: "Regular", Wins: 6, Losses: 4, GamesPlayed: 10},
+	{Name: "Lucky", Wins: 1, GamesPlayed: 1},
+	{Name: "Grinder", Wins: 7, Losses: 13, GamesPlayed: 20},
+	{Name: "Steady", Wins: 3, Losses: 2, GamesPlayed: 5},
+}
+
 func TestLeague(t *testing.T) {
 
 	t.Run("it returns league table as JSON", func(t *testing.T) {
@@ -18,7 +25,7 @@ func TestLeague(t *testing.T) {
 		}
 
 		store := poker.StubPlayerStore{Scores: nil, WinCalls: nil, League: wantedLeague}
-		server, _ := poker.NewPlayerServer(&store, dummyGame)
+		server, _ := poker.NewPlayerServer(&store, managerFor(dummyGame))
 
 		request := newLeagueRequest()
 		response := httptest.NewRecorder()
@@ -36,3 +43,76 @@ func TestLeague(t *testing.T) {
 		poker.AssertLeague(t, league, wantedLeague)
 	})
 }
+
+func TestLeagueRankedByWinRate(t *testing.T) {
+
+	t.Run("ranks by win rate", func(t *testing.T) {
+		got := poker.League(rankingLeague).RankedByWinRate(0)
+
+		want := poker.League{rankingLeague[1], rankingLeague[0], rankingLeague[3], rankingLeague[2]}
+		poker.AssertLeague(t, got, want)
+	})
+
+	t.Run("players under the minimum games come last", func(t *testing.T) {
+		got := poker.League(rankingLeague).RankedByWinRate(5)
+
+		want := poker.League{rankingLeague[0], rankingLeague[3], rankingLeague[2], rankingLeague[1]}
+		poker.AssertLeague(t, got, want)
+	})
+
+	t.Run("equal win rates rank the player with more games first", func(t *testing.T) {
+		league := poker.League{
+			{Name: "Cleo", Wins: 1, Losses: 1, GamesPlayed: 2},
+			{Name: "Chris", Wins: 5, Losses: 5, GamesPlayed: 10},
+		}
+
+		got := league.RankedByWinRate(0)
+
+		poker.AssertLeague(t, got, poker.League{league[1], league[0]})
+	})
+
+	t.Run("doesn't reorder the league it was given", func(t *testing.T) {
+		league := poker.League(rankingLeague).RankedByWinRate(0)
+
+		if league[0].Name == rankingLeague[0].Name {
+			t.Fatalf("expected the ranking to differ from the original order")
+		}
+
+		if rankingLeague[0].Name != "Regular" {
+			t.Errorf("original league was reordered, got %v", rankingLeague)
+		}
+	})
+
+	t.Run("/league?rank=winrate&mingames=5 ranks by win rate", func(t *testing.T) {
+		store := poker.StubPlayerStore{League: rankingLeague}
+		server := mustMakePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/league?rank=winrate&mingames=5", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		want := []poker.Player{rankingLeague[0], rankingLeague[3], rankingLeague[2], rankingLeague[1]}
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertLeague(t, getLeagueFromResponse(t, response.Body), want)
+	})
+}
+
+func TestPlayerWinRate(t *testing.T) {
+	cases := []struct {
+		player poker.Player
+		want   float64
+	}{
+		{poker.Player{Name: "New"}, 0},
+		{poker.Player{Name: "Cleo", Wins: 1, Losses: 3, GamesPlayed: 4}, 0.25},
+		{poker.Player{Name: "Chris", Wins: 2, GamesPlayed: 2}, 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.player.Name, func(t *testing.T) {
+			if got := c.player.WinRate(); got != c.want {
+				t.Errorf("got win rate %v want %v", got, c.want)
+			}
+		})
+	}
+}
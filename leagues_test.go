@@ -0,0 +1,114 @@
+package poker_test
+
+import (
+	"encoding/json"
+	"github.com/vetch101/go-tddapp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLeagues(t *testing.T) {
+
+	newStore := func(t *testing.T) *poker.StubPlayerStore {
+		t.Helper()
+		store := &poker.StubPlayerStore{}
+		poker.AssertNoError(t, store.StartSeason("", "2026Q3"))
+		poker.AssertNoError(t, store.RecordLeagueWin("", "", "Cleo"))
+		poker.AssertNoError(t, store.RecordLeagueWin("office", "", "Chris"))
+		return store
+	}
+
+	t.Run("returns a season of the main league", func(t *testing.T) {
+		server := mustMakePlayerServer(t, newStore(t), dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRequest(http.MethodGet, "/league?season=2026Q3"))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertContentType(t, response.Result().Header.Get("content-type"), jsonContentType)
+		poker.AssertLeague(t, getLeagueFromResponse(t, response.Body), poker.League{{Name: "Cleo", Wins: 1, GamesPlayed: 1}})
+	})
+
+	t.Run("returns a named league", func(t *testing.T) {
+		server := mustMakePlayerServer(t, newStore(t), dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRequest(http.MethodGet, "/leagues/office"))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertLeague(t, getLeagueFromResponse(t, response.Body), poker.League{{Name: "Chris", Wins: 1, GamesPlayed: 1}})
+	})
+
+	t.Run("returns 404 on missing leagues and seasons", func(t *testing.T) {
+		server := mustMakePlayerServer(t, newStore(t), dummyGame)
+
+		for _, path := range []string{"/leagues/home", "/league?season=2026Q4", "/leagues/office?season=2026Q3"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newRequest(http.MethodGet, path))
+
+			poker.AssertStatus(t, response.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("records wins into a named league", func(t *testing.T) {
+		store := newStore(t)
+		server := mustMakePlayerServer(t, store, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRequest(http.MethodPost, "/players/Chris?league=office"))
+
+		poker.AssertStatus(t, response.Code, http.StatusAccepted)
+
+		office, _ := store.GetLeagueTable("office", "")
+		poker.AssertLeague(t, office, poker.League{{Name: "Chris", Wins: 2, GamesPlayed: 2}})
+	})
+
+	t.Run("starts, lists and closes seasons", func(t *testing.T) {
+		store := newStore(t)
+		server := mustMakePlayerServer(t, store, dummyGame)
+
+		cases := []struct {
+			method string
+			path   string
+			want   int
+		}{
+			{http.MethodPost, "/seasons?league=office&name=spring", http.StatusCreated},
+			{http.MethodPost, "/seasons?league=office&name=summer", http.StatusConflict},
+			{http.MethodPost, "/seasons?league=office", http.StatusBadRequest},
+			{http.MethodPost, "/seasons/close?league=office&name=spring", http.StatusOK},
+			{http.MethodPost, "/seasons/close?league=office&name=spring", http.StatusConflict},
+			{http.MethodPost, "/seasons/close?league=office&name=summer", http.StatusNotFound},
+			{http.MethodGet, "/seasons/close?league=office&name=spring", http.StatusMethodNotAllowed},
+			{http.MethodPost, "/players/Chris?league=office&season=spring", http.StatusConflict},
+		}
+
+		for _, c := range cases {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newRequest(c.method, c.path))
+
+			if response.Code != c.want {
+				t.Errorf("%s %s got status %d want %d", c.method, c.path, response.Code, c.want)
+			}
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRequest(http.MethodGet, "/seasons?league=office"))
+
+		var seasons []poker.Season
+		err := json.NewDecoder(response.Body).Decode(&seasons)
+
+		if err != nil {
+			t.Fatalf("unable to parse response from server %q into seasons, '%v'", response.Body, err)
+		}
+
+		if len(seasons) != 1 || seasons[0].Name != "spring" || seasons[0].Open() {
+			t.Errorf("got seasons %+v, want spring closed", seasons)
+		}
+	})
+}
+
+func newRequest(method, path string) *http.Request {
+	request, _ := http.NewRequest(method, path, nil)
+	return request
+}
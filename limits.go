@@ -0,0 +1,178 @@
+package poker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The names of the betting structures games can be played with
+const (
+	NoLimitName    = "no-limit"
+	PotLimitName   = "pot-limit"
+	FixedLimitName = "fixed-limit"
+)
+
+// DefaultRaiseCap is how many bets and raises a street of a fixed-limit game allows: a bet and three raises
+const DefaultRaiseCap = 4
+
+// BetSituation is what a BettingStructure goes by to limit the bet of the seat to act
+type BetSituation struct {
+	// Round is how many streets have been bet on before this one
+	Round int
+	// CurrentBet is the bet everyone has to match, and Raises how many bets and raises made it
+	CurrentBet int
+	Raises     int
+	// LastRaise is the size of the last full bet or raise, and never less than BigBlind
+	LastRaise int
+	BigBlind  int
+	// Bet and Chips are what the seat to act has bet on the street and has left
+	Bet   int
+	Chips int
+	// Pot is every chip put in so far, including the bets on the street
+	Pot int
+}
+
+// ToCall is how much more the seat to act has to put in to call
+func (s BetSituation) ToCall() int {
+	return s.CurrentBet - s.Bet
+}
+
+// BettingStructure limits how much players can bet and raise. Limits is the least and most the seat to act
+// can make their bet up to by betting or raising, reporting false if they can't bet or raise at all. Going all
+// in for less than the least is always allowed, and no one can bet more chips than they have.
+type BettingStructure interface {
+	Limits(situation BetSituation) (min, max int, ok bool)
+	String() string
+}
+
+// NoLimit lets players bet everything they have, raising by at least the last bet or raise
+type NoLimit struct{}
+
+// Limits are a raise of the last bet or raise up to every chip the seat has
+func (NoLimit) Limits(s BetSituation) (int, int, bool) {
+	return s.CurrentBet + s.LastRaise, s.Bet + s.Chips, true
+}
+
+func (NoLimit) String() string {
+	return NoLimitName
+}
+
+// PotLimit lets players raise by at least the last bet or raise and at most the pot, counting their call
+type PotLimit struct{}
+
+// Limits are a raise of the last bet or raise up to a raise of the pot after calling
+func (PotLimit) Limits(s BetSituation) (int, int, bool) {
+	return s.CurrentBet + s.LastRaise, s.CurrentBet + s.Pot + s.ToCall(), true
+}
+
+func (PotLimit) String() string {
+	return PotLimitName
+}
+
+// FixedLimit makes every bet and raise the small bet on the first two streets and the big bet after them,
+// with at most Cap bets and raises on a street. The small bet is the big blind if it's 0, the big bet twice
+// the small bet if it's 0, and the raises aren't capped if Cap is 0.
+type FixedLimit struct {
+	SmallBet int
+	BigBet   int
+	Cap      int
+}
+
+// Limits are the one bet size of the street, made up to from the last full bet, or nothing once the street
+// is capped or there's no bet size, such as when the big blind is 0
+func (f FixedLimit) Limits(s BetSituation) (int, int, bool) {
+
+	if f.Cap > 0 && s.Raises >= f.Cap {
+		return 0, 0, false
+	}
+
+	size := f.BetSize(s.Round, s.BigBlind)
+
+	if size <= 0 {
+		return 0, 0, false
+	}
+
+	// an all in for less than a full raise, or a stud bring-in, is raised as if it were the bet before it
+	to := s.CurrentBet - s.CurrentBet%size + size
+
+	return to, to, true
+}
+
+// BetSize is what every bet and raise on the round'th street is with the big blind at bigBlind
+func (f FixedLimit) BetSize(round, bigBlind int) int {
+
+	small := f.SmallBet
+
+	if small == 0 {
+		small = bigBlind
+	}
+
+	if round < 2 {
+		return small
+	}
+
+	if f.BigBet == 0 {
+		return 2 * small
+	}
+
+	return f.BigBet
+}
+
+func (f FixedLimit) String() string {
+	return FixedLimitName
+}
+
+// ParseBettingStructure reads a betting structure from its name, which for fixed-limit can be followed by
+// small-bet={chips},big-bet={chips},cap={raises}, such as fixed-limit,small-bet=100,big-bet=200,cap=4
+func ParseBettingStructure(input string) (BettingStructure, error) {
+
+	settings := strings.Split(input, ",")
+
+	switch strings.TrimSpace(settings[0]) {
+	case NoLimitName:
+		if len(settings) == 1 {
+			return NoLimit{}, nil
+		}
+	case PotLimitName:
+		if len(settings) == 1 {
+			return PotLimit{}, nil
+		}
+	case FixedLimitName:
+		return parseFixedLimit(settings[1:])
+	}
+
+	return nil, ErrBadBettingStructure
+}
+
+// parseFixedLimit reads the settings of a fixed-limit betting structure
+func parseFixedLimit(settings []string) (BettingStructure, error) {
+
+	limit := FixedLimit{Cap: DefaultRaiseCap}
+
+	for _, setting := range settings {
+		parts := strings.SplitN(strings.TrimSpace(setting), "=", 2)
+
+		if len(parts) != 2 {
+			return nil, ErrBadBettingStructure
+		}
+
+		value, err := strconv.Atoi(parts[1])
+
+		if err != nil || value < 0 {
+			return nil, ErrBadBettingStructure
+		}
+
+		switch parts[0] {
+		case "small-bet":
+			limit.SmallBet = value
+		case "big-bet":
+			limit.BigBet = value
+		case "cap":
+			limit.Cap = value
+		default:
+			return nil, ErrBadBettingStructure
+		}
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,308 @@
+package poker_test
+
+import (
+	"bytes"
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"testing"
+)
+
+func TestBettingStructureLimits(t *testing.T) {
+
+	cases := []struct {
+		name      string
+		structure poker.BettingStructure
+		situation poker.BetSituation
+		min, max  int
+		ok        bool
+	}{
+		{
+			"no-limit raises the big blind by at least the big blind, up to every chip",
+			poker.NoLimit{},
+			poker.BetSituation{CurrentBet: 100, Raises: 1, LastRaise: 100, BigBlind: 100, Chips: 5000, Pot: 150},
+			200, 5000, true,
+		},
+		{
+			"no-limit re-raises by at least the last raise",
+			poker.NoLimit{},
+			poker.BetSituation{CurrentBet: 300, Raises: 2, LastRaise: 200, BigBlind: 100, Bet: 100, Chips: 4900, Pot: 450},
+			500, 5000, true,
+		},
+		{
+			"no-limit opens a street with at least the big blind",
+			poker.NoLimit{},
+			poker.BetSituation{Round: 1, LastRaise: 100, BigBlind: 100, Chips: 4700, Pot: 900},
+			100, 4700, true,
+		},
+		{
+			"pot-limit raises the big blind to the pot after calling it",
+			poker.PotLimit{},
+			poker.BetSituation{CurrentBet: 100, Raises: 1, LastRaise: 100, BigBlind: 100, Chips: 5000, Pot: 150},
+			200, 350, true,
+		},
+		{
+			"pot-limit counts the small blind's own bet in the pot",
+			poker.PotLimit{},
+			poker.BetSituation{CurrentBet: 100, Raises: 1, LastRaise: 100, BigBlind: 100, Bet: 50, Chips: 4950, Pot: 150},
+			200, 300, true,
+		},
+		{
+			"pot-limit re-raises a raise to the pot after calling it",
+			poker.PotLimit{},
+			poker.BetSituation{CurrentBet: 350, Raises: 2, LastRaise: 250, BigBlind: 100, Bet: 100, Chips: 4900, Pot: 500},
+			600, 1100, true,
+		},
+		{
+			"pot-limit opens a street with up to the pot",
+			poker.PotLimit{},
+			poker.BetSituation{Round: 1, LastRaise: 100, BigBlind: 100, Chips: 4700, Pot: 600},
+			100, 600, true,
+		},
+		{
+			"pot-limit raises a bet to the pot with the bet and the call in it",
+			poker.PotLimit{},
+			poker.BetSituation{Round: 2, CurrentBet: 200, Raises: 1, LastRaise: 200, BigBlind: 100, Chips: 4700, Pot: 800},
+			400, 1200, true,
+		},
+		{
+			"fixed-limit raises by the small bet, the big blind, before the turn",
+			poker.FixedLimit{Cap: 4},
+			poker.BetSituation{CurrentBet: 100, Raises: 1, LastRaise: 100, BigBlind: 100, Chips: 5000, Pot: 150},
+			200, 200, true,
+		},
+		{
+			"fixed-limit bets the big bet, twice the small bet, from the turn",
+			poker.FixedLimit{Cap: 4},
+			poker.BetSituation{Round: 2, LastRaise: 100, BigBlind: 100, Chips: 4800, Pot: 600},
+			200, 200, true,
+		},
+		{
+			"fixed-limit raises the big bet on the river",
+			poker.FixedLimit{Cap: 4},
+			poker.BetSituation{Round: 3, CurrentBet: 200, Raises: 1, LastRaise: 200, BigBlind: 100, Chips: 4800, Pot: 800},
+			400, 400, true,
+		},
+		{
+			"fixed-limit goes by the bet sizes it's given",
+			poker.FixedLimit{SmallBet: 50, BigBet: 150},
+			poker.BetSituation{Round: 3, CurrentBet: 150, Raises: 1, LastRaise: 150, BigBlind: 100, Chips: 4800, Pot: 800},
+			300, 300, true,
+		},
+		{
+			"fixed-limit completes a stud bring-in to the small bet",
+			poker.FixedLimit{Cap: 4},
+			poker.BetSituation{CurrentBet: 50, LastRaise: 50, BigBlind: 100, Chips: 5000, Pot: 50},
+			100, 100, true,
+		},
+		{
+			"fixed-limit raises an all in for less as if it were the bet before it",
+			poker.FixedLimit{Cap: 4},
+			poker.BetSituation{CurrentBet: 250, Raises: 2, LastRaise: 100, BigBlind: 100, Chips: 5000, Pot: 400},
+			300, 300, true,
+		},
+		{
+			"fixed-limit stops raising once a street has been capped",
+			poker.FixedLimit{Cap: 4},
+			poker.BetSituation{CurrentBet: 400, Raises: 4, LastRaise: 100, BigBlind: 100, Chips: 5000, Pot: 750},
+			0, 0, false,
+		},
+		{
+			"fixed-limit can't bet without a bet size",
+			poker.FixedLimit{Cap: 4},
+			poker.BetSituation{LastRaise: 0, BigBlind: 0, Chips: 5000},
+			0, 0, false,
+		},
+		{
+			"fixed-limit without a cap keeps raising",
+			poker.FixedLimit{},
+			poker.BetSituation{CurrentBet: 1000, Raises: 10, LastRaise: 100, BigBlind: 100, Chips: 5000, Pot: 3000},
+			1100, 1100, true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			min, max, ok := c.structure.Limits(c.situation)
+
+			if min != c.min || max != c.max || ok != c.ok {
+				t.Errorf("got %d to %d (%v) want %d to %d (%v)", min, max, ok, c.min, c.max, c.ok)
+			}
+		})
+	}
+}
+
+func TestParseBettingStructure(t *testing.T) {
+
+	cases := []struct {
+		input string
+		want  poker.BettingStructure
+		err   error
+	}{
+		{"no-limit", poker.NoLimit{}, nil},
+		{"pot-limit", poker.PotLimit{}, nil},
+		{"fixed-limit", poker.FixedLimit{Cap: poker.DefaultRaiseCap}, nil},
+		{"fixed-limit,small-bet=100,big-bet=200,cap=3", poker.FixedLimit{SmallBet: 100, BigBet: 200, Cap: 3}, nil},
+		{"fixed-limit, cap=0", poker.FixedLimit{}, nil},
+		{"fixed-limit,cap=-1", nil, poker.ErrBadBettingStructure},
+		{"fixed-limit,raises=3", nil, poker.ErrBadBettingStructure},
+		{"fixed-limit,cap", nil, poker.ErrBadBettingStructure},
+		{"pot-limit,cap=3", nil, poker.ErrBadBettingStructure},
+		{"spread-limit", nil, poker.ErrBadBettingStructure},
+		{"", nil, poker.ErrBadBettingStructure},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			got, err := poker.ParseBettingStructure(c.input)
+
+			if err != c.err {
+				t.Fatalf("got error %v want %v", err, c.err)
+			}
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %#v want %#v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFixedLimitBetting(t *testing.T) {
+
+	newFixedLimit := func(stacks ...int) *poker.Betting {
+		b := mustBetting(t, stacks, 0)
+		b.SetStructure(poker.FixedLimit{Cap: 4})
+		return b
+	}
+
+	t.Run("raises are one small bet each until the street is capped", func(t *testing.T) {
+		b := newFixedLimit(5000, 5000, 5000)
+
+		if err := b.Act(0, poker.Action{Type: poker.Raise, Amount: 250}); err != poker.ErrOverLimit {
+			t.Errorf("got error %v want %v", err, poker.ErrOverLimit)
+		}
+
+		if err := b.Act(0, poker.Action{Type: poker.Raise, Amount: 150}); err != poker.ErrBetTooSmall {
+			t.Errorf("got error %v want %v", err, poker.ErrBetTooSmall)
+		}
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Raise, Amount: 200})
+		mustAct(t, b, 1, poker.Action{Type: poker.Raise, Amount: 300})
+		mustAct(t, b, 2, poker.Action{Type: poker.Raise, Amount: 400})
+
+		if err := b.Act(0, poker.Action{Type: poker.Raise, Amount: 500}); err != poker.ErrRaiseCapped {
+			t.Errorf("got error %v want %v", err, poker.ErrRaiseCapped)
+		}
+
+		if err := b.Act(0, poker.Action{Type: poker.AllIn}); err != poker.ErrRaiseCapped {
+			t.Errorf("got error %v going all in want %v", err, poker.ErrRaiseCapped)
+		}
+
+		if b.MinBet() != 0 || b.MaxBet() != 0 {
+			t.Errorf("got bets of %d to %d want none once capped", b.MinBet(), b.MaxBet())
+		}
+
+		mustAct(t, b, 0, poker.Action{Type: poker.Call})
+		assertBets(t, b, 400, 300, 400)
+	})
+
+	t.Run("bets double from the turn", func(t *testing.T) {
+		b := newFixedLimit(5000, 5000, 5000)
+
+		for street := 0; street < 2; street++ {
+			for b.ToAct() >= 0 {
+				action := poker.Action{Type: poker.Check}
+				if b.ToCall() > 0 {
+					action.Type = poker.Call
+				}
+				mustAct(t, b, b.ToAct(), action)
+			}
+			poker.AssertNoError(t, b.NextStreet())
+		}
+
+		if b.Street() != poker.Turn || b.MinBet() != 200 || b.MaxBet() != 200 {
+			t.Errorf("got bets of %d to %d on the %v want 200", b.MinBet(), b.MaxBet(), b.Street())
+		}
+	})
+
+	t.Run("a short stack can go all in for less than a bet, or a raise of less than one", func(t *testing.T) {
+		b := newFixedLimit(150, 5000, 5000)
+
+		mustAct(t, b, 0, poker.Action{Type: poker.AllIn})
+		assertBets(t, b, 150, 50, 100)
+
+		if b.MinBet() != 200 {
+			t.Errorf("got a raise to %d want the all in raised as if it were the big blind, to 200", b.MinBet())
+		}
+	})
+
+	t.Run("a stud bring-in is completed to the small bet", func(t *testing.T) {
+		b, err := poker.NewStudBetting([]int{5000, 5000, 5000}, 0, blinds, func(int) poker.HandValue { return 0 })
+		poker.AssertNoError(t, err)
+		b.SetStructure(poker.FixedLimit{Cap: 4})
+
+		if b.MinBet() != 100 || b.MaxBet() != 100 {
+			t.Errorf("got the bring-in completed to %d to %d want 100", b.MinBet(), b.MaxBet())
+		}
+
+		mustAct(t, b, 1, poker.Action{Type: poker.Raise, Amount: 100})
+
+		if b.MinBet() != 200 {
+			t.Errorf("got a raise to %d want 200", b.MinBet())
+		}
+	})
+}
+
+func TestGame_BettingStructure(t *testing.T) {
+
+	t.Run("variants are played with their own betting structure", func(t *testing.T) {
+		cases := map[string]poker.BettingStructure{
+			poker.TexasHoldEmName:   poker.NoLimit{},
+			poker.PotLimitOmahaName: poker.PotLimit{},
+			poker.SevenCardStudName: poker.NoLimit{},
+		}
+
+		for name, want := range cases {
+			variant, _ := poker.VariantFor(name)
+			game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+			game.SetVariant(variant)
+			game.Start(3, &bytes.Buffer{})
+
+			if got := game.Betting().Structure(); got != want {
+				t.Errorf("got %v betting for %s want %v", got, name, want)
+			}
+		}
+	})
+
+	t.Run("a game's betting structure is played in place of the variant's", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetVariant(poker.PotLimitOmaha)
+		game.SetBettingStructure(poker.FixedLimit{Cap: 4})
+		game.Start(3, &bytes.Buffer{})
+
+		betting := game.Betting()
+		want := 2 * betting.Seats()[2].Bet
+
+		if betting.Structure().String() != poker.FixedLimitName || betting.MinBet() != want || betting.MaxBet() != want {
+			t.Errorf("got %v bets of %d to %d want fixed-limit raises to %d", betting.Structure(), betting.MinBet(), betting.MaxBet(), want)
+		}
+
+		if err := game.Act(poker.Action{Type: poker.Raise, Amount: want + 1}); err != poker.ErrOverLimit {
+			t.Errorf("got error %v want %v", err, poker.ErrOverLimit)
+		}
+
+		poker.AssertNoError(t, game.Act(poker.Action{Type: poker.Raise, Amount: want}))
+	})
+
+	t.Run("fixed-limit without a big blind can only be checked and called", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetBlindStructure(poker.BlindStructure{Levels: []poker.BlindLevel{{}}})
+		game.SetBettingStructure(poker.FixedLimit{Cap: 4})
+		poker.AssertNoError(t, game.Start(3, &bytes.Buffer{}))
+
+		if betting := game.Betting(); betting.MinBet() != 0 || betting.MaxBet() != 0 {
+			t.Errorf("got bets of %d to %d want none", betting.MinBet(), betting.MaxBet())
+		}
+
+		checkAround(t, game)
+	})
+}
@@ -0,0 +1,253 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// CurrentDBVersion is the schema version the FileSystemPlayerStore writes
+const CurrentDBVersion = 5
+
+// dbFile is the versioned envelope the FileSystemPlayerStore keeps its data in
+type dbFile struct {
+	Version int
+	League  League
+	Games   []GameRecord
+	Ratings Ratings
+	Leagues Leagues
+}
+
+// dbMigration rolls the json of a db file forward by one version
+type dbMigration func(data []byte) ([]byte, error)
+
+// dbMigrations holds the migration from each version to the next, indexed by the version it starts from.
+// Changing the schema means bumping CurrentDBVersion and appending the migration that gets there.
+var dbMigrations = []dbMigration{
+	migrateBareLeague,
+	migrateAddGames,
+	migrateAddGamesPlayed,
+	migrateAddRatings,
+	migrateAddLeagues,
+}
+
+// MigrateDBFile rolls the json of a db file at any earlier version forward to CurrentDBVersion
+func MigrateDBFile(data []byte) ([]byte, error) {
+	return MigrateDBFileTo(data, CurrentDBVersion)
+}
+
+// MigrateDBFileTo rolls the json of a db file at any earlier version forward to version to
+func MigrateDBFileTo(data []byte, to int) ([]byte, error) {
+
+	version, err := dbFileVersion(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if version > to || to > CurrentDBVersion {
+		return nil, ErrDBVersion
+	}
+
+	for ; version < to; version++ {
+		if version >= len(dbMigrations) {
+			return nil, ErrMigration
+		}
+
+		data, err = dbMigrations[version](data)
+
+		if err != nil {
+			return nil, ErrMigration
+		}
+	}
+
+	return data, nil
+}
+
+// dbFileVersion reads the version of a db file. Files from before
+// versioning are a bare League array, which counts as version 0.
+func dbFileVersion(data []byte) (int, error) {
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return 0, nil
+	}
+
+	var envelope struct {
+		Version int
+	}
+
+	err := json.Unmarshal(data, &envelope)
+
+	if err != nil {
+		return 0, ErrLoadingPlayerStore
+	}
+
+	return envelope.Version, nil
+}
+
+// decodeDBFile migrates the json of a db file and decodes it, reporting whether it needed migrating
+func decodeDBFile(data []byte) (dbFile, bool, error) {
+
+	var db dbFile
+
+	migrated, err := MigrateDBFile(data)
+
+	if err != nil {
+		return db, false, err
+	}
+
+	err = json.Unmarshal(migrated, &db)
+
+	if err != nil {
+		return db, false, ErrLoadingPlayerStore
+	}
+
+	if db.League == nil {
+		db.League = League{}
+	}
+
+	if db.Games == nil {
+		db.Games = []GameRecord{}
+	}
+
+	if db.Ratings == nil {
+		db.Ratings = Ratings{}
+	}
+
+	if db.Leagues.Tables == nil {
+		db.Leagues = newLeagues(db.League)
+	}
+
+	if db.Leagues.Seasons == nil {
+		db.Leagues.Seasons = []Season{}
+	}
+
+	return db, !bytes.Equal(migrated, data), nil
+}
+
+// migrateBareLeague wraps the original bare League array in a version 1 envelope.
+// Migrations work on the json rather than today's types, which will have moved on.
+func migrateBareLeague(data []byte) ([]byte, error) {
+
+	var league []json.RawMessage
+
+	err := json.Unmarshal(data, &league)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		Version int
+		League  []json.RawMessage
+	}{1, league})
+}
+
+// migrateAddGames gives a version 1 file an empty history of games
+func migrateAddGames(data []byte) ([]byte, error) {
+
+	var envelope map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &envelope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	envelope["Version"] = json.RawMessage("2")
+	envelope["Games"] = json.RawMessage("[]")
+
+	return json.Marshal(envelope)
+}
+
+// migrateAddGamesPlayed gives every player in a version 2 file no losses,
+// and counts each of their wins as a game played as that's all that was known
+func migrateAddGamesPlayed(data []byte) ([]byte, error) {
+
+	var envelope map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &envelope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var league []map[string]json.RawMessage
+
+	err = json.Unmarshal(envelope["League"], &league)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, player := range league {
+		wins := player["Wins"]
+		if wins == nil {
+			wins = json.RawMessage("0")
+		}
+		player["Losses"] = json.RawMessage("0")
+		player["GamesPlayed"] = wins
+	}
+
+	envelope["League"], err = json.Marshal(league)
+
+	if err != nil {
+		return nil, err
+	}
+
+	envelope["Version"] = json.RawMessage("3")
+
+	return json.Marshal(envelope)
+}
+
+// migrateAddRatings gives a version 3 file an empty table of ratings,
+// so everyone starts from the initial rating
+func migrateAddRatings(data []byte) ([]byte, error) {
+
+	var envelope map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &envelope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	envelope["Version"] = json.RawMessage("4")
+	envelope["Ratings"] = json.RawMessage("[]")
+
+	return json.Marshal(envelope)
+}
+
+// migrateAddLeagues gives a version 4 file no seasons, and starts the
+// main league off with every win so far as none were recorded anywhere else
+func migrateAddLeagues(data []byte) ([]byte, error) {
+
+	var envelope map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &envelope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	leagues := struct {
+		Seasons []json.RawMessage
+		Tables  []map[string]json.RawMessage
+	}{
+		Seasons: []json.RawMessage{},
+		Tables: []map[string]json.RawMessage{{
+			"League":  json.RawMessage(`""`),
+			"Season":  json.RawMessage(`""`),
+			"Players": envelope["League"],
+		}},
+	}
+
+	envelope["Leagues"], err = json.Marshal(leagues)
+
+	if err != nil {
+		return nil, err
+	}
+
+	envelope["Version"] = json.RawMessage("5")
+
+	return json.Marshal(envelope)
+}
@@ -0,0 +1,221 @@
+package poker_test
+
+import (
+	"encoding/json"
+	"github.com/vetch101/go-tddapp"
+	"io/ioutil"
+	"testing"
+)
+
+func TestMigrateDBFile(t *testing.T) {
+
+	steps := []struct {
+		name string
+		from string
+		to   int
+		want string
+	}{
+		{
+			"0 to 1 wraps a bare league array in an envelope",
+			`[{"Name":"Cleo","Wins":10}]`,
+			1,
+			`{"Version":1,"League":[{"Name":"Cleo","Wins":10}]}`,
+		},
+		{
+			"0 to 1 wraps an empty bare league",
+			`[]`,
+			1,
+			`{"Version":1,"League":[]}`,
+		},
+		{
+			"1 to 2 adds an empty history of games",
+			`{"Version":1,"League":[{"Name":"Cleo","Wins":10}]}`,
+			2,
+			`{"Version":2,"League":[{"Name":"Cleo","Wins":10}],"Games":[]}`,
+		},
+		{
+			"2 to 3 counts each win as a game played",
+			`{"Version":2,"League":[{"Name":"Cleo","Wins":10},{"Name":"Chris"}],"Games":[]}`,
+			3,
+			`{"Version":3,"League":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10},{"Name":"Chris","Losses":0,"GamesPlayed":0}],"Games":[]}`,
+		},
+		{
+			"3 to 4 adds an empty table of ratings",
+			`{"Version":3,"League":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10}],"Games":[]}`,
+			4,
+			`{"Version":4,"League":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10}],"Games":[],"Ratings":[]}`,
+		},
+		{
+			"4 to 5 starts the main league off with the all-time league",
+			`{"Version":4,"League":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10}],"Games":[],"Ratings":[]}`,
+			5,
+			`{"Version":5,"League":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10}],"Games":[],"Ratings":[],` +
+				`"Leagues":{"Seasons":[],"Tables":[{"League":"","Season":"","Players":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10}]}]}}`,
+		},
+		{
+			"0 to 5 runs every step",
+			`[{"Name":"Cleo","Wins":10}]`,
+			5,
+			`{"Version":5,"League":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10}],"Games":[],"Ratings":[],` +
+				`"Leagues":{"Seasons":[],"Tables":[{"League":"","Season":"","Players":[{"Name":"Cleo","Wins":10,"Losses":0,"GamesPlayed":10}]}]}}`,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			got := mustMigrate(t, step.from, step.to)
+
+			assertJSONEqual(t, got, step.want)
+		})
+	}
+
+	t.Run("reaches the current version from version 0", func(t *testing.T) {
+		got, err := poker.MigrateDBFile([]byte(`[]`))
+		poker.AssertNoError(t, err)
+
+		var envelope struct{ Version int }
+		json.Unmarshal(got, &envelope)
+
+		if envelope.Version != poker.CurrentDBVersion {
+			t.Errorf("got version %d want %d", envelope.Version, poker.CurrentDBVersion)
+		}
+	})
+
+	t.Run("leaves a current file alone", func(t *testing.T) {
+		current := mustMigrate(t, `[{"Name":"Cleo","Wins":10}]`, poker.CurrentDBVersion)
+
+		got := mustMigrate(t, current, poker.CurrentDBVersion)
+
+		if got != current {
+			t.Errorf("got %s want %s", got, current)
+		}
+	})
+
+	t.Run("refuses a file from a newer version", func(t *testing.T) {
+		_, err := poker.MigrateDBFile([]byte(`{"Version":99,"League":[]}`))
+
+		if err != poker.ErrDBVersion {
+			t.Errorf("got error %v want %v", err, poker.ErrDBVersion)
+		}
+	})
+
+	t.Run("refuses a file that isn't json", func(t *testing.T) {
+		_, err := poker.MigrateDBFile([]byte(`[{"Name":`))
+
+		if err == nil {
+			t.Errorf("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestFileSystemStoreMigration(t *testing.T) {
+
+	t.Run("rewrites a legacy file at the current version on load", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `[{"Name":"Cleo","Wins":10}]`)
+		defer cleanDatabase()
+
+		store, err := poker.OpenFileSystemPlayerStore(poker.OSFileSystem{}, database)
+		poker.AssertNoError(t, err)
+
+		poker.AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 10)
+
+		mainLeague, err := store.GetLeagueTable("", "")
+		poker.AssertNoError(t, err)
+		poker.AssertLeague(t, mainLeague, poker.League{{Name: "Cleo", Wins: 10, GamesPlayed: 10}})
+
+		assertDBFileAtCurrentVersion(t, database, poker.League{{Name: "Cleo", Wins: 10, GamesPlayed: 10}})
+	})
+
+	t.Run("writes new files at the current version", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, "")
+		defer cleanDatabase()
+
+		store, err := poker.OpenFileSystemPlayerStore(poker.OSFileSystem{}, database)
+		poker.AssertNoError(t, err)
+
+		poker.AssertNoError(t, store.PostRecordWin("Cleo"))
+		assertDBFileAtCurrentVersion(t, database, poker.League{{Name: "Cleo", Wins: 1, GamesPlayed: 1}})
+	})
+
+	t.Run("refuses to open a file from a newer version", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Version":99,"League":[]}`)
+		defer cleanDatabase()
+
+		_, err := poker.OpenFileSystemPlayerStore(poker.OSFileSystem{}, database)
+
+		if err != poker.ErrDBVersion {
+			t.Errorf("got error %v want %v", err, poker.ErrDBVersion)
+		}
+
+		_, _, err = poker.StoreFromFile(poker.FileBackend, database)
+
+		if err != poker.ErrDBVersion {
+			t.Errorf("got error %v opening the file backend want %v", err, poker.ErrDBVersion)
+		}
+	})
+
+	t.Run("tells a file it can't read from one from a newer version", func(t *testing.T) {
+		database, cleanDatabase := createTempDB(t, `{"Version":`)
+		defer cleanDatabase()
+
+		_, _, err := poker.FileSystemStoreFromFile(database)
+
+		if err != poker.ErrCreateStore {
+			t.Errorf("got error %v want %v", err, poker.ErrCreateStore)
+		}
+	})
+}
+
+func assertDBFileAtCurrentVersion(t *testing.T, filename string, wantLeague poker.League) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		t.Fatalf("could not read %s %v", filename, err)
+	}
+
+	var envelope struct {
+		Version int
+		League  poker.League
+	}
+
+	err = json.Unmarshal(data, &envelope)
+
+	if err != nil {
+		t.Fatalf("could not decode %s, %v", data, err)
+	}
+
+	if envelope.Version != poker.CurrentDBVersion {
+		t.Errorf("got version %d want %d", envelope.Version, poker.CurrentDBVersion)
+	}
+
+	poker.AssertLeague(t, envelope.League, wantLeague)
+}
+
+func mustMigrate(t *testing.T, data string, to int) string {
+	t.Helper()
+
+	got, err := poker.MigrateDBFileTo([]byte(data), to)
+
+	if err != nil {
+		t.Fatalf("could not migrate %s, %v", data, err)
+	}
+
+	return string(got)
+}
+
+func assertJSONEqual(t *testing.T, got, want string) {
+	t.Helper()
+
+	var gotValue, wantValue interface{}
+	json.Unmarshal([]byte(got), &gotValue)
+	json.Unmarshal([]byte(want), &wantValue)
+
+	gotJSON, _ := json.Marshal(gotValue)
+	wantJSON, _ := json.Marshal(wantValue)
+
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
@@ -0,0 +1,403 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BuyIns are what it costs to enter a tournament, to rebuy and to take an add-on
+type BuyIns struct {
+	BuyIn int
+	Rebuy int
+	AddOn int
+}
+
+// PrizePool is everything paid in by entrants, rebuys and add-ons
+func (b BuyIns) PrizePool(entrants, rebuys, addOns int) int {
+	return entrants*b.BuyIn + rebuys*b.Rebuy + addOns*b.AddOn
+}
+
+// PayoutTier is the percentage of the prize pool paid to each finishing position, best first,
+// of a tournament with up to MaxEntrants, where 0 is any number
+type PayoutTier struct {
+	MaxEntrants int   `json:"max_entrants,omitempty" yaml:"max_entrants,omitempty"`
+	Percentages []int `json:"percentages" yaml:"percentages"`
+}
+
+// PayoutStructure is how a prize pool is paid out, by tiers of the number of entrants from the fewest up
+type PayoutStructure []PayoutTier
+
+// The names of the preset payout structures
+const (
+	StandardPayouts      = "standard"
+	WinnerTakesAllPayout = "winner-takes-all"
+)
+
+var payoutPresets = map[string]PayoutStructure{
+	StandardPayouts: {
+		{MaxEntrants: 4, Percentages: []int{100}},
+		{MaxEntrants: 7, Percentages: []int{65, 35}},
+		{MaxEntrants: 10, Percentages: []int{50, 30, 20}},
+		{MaxEntrants: 20, Percentages: []int{40, 25, 18, 10, 7}},
+		{Percentages: []int{35, 22, 15, 10, 8, 6, 4}},
+	},
+	WinnerTakesAllPayout: {
+		{Percentages: []int{100}},
+	},
+}
+
+// PayoutPreset returns the preset payout structure called name
+func PayoutPreset(name string) (PayoutStructure, error) {
+
+	structure, ok := payoutPresets[name]
+
+	if !ok {
+		return nil, ErrUnknownPayouts
+	}
+
+	return structure, nil
+}
+
+// LoadPayoutStructure reads a payout structure's tiers from a yaml file, if its extension is .yaml or .yml,
+// or otherwise from a json file
+func LoadPayoutStructure(filename string) (PayoutStructure, error) {
+
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return nil, ErrFileOpen
+	}
+
+	var s PayoutStructure
+
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(data, &s)
+	default:
+		err = json.Unmarshal(data, &s)
+	}
+
+	if err != nil {
+		return nil, ErrBadPayouts
+	}
+
+	return s, s.Validate()
+}
+
+// PayoutStructureFrom returns the preset called nameOrFile, or failing that loads the structure from the file it names
+func PayoutStructureFrom(nameOrFile string) (PayoutStructure, error) {
+
+	if preset, err := PayoutPreset(nameOrFile); err == nil {
+		return preset, nil
+	}
+
+	s, err := LoadPayoutStructure(nameOrFile)
+
+	if err == ErrFileOpen {
+		return nil, ErrUnknownPayouts
+	}
+
+	return s, err
+}
+
+// Validate checks that each tier is for more entrants than the one before, with the last for any number,
+// and pays out exactly the prize pool to no more places than there are entrants
+func (s PayoutStructure) Validate() error {
+
+	if len(s) == 0 || s[len(s)-1].MaxEntrants != 0 {
+		return ErrBadPayouts
+	}
+
+	previous := 0
+
+	for i, tier := range s {
+		if i < len(s)-1 && tier.MaxEntrants <= previous {
+			return ErrBadPayouts
+		}
+
+		if i < len(s)-1 && len(tier.Percentages) > tier.MaxEntrants {
+			return ErrBadPayouts
+		}
+
+		total := 0
+
+		for _, percentage := range tier.Percentages {
+			if percentage <= 0 {
+				return ErrBadPayouts
+			}
+			total += percentage
+		}
+
+		if total != 100 {
+			return ErrBadPayouts
+		}
+
+		previous = tier.MaxEntrants
+	}
+
+	return nil
+}
+
+// Percentages are the shares of the prize pool paid to each place of a tournament with entrants
+func (s PayoutStructure) Percentages(entrants int) []int {
+
+	for _, tier := range s {
+		if tier.MaxEntrants == 0 || entrants <= tier.MaxEntrants {
+			return tier.Percentages
+		}
+	}
+
+	return nil
+}
+
+// Payout is what's paid to a finishing position, and the player who finished there once it's known
+type Payout struct {
+	Position int
+	Name     string `json:",omitempty"`
+	Amount   int
+}
+
+// Payouts shares out pool between the places paid in a tournament with entrants. Amounts are
+// rounded down, with what's left over from rounding going to the winner.
+func (s PayoutStructure) Payouts(pool, entrants int) []Payout {
+
+	percentages := s.Percentages(entrants)
+
+	if len(percentages) > entrants {
+		percentages = percentages[:entrants]
+	}
+
+	var payouts []Payout
+	paid := 0
+
+	for i, percentage := range percentages {
+		amount := pool * percentage / 100
+		payouts = append(payouts, Payout{Position: i + 1, Amount: amount})
+		paid += amount
+	}
+
+	if len(payouts) > 0 {
+		payouts[0].Amount += pool - paid
+	}
+
+	return payouts
+}
+
+// PayoutsFor works out the prize pool of a finished tournament from its standings, and what each player is paid
+func (s PayoutStructure) PayoutsFor(buyIns BuyIns, standings []Standing) (int, []Payout) {
+
+	rebuys, addOns := 0, 0
+
+	for _, standing := range standings {
+		rebuys += standing.Rebuys
+		if standing.AddOn {
+			addOns++
+		}
+	}
+
+	pool := buyIns.PrizePool(len(standings), rebuys, addOns)
+	payouts := s.Payouts(pool, len(standings))
+
+	for i := range payouts {
+		for _, standing := range standings {
+			if standing.Position == payouts[i].Position {
+				payouts[i].Name = standing.Name
+			}
+		}
+	}
+
+	return pool, payouts
+}
+
+// PayoutRequest is what a tournament's payouts are worked out from, along with the stacks of the
+// players left if they're chopping what's left of the prize pool between them
+type PayoutRequest struct {
+	Entrants int
+	Rebuys   int
+	AddOns   int
+	BuyIns   BuyIns
+	Chop     []int `json:",omitempty"`
+}
+
+// PayoutSheet is what a tournament pays out, and what each player chopping it is paid, in the order of their stacks
+type PayoutSheet struct {
+	PrizePool int
+	Payouts   []Payout
+	Chop      []int `json:",omitempty"`
+}
+
+// Sheet works out the payouts asked for by request
+func (s PayoutStructure) Sheet(request PayoutRequest) (PayoutSheet, error) {
+
+	if request.Entrants < 1 || request.Rebuys < 0 || request.AddOns < 0 || len(request.Chop) > request.Entrants {
+		return PayoutSheet{}, ErrBadPayoutRequest
+	}
+
+	if costs := request.BuyIns; costs.BuyIn < 0 || costs.Rebuy < 0 || costs.AddOn < 0 {
+		return PayoutSheet{}, ErrBadPayoutRequest
+	}
+
+	pool := request.BuyIns.PrizePool(request.Entrants, request.Rebuys, request.AddOns)
+	sheet := PayoutSheet{PrizePool: pool, Payouts: s.Payouts(pool, request.Entrants)}
+
+	if len(request.Chop) == 0 {
+		return sheet, nil
+	}
+
+	prizes := make([]int, len(request.Chop))
+
+	for i := range prizes {
+		if i < len(sheet.Payouts) {
+			prizes[i] = sheet.Payouts[i].Amount
+		}
+	}
+
+	chop, err := ICMChop(request.Chop, prizes)
+
+	if err != nil {
+		return PayoutSheet{}, err
+	}
+
+	sheet.Chop = chop
+
+	return sheet, nil
+}
+
+// MaxChopPlayers is the most players that ICMChop shares prizes between
+const MaxChopPlayers = 10
+
+// ICMChop shares out prizes, best first, between players with stacks by the independent chip model, which
+// pays each player what they'd win on average going by their chance of finishing in each place when that
+// chance is their share of the chips. Amounts are rounded down, with what's left over from rounding going a
+// chip at a time to whoever lost the most to it.
+func ICMChop(stacks []int, prizes []int) ([]int, error) {
+
+	if len(stacks) < 2 || len(stacks) > MaxChopPlayers {
+		return nil, ErrChopPlayers
+	}
+
+	for _, stack := range stacks {
+		if stack <= 0 {
+			return nil, ErrBadChop
+		}
+	}
+
+	icm := &icmChop{stacks: stacks, prizes: prizes, equities: map[int][]float64{}}
+	equities := icm.equity(1<<uint(len(stacks)) - 1)
+
+	chop := make([]int, len(stacks))
+	remainder := make([]float64, len(stacks))
+	total, paid := 0, 0
+
+	for i := 0; i < len(prizes) && i < len(stacks); i++ {
+		total += prizes[i]
+	}
+
+	for i, equity := range equities {
+		chop[i] = int(math.Floor(equity + 1e-9))
+		remainder[i] = equity - float64(chop[i])
+		paid += chop[i]
+	}
+
+	order := make([]int, len(stacks))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainder[order[i]] > remainder[order[j]]
+	})
+
+	for i := 0; paid < total; i++ {
+		chop[order[i%len(order)]]++
+		paid++
+	}
+
+	return chop, nil
+}
+
+// icmChop works out each player's equity for every set of players who could still be left, remembering them as it goes
+type icmChop struct {
+	stacks   []int
+	prizes   []int
+	equities map[int][]float64
+}
+
+// equity is what each player in left, a bit for each player, can expect to win of the prizes not yet paid
+// to the players who've already finished
+func (c *icmChop) equity(left int) []float64 {
+
+	if equities, ok := c.equities[left]; ok {
+		return equities
+	}
+
+	equities := make([]float64, len(c.stacks))
+	place := len(c.stacks)
+	chips := 0
+
+	for i, stack := range c.stacks {
+		if left&(1<<uint(i)) != 0 {
+			place--
+			chips += stack
+		}
+	}
+
+	if place >= len(c.prizes) || chips == 0 {
+		c.equities[left] = equities
+		return equities
+	}
+
+	for i, stack := range c.stacks {
+		if left&(1<<uint(i)) == 0 {
+			continue
+		}
+
+		chance := float64(stack) / float64(chips)
+		equities[i] += chance * float64(c.prizes[place])
+
+		for j, equity := range c.equity(left &^ (1 << uint(i))) {
+			equities[j] += chance * equity
+		}
+	}
+
+	c.equities[left] = equities
+
+	return equities
+}
+
+// ParseChop reads the stacks of the players chopping a prize pool written as a comma separated list, such as "5000,3000,2000"
+func ParseChop(input string) ([]int, error) {
+
+	var stacks []int
+
+	for _, value := range strings.Split(input, ",") {
+		stack, err := strconv.Atoi(strings.TrimSpace(value))
+
+		if err != nil {
+			return nil, ErrBadChop
+		}
+
+		stacks = append(stacks, stack)
+	}
+
+	return stacks, nil
+}
+
+// PrintPayouts writes the amount paid to each finishing position, and who finished there if it's known, one per line
+func PrintPayouts(out io.Writer, payouts []Payout) {
+	for _, payout := range payouts {
+		if payout.Name == "" {
+			fmt.Fprintf(out, "%s %d\n", ordinal(payout.Position), payout.Amount)
+		} else {
+			fmt.Fprintf(out, "%s %s %d\n", ordinal(payout.Position), payout.Name, payout.Amount)
+		}
+	}
+}
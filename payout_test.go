@@ -0,0 +1,296 @@
+package poker_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/vetch101/go-tddapp"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPayoutStructure(t *testing.T) {
+
+	t.Run("the presets are valid", func(t *testing.T) {
+		for _, name := range []string{poker.StandardPayouts, poker.WinnerTakesAllPayout} {
+			structure, err := poker.PayoutPreset(name)
+			poker.AssertNoError(t, err)
+			poker.AssertNoError(t, structure.Validate())
+		}
+
+		if _, err := poker.PayoutPreset("everyone-wins"); err != poker.ErrUnknownPayouts {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownPayouts)
+		}
+	})
+
+	t.Run("pays more places as more players enter", func(t *testing.T) {
+		structure, _ := poker.PayoutPreset(poker.StandardPayouts)
+
+		cases := map[int]int{2: 1, 4: 1, 5: 2, 10: 3, 11: 5, 100: 7}
+
+		for entrants, want := range cases {
+			if got := len(structure.Percentages(entrants)); got != want {
+				t.Errorf("got %d places paid for %d entrants want %d", got, entrants, want)
+			}
+		}
+	})
+
+	cases := []struct {
+		name      string
+		structure poker.PayoutStructure
+	}{
+		{"no tiers", poker.PayoutStructure{}},
+		{"not adding up to 100", poker.PayoutStructure{{Percentages: []int{60, 30}}}},
+		{"a negative percentage", poker.PayoutStructure{{Percentages: []int{110, -10}}}},
+		{"no tier for any number", poker.PayoutStructure{{MaxEntrants: 10, Percentages: []int{100}}}},
+		{"tiers out of order", poker.PayoutStructure{
+			{MaxEntrants: 10, Percentages: []int{100}},
+			{MaxEntrants: 5, Percentages: []int{100}},
+			{Percentages: []int{100}},
+		}},
+		{"more places than entrants", poker.PayoutStructure{
+			{MaxEntrants: 2, Percentages: []int{50, 30, 20}},
+			{Percentages: []int{100}},
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run("rejects "+c.name, func(t *testing.T) {
+			if err := c.structure.Validate(); err != poker.ErrBadPayouts {
+				t.Errorf("got error %v want %v", err, poker.ErrBadPayouts)
+			}
+		})
+	}
+}
+
+func TestLoadPayoutStructure(t *testing.T) {
+
+	want := poker.PayoutStructure{
+		{MaxEntrants: 6, Percentages: []int{70, 30}},
+		{Percentages: []int{50, 30, 20}},
+	}
+
+	t.Run("from json", func(t *testing.T) {
+		filename, clean := createBlindsFile(t, "payouts.json", `[
+			{"max_entrants": 6, "percentages": [70, 30]},
+			{"percentages": [50, 30, 20]}
+		]`)
+		defer clean()
+
+		got, err := poker.PayoutStructureFrom(filename)
+		poker.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("from yaml", func(t *testing.T) {
+		filename, clean := createBlindsFile(t, "payouts.yaml", `
+- {max_entrants: 6, percentages: [70, 30]}
+- {percentages: [50, 30, 20]}
+`)
+		defer clean()
+
+		got, err := poker.LoadPayoutStructure(filename)
+		poker.AssertNoError(t, err)
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("rejects structures that don't pay out the prize pool", func(t *testing.T) {
+		filename, clean := createBlindsFile(t, "payouts.json", `[{"percentages": [70, 20]}]`)
+		defer clean()
+
+		if _, err := poker.LoadPayoutStructure(filename); err != poker.ErrBadPayouts {
+			t.Errorf("got error %v want %v", err, poker.ErrBadPayouts)
+		}
+	})
+
+	t.Run("neither a preset nor a file", func(t *testing.T) {
+		if _, err := poker.PayoutStructureFrom("everyone-wins"); err != poker.ErrUnknownPayouts {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownPayouts)
+		}
+	})
+}
+
+func TestPayouts(t *testing.T) {
+
+	structure, _ := poker.PayoutPreset(poker.StandardPayouts)
+
+	t.Run("shares the prize pool by the percentages", func(t *testing.T) {
+		got := structure.Payouts(1000, 10)
+		want := []poker.Payout{{Position: 1, Amount: 500}, {Position: 2, Amount: 300}, {Position: 3, Amount: 200}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("gives what's left from rounding down to the winner", func(t *testing.T) {
+		got := structure.Payouts(999, 9)
+		want := []poker.Payout{{Position: 1, Amount: 501}, {Position: 2, Amount: 299}, {Position: 3, Amount: 199}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("pays buy-ins, rebuys and add-ons to the players by where they finished", func(t *testing.T) {
+		buyIns := poker.BuyIns{BuyIn: 100, Rebuy: 50, AddOn: 25}
+		standings := []poker.Standing{
+			{Name: "Ruth", Position: 1, AddOn: true},
+			{Name: "Chris", Position: 2, Rebuys: 2},
+			{Name: "Cleo", Position: 3},
+			{Name: "Sam", Position: 4},
+			{Name: "Alex", Position: 5, AddOn: true},
+		}
+
+		pool, got := structure.PayoutsFor(buyIns, standings)
+
+		if pool != 650 {
+			t.Errorf("got a prize pool of %d want 650", pool)
+		}
+
+		want := []poker.Payout{{Position: 1, Name: "Ruth", Amount: 423}, {Position: 2, Name: "Chris", Amount: 227}}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+}
+
+func TestICMChop(t *testing.T) {
+
+	t.Run("pays bigger stacks more, sharing out every prize", func(t *testing.T) {
+		got, err := poker.ICMChop([]int{5000, 3000, 2000}, []int{500, 300, 200})
+		poker.AssertNoError(t, err)
+
+		if want := []int{384, 327, 289}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("splits evenly between even stacks", func(t *testing.T) {
+		got, err := poker.ICMChop([]int{1000, 1000}, []int{700, 300})
+		poker.AssertNoError(t, err)
+
+		if want := []int{500, 500}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("needs between 2 and 10 players with chips", func(t *testing.T) {
+		if _, err := poker.ICMChop([]int{1000}, []int{100}); err != poker.ErrChopPlayers {
+			t.Errorf("got error %v want %v", err, poker.ErrChopPlayers)
+		}
+
+		if _, err := poker.ICMChop(make([]int, 11), []int{100}); err != poker.ErrChopPlayers {
+			t.Errorf("got error %v want %v", err, poker.ErrChopPlayers)
+		}
+
+		if _, err := poker.ICMChop([]int{1000, 0}, []int{100}); err != poker.ErrBadChop {
+			t.Errorf("got error %v want %v", err, poker.ErrBadChop)
+		}
+	})
+}
+
+func TestPayoutSheet(t *testing.T) {
+
+	structure, _ := poker.PayoutPreset(poker.StandardPayouts)
+
+	t.Run("chops the prizes of the places the players left could finish in", func(t *testing.T) {
+		sheet, err := structure.Sheet(poker.PayoutRequest{
+			Entrants: 8,
+			Rebuys:   2,
+			BuyIns:   poker.BuyIns{BuyIn: 100, Rebuy: 100},
+			Chop:     []int{5000, 3000, 2000},
+		})
+		poker.AssertNoError(t, err)
+
+		if sheet.PrizePool != 1000 || !reflect.DeepEqual(sheet.Chop, []int{384, 327, 289}) {
+			t.Errorf("got %+v want a prize pool of 1000 chopped [384 327 289]", sheet)
+		}
+	})
+
+	for name, request := range map[string]poker.PayoutRequest{
+		"no entrants":                         {},
+		"negative rebuys":                     {Entrants: 5, Rebuys: -1},
+		"a negative buy-in":                   {Entrants: 5, BuyIns: poker.BuyIns{BuyIn: -100}},
+		"a negative rebuy or add-on":          {Entrants: 5, Rebuys: 1, AddOns: 1, BuyIns: poker.BuyIns{BuyIn: 100, Rebuy: -100, AddOn: -50}},
+		"more players chopping than entrants": {Entrants: 2, Chop: []int{10, 10, 10}},
+	} {
+		t.Run("rejects "+name, func(t *testing.T) {
+			if _, err := structure.Sheet(request); err != poker.ErrBadPayoutRequest {
+				t.Errorf("got error %v want %v", err, poker.ErrBadPayoutRequest)
+			}
+		})
+	}
+}
+
+func TestGETPayouts(t *testing.T) {
+
+	server := mustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+	t.Run("returns the payouts and chop", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGamesRequest(http.MethodGet, "/payouts?entrants=10&buy_in=100&chop=5000,3000,2000"))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertContentType(t, response.Header().Get("content-type"), jsonContentType)
+
+		var got poker.PayoutSheet
+		poker.AssertNoError(t, json.NewDecoder(response.Body).Decode(&got))
+
+		want := poker.PayoutSheet{
+			PrizePool: 1000,
+			Payouts:   []poker.Payout{{Position: 1, Amount: 500}, {Position: 2, Amount: 300}, {Position: 3, Amount: 200}},
+			Chop:      []int{384, 327, 289},
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	for _, query := range []string{"entrants=0", "entrants=ten", "entrants=5&structure=everyone-wins", "entrants=5&chop=lots,few"} {
+		t.Run("returns 400 for "+query, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newGamesRequest(http.MethodGet, "/payouts?"+query))
+
+			poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+}
+
+func TestGame_Payouts(t *testing.T) {
+	store := &poker.StubPlayerStore{}
+	game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+	game.SetRenderer(poker.TextRenderer)
+	game.SetTournament(poker.TournamentRules{RebuyLevels: 1})
+	structure, _ := poker.PayoutPreset(poker.StandardPayouts)
+	game.SetPayouts(poker.BuyIns{BuyIn: 100, Rebuy: 50}, structure)
+	out := &bytes.Buffer{}
+
+	in := userSends("Ruth,Chris,Cleo", "Chris out", "rebuy Chris", "Cleo out", "Chris out")
+	poker.NewCLI(in, out, game).PlayPoker()
+
+	if len(store.Games) != 1 {
+		t.Fatalf("got %d games recorded want 1", len(store.Games))
+	}
+
+	record := store.Games[0]
+	want := []poker.Payout{{Position: 1, Name: "Ruth", Amount: 350}}
+
+	if record.PrizePool != 350 || !reflect.DeepEqual(record.Payouts, want) {
+		t.Errorf("got a prize pool of %d paid %+v want 350 paid %+v", record.PrizePool, record.Payouts, want)
+	}
+
+	if wantOut := "prize pool 350\n1st Ruth 350\n"; !strings.HasSuffix(out.String(), wantOut) {
+		t.Errorf("got %q want it to end %q", out.String(), wantOut)
+	}
+}
@@ -0,0 +1,425 @@
+package poker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// PlayerStoreFactory opens the PlayerStore kept in filename, returning it with its close func.
+// Opening the same filename again after closing must give back what was stored.
+type PlayerStoreFactory func(t *testing.T, filename string) (PlayerStore, func())
+
+// RunPlayerStoreSuite checks that the PlayerStore made by factory behaves like every other backend
+func RunPlayerStoreSuite(t *testing.T, factory PlayerStoreFactory) {
+
+	t.Run("game history", func(t *testing.T) {
+		runGameHistorySuite(t, factory)
+	})
+
+	t.Run("leagues and seasons", func(t *testing.T) {
+		runSeasonSuite(t, factory)
+	})
+
+	t.Run("unknown players score 0", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		AssertScoreEquals(t, store.GetPlayerScore("Apollo"), 0)
+		AssertLeague(t, store.GetLeague(), League{})
+	})
+
+	t.Run("wins increment a player's score", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		AssertNoError(t, store.PostRecordWin("Pepper"))
+		AssertScoreEquals(t, store.GetPlayerScore("Pepper"), 1)
+
+		AssertNoError(t, store.PostRecordWin("Pepper"))
+		AssertScoreEquals(t, store.GetPlayerScore("Pepper"), 2)
+	})
+
+	t.Run("league is ordered by wins", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordWins(t, store, "Cleo", "Chris", "Chris", "Chris", "Trevor", "Trevor")
+
+		want := League{
+			{Name: "Chris", Wins: 3, GamesPlayed: 3},
+			{Name: "Trevor", Wins: 2, GamesPlayed: 2},
+			{Name: "Cleo", Wins: 1, GamesPlayed: 1},
+		}
+		AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("ties are ordered by name", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordWins(t, store, "Trevor", "Cleo", "Chris", "Cleo", "Trevor")
+
+		want := League{
+			{Name: "Cleo", Wins: 2, GamesPlayed: 2},
+			{Name: "Trevor", Wins: 2, GamesPlayed: 2},
+			{Name: "Chris", Wins: 1, GamesPlayed: 1},
+		}
+		AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("league can be changed by the caller without changing the store", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordWins(t, store, "Cleo", "Chris")
+
+		league := store.GetLeague()
+		league[0].Wins = 100
+
+		AssertLeague(t, store.GetLeague(), League{{Name: "Chris", Wins: 1, GamesPlayed: 1}, {Name: "Cleo", Wins: 1, GamesPlayed: 1}})
+	})
+
+	t.Run("wins persist across a reopen", func(t *testing.T) {
+		filename, clean := suiteDBFile(t)
+		defer clean()
+
+		store, closeStore := factory(t, filename)
+		recordWins(t, store, "Cleo", "Chris", "Chris")
+		closeStore()
+
+		store, closeStore = factory(t, filename)
+		defer closeStore()
+
+		AssertScoreEquals(t, store.GetPlayerScore("Chris"), 2)
+		AssertLeague(t, store.GetLeague(), League{{Name: "Chris", Wins: 2, GamesPlayed: 2}, {Name: "Cleo", Wins: 1, GamesPlayed: 1}})
+	})
+
+	t.Run("concurrent wins are not lost", func(t *testing.T) {
+		filename, clean := suiteDBFile(t)
+		defer clean()
+
+		store, closeStore := factory(t, filename)
+
+		players := []string{"Pepper", "Bob", "Cleo"}
+		winsPerPlayer := 20
+
+		var wg sync.WaitGroup
+
+		for _, player := range players {
+			for i := 0; i < winsPerPlayer; i++ {
+				wg.Add(2)
+				go func(player string) {
+					defer wg.Done()
+					if err := store.PostRecordWin(player); err != nil {
+						t.Errorf("didn't expect error but got one, %v", err)
+					}
+				}(player)
+				go func(player string) {
+					defer wg.Done()
+					store.GetPlayerScore(player)
+					store.GetLeague()
+				}(player)
+			}
+		}
+
+		wg.Wait()
+
+		for _, player := range players {
+			AssertScoreEquals(t, store.GetPlayerScore(player), winsPerPlayer)
+		}
+
+		closeStore()
+
+		store, closeStore = factory(t, filename)
+		defer closeStore()
+
+		for _, player := range players {
+			AssertScoreEquals(t, store.GetPlayerScore(player), winsPerPlayer)
+		}
+	})
+}
+
+// runGameHistorySuite checks that the PlayerStore made by factory records and queries games
+// like every other backend
+func runGameHistorySuite(t *testing.T, factory PlayerStoreFactory) {
+
+	day := time.Date(2019, time.July, 1, 20, 0, 0, 0, time.UTC)
+
+	games := []GameRecord{
+		{ID: "3", Started: day.Add(48 * time.Hour), Finished: day.Add(49 * time.Hour), NumberOfPlayers: 2, Players: []string{"Cleo", "Chris"}, Winner: "Chris", BlindLevel: 3, Blind: 300},
+		{ID: "1", Started: day, Finished: day.Add(time.Hour), NumberOfPlayers: 3, Players: []string{"Cleo", "Chris", "Trevor"}, Winner: "Cleo", BlindLevel: 2, Blind: 200},
+		{ID: "2", Started: day.Add(24 * time.Hour), Finished: day.Add(25 * time.Hour), NumberOfPlayers: 5, Winner: "Trevor", BlindLevel: 1, Blind: 100},
+	}
+
+	t.Run("no games to start with", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		AssertGames(t, store.GetGames(GameQuery{}), []GameRecord{})
+	})
+
+	t.Run("games are returned in the order they started", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordGames(t, store, games...)
+
+		AssertGames(t, store.GetGames(GameQuery{}), []GameRecord{games[1], games[2], games[0]})
+	})
+
+	t.Run("games can be queried by player", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordGames(t, store, games...)
+
+		AssertGames(t, store.GetGames(GameQuery{Player: "Trevor"}), []GameRecord{games[1], games[2]})
+		AssertGames(t, store.GetGames(GameQuery{Player: "Apollo"}), []GameRecord{})
+	})
+
+	t.Run("games can be queried by date range", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordGames(t, store, games...)
+
+		query := GameQuery{From: day.Add(time.Hour), To: day.Add(48 * time.Hour)}
+		AssertGames(t, store.GetGames(query), []GameRecord{games[2], games[0]})
+
+		query = GameQuery{Player: "Cleo", To: day.Add(24 * time.Hour)}
+		AssertGames(t, store.GetGames(query), []GameRecord{games[1]})
+	})
+
+	t.Run("games count for everyone who played", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordWins(t, store, "Chris")
+		recordGames(t, store, games...)
+
+		want := League{
+			{Name: "Chris", Wins: 2, Losses: 1, GamesPlayed: 3},
+			{Name: "Cleo", Wins: 1, Losses: 1, GamesPlayed: 2},
+			{Name: "Trevor", Wins: 1, Losses: 1, GamesPlayed: 2},
+		}
+		AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("games persist across a reopen", func(t *testing.T) {
+		filename, clean := suiteDBFile(t)
+		defer clean()
+
+		store, closeStore := factory(t, filename)
+		recordWins(t, store, "Cleo")
+		recordGames(t, store, games...)
+		closeStore()
+
+		store, closeStore = factory(t, filename)
+		defer closeStore()
+
+		AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 2)
+		AssertGames(t, store.GetGames(GameQuery{}), []GameRecord{games[1], games[2], games[0]})
+	})
+
+	t.Run("games rate everyone who played", func(t *testing.T) {
+		filename, clean := suiteDBFile(t)
+		defer clean()
+
+		store, closeStore := factory(t, filename)
+		AssertRatings(t, store.GetRatings(), Ratings{})
+
+		recordWins(t, store, "Apollo")
+		recordGames(t, store, games[1], games[2])
+
+		// a bare win and a game with only its winner known aren't rated
+		want := Ratings{
+			{Name: "Cleo", Rating: 1516, Games: 1},
+			{Name: "Chris", Rating: 1492, Games: 1},
+			{Name: "Trevor", Rating: 1492, Games: 1},
+		}
+		AssertRatings(t, store.GetRatings(), want)
+		closeStore()
+
+		store, closeStore = factory(t, filename)
+		defer closeStore()
+
+		AssertRatings(t, store.GetRatings(), want)
+	})
+}
+
+// runSeasonSuite checks that the PlayerStore made by factory keeps leagues and seasons
+// like every other backend
+func runSeasonSuite(t *testing.T, factory PlayerStoreFactory) {
+
+	t.Run("only the main league exists to start with", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		table, err := store.GetLeagueTable("", "")
+		AssertNoError(t, err)
+		AssertLeague(t, table, League{})
+
+		_, err = store.GetLeagueTable("office", "")
+		assertSuiteError(t, err, ErrUnknownLeague)
+	})
+
+	t.Run("wins go into the open season and the all-time league", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		AssertNoError(t, store.StartSeason("", "2026Q3"))
+		recordWins(t, store, "Cleo", "Chris")
+		AssertNoError(t, store.RecordLeagueWin("", "2026Q3", "Cleo"))
+		AssertNoError(t, store.CloseSeason("", "2026Q3"))
+		recordWins(t, store, "Cleo")
+
+		season, err := store.GetLeagueTable("", "2026Q3")
+		AssertNoError(t, err)
+		AssertLeague(t, season, League{{Name: "Cleo", Wins: 2, GamesPlayed: 2}, {Name: "Chris", Wins: 1, GamesPlayed: 1}})
+
+		totals, err := store.GetLeagueTable("", "")
+		AssertNoError(t, err)
+		AssertLeague(t, totals, League{{Name: "Cleo", Wins: 3, GamesPlayed: 3}, {Name: "Chris", Wins: 1, GamesPlayed: 1}})
+		AssertLeague(t, store.GetLeague(), totals)
+	})
+
+	t.Run("named leagues are kept apart from the main league", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		recordWins(t, store, "Cleo")
+		AssertNoError(t, store.RecordLeagueWin("office", "", "Trevor"))
+
+		office, err := store.GetLeagueTable("office", "")
+		AssertNoError(t, err)
+		AssertLeague(t, office, League{{Name: "Trevor", Wins: 1, GamesPlayed: 1}})
+
+		mainLeague, err := store.GetLeagueTable("", "")
+		AssertNoError(t, err)
+		AssertLeague(t, mainLeague, League{{Name: "Cleo", Wins: 1, GamesPlayed: 1}})
+
+		AssertLeague(t, store.GetLeague(), League{{Name: "Cleo", Wins: 1, GamesPlayed: 1}, {Name: "Trevor", Wins: 1, GamesPlayed: 1}})
+	})
+
+	t.Run("games go into the open season of their league", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		AssertNoError(t, store.StartSeason("office", "spring"))
+		recordGames(t, store, GameRecord{ID: "1", NumberOfPlayers: 2, Players: []string{"Cleo", "Chris"}, Winner: "Cleo", League: "office"})
+
+		spring, err := store.GetLeagueTable("office", "spring")
+		AssertNoError(t, err)
+		AssertLeague(t, spring, League{{Name: "Cleo", Wins: 1, GamesPlayed: 1}, {Name: "Chris", Losses: 1, GamesPlayed: 1}})
+
+		games := store.GetGames(GameQuery{})
+		if len(games) != 1 || games[0].Season != "spring" {
+			t.Errorf("got games %+v, want one game in season spring", games)
+		}
+	})
+
+	t.Run("seasons can't be started or played in twice", func(t *testing.T) {
+		store, closeStore := openSuiteStore(t, factory)
+		defer closeStore()
+
+		assertSuiteError(t, store.StartSeason("", ""), ErrSeasonName)
+		AssertNoError(t, store.StartSeason("", "2026Q3"))
+		assertSuiteError(t, store.StartSeason("", "2026Q4"), ErrSeasonOpen)
+		AssertNoError(t, store.CloseSeason("", "2026Q3"))
+		assertSuiteError(t, store.StartSeason("", "2026Q3"), ErrSeasonExists)
+		assertSuiteError(t, store.CloseSeason("", "2026Q3"), ErrSeasonClosed)
+		assertSuiteError(t, store.CloseSeason("", "2026Q4"), ErrUnknownSeason)
+		assertSuiteError(t, store.RecordLeagueWin("", "2026Q3", "Cleo"), ErrSeasonClosed)
+		assertSuiteError(t, store.RecordGame(GameRecord{ID: "1", Winner: "Cleo", Season: "2026Q4"}), ErrUnknownSeason)
+
+		_, err := store.GetLeagueTable("", "2026Q4")
+		assertSuiteError(t, err, ErrUnknownSeason)
+
+		AssertScoreEquals(t, store.GetPlayerScore("Cleo"), 0)
+	})
+
+	t.Run("seasons persist across a reopen", func(t *testing.T) {
+		filename, clean := suiteDBFile(t)
+		defer clean()
+
+		store, closeStore := factory(t, filename)
+		AssertNoError(t, store.StartSeason("office", "spring"))
+		AssertNoError(t, store.RecordLeagueWin("office", "", "Cleo"))
+		AssertNoError(t, store.CloseSeason("office", "spring"))
+		AssertNoError(t, store.StartSeason("office", "summer"))
+		closeStore()
+
+		store, closeStore = factory(t, filename)
+		defer closeStore()
+
+		seasons := store.GetSeasons("office")
+
+		if len(seasons) != 2 || seasons[0].Name != "spring" || seasons[0].Open() || seasons[1].Name != "summer" || !seasons[1].Open() {
+			t.Errorf("got seasons %+v, want spring closed and summer open", seasons)
+		}
+
+		spring, err := store.GetLeagueTable("office", "spring")
+		AssertNoError(t, err)
+		AssertLeague(t, spring, League{{Name: "Cleo", Wins: 1, GamesPlayed: 1}})
+	})
+}
+
+func assertSuiteError(t *testing.T, got, want error) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got error %v want %v", got, want)
+	}
+}
+
+func openSuiteStore(t *testing.T, factory PlayerStoreFactory) (PlayerStore, func()) {
+	t.Helper()
+
+	filename, clean := suiteDBFile(t)
+	store, closeStore := factory(t, filename)
+
+	return store, func() {
+		closeStore()
+		clean()
+	}
+}
+
+// suiteDBFile names a db file in a directory of its own, which is removed by the returned func
+func suiteDBFile(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "store")
+
+	if err != nil {
+		t.Fatalf("could not create temp dir %v", err)
+	}
+
+	return filepath.Join(dir, "game.db"), func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func recordGames(t *testing.T, store PlayerStore, games ...GameRecord) {
+	t.Helper()
+
+	for _, game := range games {
+		err := store.RecordGame(game)
+		if err != nil {
+			t.Fatalf("could not record game %s, %v", game.ID, err)
+		}
+	}
+}
+
+func recordWins(t *testing.T, store PlayerStore, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		err := store.PostRecordWin(name)
+		if err != nil {
+			t.Fatalf("could not record win for %s, %v", name, err)
+		}
+	}
+}
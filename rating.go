@@ -0,0 +1,131 @@
+package poker
+
+import (
+	"math"
+	"sort"
+)
+
+// InitialRating is the rating a player starts on before their first rated game
+const InitialRating = 1500
+
+// Rating is a player's skill rating and the number of rated games it's based on
+type Rating struct {
+	Name   string
+	Rating float64
+	Games  int
+}
+
+// Ratings is an array of Ratings
+type Ratings []Rating
+
+// RatingEngine updates the ratings of everyone who played in a finished game
+type RatingEngine interface {
+	Rate(ratings Ratings, game GameRecord) Ratings
+}
+
+// Elo is a multi-player Elo RatingEngine. Every pair of players in a game is scored as a
+// two player match, the winner beating each of the others and the others drawing among
+// themselves, or in a tournament whoever finished higher winning, with K shared out so
+// that a game moves ratings as much as a single match.
+type Elo struct {
+	K float64
+}
+
+// DefaultRatingEngine is the RatingEngine the stores rate games with
+var DefaultRatingEngine RatingEngine = Elo{K: 32}
+
+// Rate updates ratings in place for a game (adding players who are new) and returns them,
+// like the result of append. Games with fewer than two known players aren't rated.
+func (e Elo) Rate(ratings Ratings, game GameRecord) Ratings {
+
+	players := game.participants()
+
+	if len(players) < 2 {
+		return ratings
+	}
+
+	before := make([]float64, len(players))
+
+	for i, name := range players {
+		var rating *Rating
+		ratings, rating = ratings.findOrAdd(name)
+		before[i] = rating.Rating
+	}
+
+	k := e.K / float64(len(players)-1)
+
+	for i, name := range players {
+		change := 0.0
+
+		for j, opponent := range players {
+			if i == j {
+				continue
+			}
+			change += k * (game.score(name, opponent) - expectedScore(before[i], before[j]))
+		}
+
+		rating := ratings.Find(name)
+		rating.Rating = before[i] + change
+		rating.Games++
+	}
+
+	return ratings
+}
+
+// expectedScore is the chance of a player rated a beating a player rated b
+func expectedScore(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// score is what player earned against opponent in a game won by winner
+func score(player, opponent, winner string) float64 {
+	switch winner {
+	case player:
+		return 1
+	case opponent:
+		return 0
+	}
+	return 0.5
+}
+
+// Find finds and returns a Rating
+func (r Ratings) Find(name string) *Rating {
+	for i, rating := range r {
+		if rating.Name == name {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// findOrAdd returns the Ratings and the named Rating, which starts at InitialRating if they're new
+func (r Ratings) findOrAdd(name string) (Ratings, *Rating) {
+	rating := r.Find(name)
+
+	if rating != nil {
+		return r, rating
+	}
+
+	r = append(r, Rating{Name: name, Rating: InitialRating})
+	return r, &r[len(r)-1]
+}
+
+// copyRatings returns Ratings that can be changed without affecting r
+func (r Ratings) copyRatings() Ratings {
+	ratings := make(Ratings, len(r))
+	copy(ratings, r)
+	return ratings
+}
+
+// sorted returns a copy of the Ratings from highest to lowest, with ties ordered by name
+func (r Ratings) sorted() Ratings {
+	ratings := r.copyRatings()
+
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].Name < ratings[j].Name
+	})
+	return ratings
+}
@@ -0,0 +1,126 @@
+package poker_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/vetch101/go-tddapp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestElo(t *testing.T) {
+
+	elo := poker.Elo{K: 32}
+
+	game := func(winner string, players ...string) poker.GameRecord {
+		return poker.GameRecord{NumberOfPlayers: len(players), Players: players, Winner: winner}
+	}
+
+	cases := []struct {
+		name  string
+		games []poker.GameRecord
+		want  poker.Ratings
+	}{
+		{
+			"even two player game",
+			[]poker.GameRecord{game("Cleo", "Cleo", "Chris")},
+			poker.Ratings{{Name: "Cleo", Rating: 1516, Games: 1}, {Name: "Chris", Rating: 1484, Games: 1}},
+		},
+		{
+			"the favourite gains less for winning again",
+			[]poker.GameRecord{game("Cleo", "Cleo", "Chris"), game("Cleo", "Cleo", "Chris")},
+			poker.Ratings{{Name: "Cleo", Rating: 1530.53, Games: 2}, {Name: "Chris", Rating: 1469.47, Games: 2}},
+		},
+		{
+			"the underdog gains more for an upset",
+			[]poker.GameRecord{game("Cleo", "Cleo", "Chris"), game("Chris", "Cleo", "Chris")},
+			poker.Ratings{{Name: "Cleo", Rating: 1498.53, Games: 2}, {Name: "Chris", Rating: 1501.47, Games: 2}},
+		},
+		{
+			"even three player game shares out the losses",
+			[]poker.GameRecord{game("Cleo", "Cleo", "Chris", "Trevor")},
+			poker.Ratings{{Name: "Cleo", Rating: 1516, Games: 1}, {Name: "Chris", Rating: 1492, Games: 1}, {Name: "Trevor", Rating: 1492, Games: 1}},
+		},
+		{
+			"a winner missing from the players still plays",
+			[]poker.GameRecord{game("Cleo", "Chris")},
+			poker.Ratings{{Name: "Chris", Rating: 1484, Games: 1}, {Name: "Cleo", Rating: 1516, Games: 1}},
+		},
+		{
+			"a player named twice is rated once",
+			[]poker.GameRecord{game("Cleo", "Cleo", "Chris", "Cleo")},
+			poker.Ratings{{Name: "Cleo", Rating: 1516, Games: 1}, {Name: "Chris", Rating: 1484, Games: 1}},
+		},
+		{
+			"a game without a winner has no nameless player",
+			[]poker.GameRecord{game("", "Cleo", "Chris")},
+			poker.Ratings{{Name: "Cleo", Rating: 1500, Games: 1}, {Name: "Chris", Rating: 1500, Games: 1}},
+		},
+		{
+			"a tournament rates players by where they finished",
+			[]poker.GameRecord{{
+				Players:   []string{"Trevor", "Chris", "Cleo"},
+				Winner:    "Cleo",
+				Standings: []poker.Standing{{Name: "Cleo", Position: 1}, {Name: "Chris", Position: 2}, {Name: "Trevor", Position: 3}},
+			}},
+			poker.Ratings{{Name: "Trevor", Rating: 1484, Games: 1}, {Name: "Chris", Rating: 1500, Games: 1}, {Name: "Cleo", Rating: 1516, Games: 1}},
+		},
+		{
+			"a game with only its winner known isn't rated",
+			[]poker.GameRecord{game("Cleo")},
+			poker.Ratings{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ratings := poker.Ratings{}
+
+			for _, g := range c.games {
+				ratings = elo.Rate(ratings, g)
+			}
+
+			poker.AssertRatings(t, ratings, c.want)
+		})
+	}
+}
+
+func TestGETRatings(t *testing.T) {
+
+	ratings := poker.Ratings{
+		{Name: "Cleo", Rating: 1516, Games: 1},
+		{Name: "Chris", Rating: 1484, Games: 1},
+	}
+
+	store := &poker.StubPlayerStore{Ratings: ratings}
+	server := mustMakePlayerServer(t, store, dummyGame)
+
+	request, _ := http.NewRequest(http.MethodGet, "/ratings", nil)
+	response := httptest.NewRecorder()
+
+	server.ServeHTTP(response, request)
+
+	var got poker.Ratings
+	err := json.NewDecoder(response.Body).Decode(&got)
+
+	if err != nil {
+		t.Fatalf("unable to parse response from server %q into Ratings, '%v'", response.Body, err)
+	}
+
+	poker.AssertStatus(t, response.Code, http.StatusOK)
+	poker.AssertContentType(t, response.Result().Header.Get("content-type"), jsonContentType)
+	poker.AssertRatings(t, got, ratings)
+}
+
+func TestPrintRatings(t *testing.T) {
+
+	out := &bytes.Buffer{}
+
+	poker.PrintRatings(out, poker.Ratings{
+		{Name: "Cleo", Rating: 1530.53, Games: 2},
+		{Name: "Chris", Rating: 1469.47, Games: 2},
+	})
+
+	poker.AssertResponseBody(t, out.String(), "Cleo 1531 (2 games)\nChris 1469 (2 games)\n")
+}
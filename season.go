@@ -0,0 +1,221 @@
+package poker
+
+import (
+	"sort"
+	"time"
+)
+
+// Season is a stretch of play in a league, such as a quarter, whose wins are tallied in a table of their own
+type Season struct {
+	League  string
+	Name    string
+	Started time.Time
+	Closed  time.Time
+}
+
+// Open reports whether wins are still being recorded in the Season
+func (s Season) Open() bool {
+	return s.Closed.IsZero()
+}
+
+// LeagueTable is the League of a single season of a league.
+// Wins recorded while the league has no open season go in a table with no Season.
+type LeagueTable struct {
+	League  string
+	Season  string
+	Players League
+}
+
+// Leagues is the seasons and tables of every league. The main league, which
+// wins are recorded in unless another is named, is the one without a name.
+type Leagues struct {
+	Seasons []Season
+	Tables  []LeagueTable
+}
+
+// newLeagues returns Leagues whose main league starts with the wins in league
+func newLeagues(league League) Leagues {
+	return Leagues{
+		Seasons: []Season{},
+		Tables:  []LeagueTable{{Players: league.copyLeague()}},
+	}
+}
+
+// copyLeagues returns Leagues that can be changed without affecting l
+func (l Leagues) copyLeagues() Leagues {
+	leagues := Leagues{
+		Seasons: make([]Season, len(l.Seasons)),
+		Tables:  make([]LeagueTable, len(l.Tables)),
+	}
+
+	copy(leagues.Seasons, l.Seasons)
+
+	for i, table := range l.Tables {
+		table.Players = table.Players.copyLeague()
+		leagues.Tables[i] = table
+	}
+
+	return leagues
+}
+
+// findSeason finds and returns the named Season of league
+func (l Leagues) findSeason(league, name string) *Season {
+	for i, season := range l.Seasons {
+		if season.League == league && season.Name == name {
+			return &l.Seasons[i]
+		}
+	}
+	return nil
+}
+
+// openSeason returns the Season of league that is open, if there is one
+func (l Leagues) openSeason(league string) *Season {
+	for i, season := range l.Seasons {
+		if season.League == league && season.Open() {
+			return &l.Seasons[i]
+		}
+	}
+	return nil
+}
+
+// startSeason adds an open Season in place like League.recordWin. A league can only have one season
+// open at a time, so the last one has to be closed first.
+func (l Leagues) startSeason(season Season) (Leagues, error) {
+
+	if season.Name == "" {
+		return l, ErrSeasonName
+	}
+
+	if l.findSeason(season.League, season.Name) != nil {
+		return l, ErrSeasonExists
+	}
+
+	if l.openSeason(season.League) != nil {
+		return l, ErrSeasonOpen
+	}
+
+	season.Closed = time.Time{}
+	l.Seasons = append(l.Seasons, season)
+
+	return l, nil
+}
+
+// closeSeason stops wins being recorded in the named Season of league, in place
+func (l Leagues) closeSeason(league, name string, closed time.Time) (Leagues, error) {
+
+	season := l.findSeason(league, name)
+
+	if season == nil {
+		return l, ErrUnknownSeason
+	}
+
+	if !season.Open() {
+		return l, ErrSeasonClosed
+	}
+
+	season.Closed = closed
+
+	return l, nil
+}
+
+// seasonFor returns the season of league a win should be recorded in: the named season,
+// which must be open, or when season is empty the open season if there is one
+func (l Leagues) seasonFor(league, season string) (string, error) {
+
+	if season == "" {
+		if open := l.openSeason(league); open != nil {
+			return open.Name, nil
+		}
+		return "", nil
+	}
+
+	found := l.findSeason(league, season)
+
+	if found == nil {
+		return "", ErrUnknownSeason
+	}
+
+	if !found.Open() {
+		return "", ErrSeasonClosed
+	}
+
+	return season, nil
+}
+
+// recordWin counts a win for name in the table of a season of league, in place
+func (l Leagues) recordWin(league, season, name string) Leagues {
+	l, table := l.findOrAddTable(league, season)
+	table.Players = table.Players.recordWin(name)
+	return l
+}
+
+// recordGame counts a game in the table of its league and season, in place
+func (l Leagues) recordGame(game GameRecord) Leagues {
+	l, table := l.findOrAddTable(game.League, game.Season)
+	table.Players = table.Players.recordGame(game)
+	return l
+}
+
+// findOrAddTable returns the Leagues and the table for a season of league, which is added if it's new
+func (l Leagues) findOrAddTable(league, season string) (Leagues, *LeagueTable) {
+	for i, table := range l.Tables {
+		if table.League == league && table.Season == season {
+			return l, &l.Tables[i]
+		}
+	}
+
+	l.Tables = append(l.Tables, LeagueTable{League: league, Season: season, Players: League{}})
+	return l, &l.Tables[len(l.Tables)-1]
+}
+
+// table returns a copy of the League for a season of league, sorted by wins. With no season
+// it is the league's totals across every season. Only the main league exists before it is played in.
+func (l Leagues) table(league, season string) (League, error) {
+
+	if season != "" && l.findSeason(league, season) == nil {
+		return nil, ErrUnknownSeason
+	}
+
+	totals := League{}
+	known := league == ""
+
+	for _, table := range l.Tables {
+		if table.League != league || (season != "" && table.Season != season) {
+			continue
+		}
+
+		known = true
+
+		for _, player := range table.Players {
+			var total *Player
+			totals, total = totals.findOrAdd(player.Name)
+			total.Wins += player.Wins
+			total.Losses += player.Losses
+			total.GamesPlayed += player.GamesPlayed
+		}
+	}
+
+	if !known && len(l.seasons(league)) == 0 {
+		return nil, ErrUnknownLeague
+	}
+
+	return totals.sorted(), nil
+}
+
+// seasons returns a copy of the Seasons of league in the order they started
+func (l Leagues) seasons(league string) []Season {
+
+	seasons := []Season{}
+
+	for _, season := range l.Seasons {
+		if season.League == league {
+			seasons = append(seasons, season)
+		}
+	}
+
+	sort.SliceStable(seasons, func(i, j int) bool {
+		return seasons[i].Started.Before(seasons[j].Started)
+	})
+
+	return seasons
+}
@@ -7,7 +7,10 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"sync"
+	"time"
 )
 
 var wsUpgrader = websocket.Upgrader{
@@ -17,17 +20,41 @@ var wsUpgrader = websocket.Upgrader{
 
 const htmlTemplatePath = "game.html"
 
-// Player stores a name with number of wins
+const historyDateFormat = "2006-01-02"
+
+const rankByWinRate = "winrate"
+
+// Player stores a name with the number of games they've played, won and lost
 type Player struct {
-	Name string
-	Wins int
+	Name        string
+	Wins        int
+	Losses      int
+	GamesPlayed int
+}
+
+// WinRate is the fraction of their games the Player has won
+func (p Player) WinRate() float64 {
+	if p.GamesPlayed == 0 {
+		return 0
+	}
+	return float64(p.Wins) / float64(p.GamesPlayed)
 }
 
-// PlayerStore stores score information about players
+// PlayerStore stores score information about players and the history of their games.
+// Wins are counted in the all-time League as well as in the league and season they were recorded in,
+// where an empty league is the main league and an empty season is the league's open season.
 type PlayerStore interface {
 	GetPlayerScore(name string) int
 	PostRecordWin(name string) error
 	GetLeague() League
+	RecordGame(game GameRecord) error
+	GetGames(query GameQuery) []GameRecord
+	GetRatings() Ratings
+	RecordLeagueWin(league, season, name string) error
+	StartSeason(league, season string) error
+	CloseSeason(league, season string) error
+	GetSeasons(league string) []Season
+	GetLeagueTable(league, season string) (League, error)
 }
 
 // PlayerServer is an HTTP interface for PlayerStore information
@@ -35,15 +62,16 @@ type PlayerServer struct {
 	store PlayerStore
 	http.Handler
 	template *template.Template
-	game     Game
+	games    *GameManager
 }
 
 type playerServerWS struct {
 	*websocket.Conn
+	mu sync.Mutex
 }
 
-// NewPlayerServer instantiates a new PlayerServer
-func NewPlayerServer(store PlayerStore, game Game) (*PlayerServer, error) {
+// NewPlayerServer instantiates a new PlayerServer, which plays the games of games
+func NewPlayerServer(store PlayerStore, games *GameManager) (*PlayerServer, error) {
 	p := new(PlayerServer)
 
 	tmpl, err := template.ParseFiles("game.html")
@@ -54,53 +82,134 @@ func NewPlayerServer(store PlayerStore, game Game) (*PlayerServer, error) {
 
 	p.template = tmpl
 	p.store = store
-	p.game = game
+	p.games = games
 
 	router := http.NewServeMux()
 	router.Handle("/league", http.HandlerFunc(p.leagueHandler))
 	router.Handle("/players/", http.HandlerFunc(p.playersHandler))
 	router.Handle("/game", http.HandlerFunc(p.gameHandler))
 	router.Handle("/ws", http.HandlerFunc(p.webSocket))
+	router.Handle("/watch", http.HandlerFunc(p.watchHandler))
+	router.Handle("/games", http.HandlerFunc(p.gamesHandler))
+	router.Handle("/games/", http.HandlerFunc(p.gameInfoHandler))
+	router.Handle("/history", http.HandlerFunc(p.historyHandler))
+	router.Handle("/ratings", http.HandlerFunc(p.ratingsHandler))
+	router.Handle("/leagues/", http.HandlerFunc(p.leaguesHandler))
+	router.Handle("/seasons", http.HandlerFunc(p.seasonsHandler))
+	router.Handle("/seasons/close", http.HandlerFunc(p.closeSeasonHandler))
+	router.Handle("/payouts", http.HandlerFunc(p.payoutsHandler))
 
 	p.Handler = router
 
 	return p, nil
 }
 
-func newPlayerServerWS(w http.ResponseWriter, r *http.Request) *playerServerWS {
+func newPlayerServerWS(w http.ResponseWriter, r *http.Request) (*playerServerWS, error) {
 
 	conn, err := wsUpgrader.Upgrade(w, r, nil)
 
 	if err != nil {
 		log.Printf("problem upgrading connection to WebSocket %v\n", err)
+		return nil, err
 	}
 
-	return &playerServerWS{conn}
+	return &playerServerWS{Conn: conn}, nil
 
 }
 
-func (w *playerServerWS) WaitForMsg() string {
+func (w *playerServerWS) WaitForMsg() (string, error) {
 	_, msg, err := w.ReadMessage()
 	if err != nil {
 		log.Printf("error reading from websocket %v\n", err)
 	}
-	return string(msg)
+	return string(msg), err
 }
 
+// webSocket plays the game named by the game parameter, or if there isn't one a new game of the variant
+// parameter. A game whose player goes away before it's finished is abandoned.
 func (p *PlayerServer) webSocket(w http.ResponseWriter, r *http.Request) {
 
-	ws := newPlayerServerWS(w, r)
+	id := r.URL.Query().Get("game")
+
+	if id == "" {
+		info, err := p.games.CreateVariant(r.URL.Query().Get("variant"))
 
-	numberOfPlayersMsg := ws.WaitForMsg()
-	numberOfPlayers, _ := strconv.Atoi(string(numberOfPlayersMsg))
-	p.game.Start(numberOfPlayers, ws)
+		if err != nil {
+			http.Error(w, err.Error(), gameErrorStatus(err))
+			return
+		}
+
+		id = info.ID
+	}
+
+	game, err := p.games.Play(id)
+
+	if err != nil {
+		http.Error(w, err.Error(), gameErrorStatus(err))
+		return
+	}
 
-	winnerMsg := ws.WaitForMsg()
-	p.game.Finish(string(winnerMsg))
+	ws, err := newPlayerServerWS(w, r)
+
+	if err != nil {
+		p.games.Abandon(id)
+		return
+	}
+
+	numberOfPlayersMsg, err := ws.WaitForMsg()
+
+	if err != nil {
+		p.games.Abandon(id)
+		return
+	}
+
+	numberOfPlayers, players, err := ParsePlayers(numberOfPlayersMsg)
+
+	if err != nil {
+		fmt.Fprintln(ws, err)
+		p.games.Abandon(id)
+		return
+	}
 
+	if err := game.Start(numberOfPlayers, ws, players...); err != nil {
+		fmt.Fprintln(ws, err)
+		p.games.Abandon(id)
+		return
+	}
+
+	winnerMsg, err := ws.WaitForMsg()
+
+	for err == nil && playTurn(game, ws, winnerMsg) {
+		if winners := game.Winners(); len(winners) == 1 {
+			finishGame(game, ws, winners[0])
+			return
+		}
+
+		winnerMsg, err = ws.WaitForMsg()
+	}
+
+	if err != nil {
+		p.games.Abandon(id)
+		return
+	}
+
+	finishGame(game, ws, winnerMsg)
+
+}
+
+// finishGame finishes game with winner, sending what went wrong to ws if it couldn't be
+func finishGame(game Game, ws *playerServerWS, winner string) {
+	if err := game.Finish(winner); err != nil {
+		fmt.Fprintln(ws, err)
+	}
 }
 
+// Write sends p as a message. Blinds are broadcast from their own goroutine, so writes are
+// taken one at a time as a websocket only allows.
 func (w *playerServerWS) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	err = w.WriteMessage(1, p)
 
 	if err != nil {
@@ -110,24 +219,342 @@ func (w *playerServerWS) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// watchHandler sends the blinds of the game named by the game parameter to a websocket until it's closed
+func (p *PlayerServer) watchHandler(w http.ResponseWriter, r *http.Request) {
+
+	id := r.URL.Query().Get("game")
+
+	if id == "" {
+		http.Error(w, ErrNoGame.Error(), http.StatusBadRequest)
+		return
+	}
+
+	alerts, err := p.games.Alerts(id)
+
+	if err != nil {
+		http.Error(w, err.Error(), gameErrorStatus(err))
+		return
+	}
+
+	ws, err := newPlayerServerWS(w, r)
+
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	if alerts == nil {
+		return
+	}
+
+	unsubscribe := alerts.Subscribe(WebSocketRenderer(ws))
+	defer unsubscribe()
+
+	// nothing is read from someone watching, so the first error is them going away
+	for {
+		if _, _, err := ws.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
 func (p *PlayerServer) gameHandler(w http.ResponseWriter, r *http.Request) {
 	p.template.Execute(w, nil)
 }
 
+// gamesHandler lists the games being played and those recently over, or on POST creates a new one
+// of the variant parameter
+func (p *PlayerServer) gamesHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method == http.MethodPost {
+		info, err := p.games.CreateVariant(r.URL.Query().Get("variant"))
+
+		if err != nil {
+			http.Error(w, err.Error(), gameErrorStatus(err))
+			return
+		}
+
+		w.Header().Set("content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(info)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(p.games.Games())
+}
+
+// gameInfoHandler returns what's known about the game whose ID is in the path
+func (p *PlayerServer) gameInfoHandler(w http.ResponseWriter, r *http.Request) {
+
+	info, err := p.games.Info(r.URL.Path[len("/games/"):])
+
+	if err != nil {
+		http.Error(w, err.Error(), gameErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// gameErrorStatus is the http status for an error from the GameManager
+func gameErrorStatus(err error) int {
+	switch err {
+	case ErrUnknownGame:
+		return http.StatusNotFound
+	case ErrGameTaken:
+		return http.StatusConflict
+	case ErrUnknownVariant:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// leagueHandler returns the all-time league, or the table of a season of the main league when asked for with season
 func (p *PlayerServer) leagueHandler(w http.ResponseWriter, r *http.Request) {
 
+	season := r.URL.Query().Get("season")
+
+	if season == "" {
+		writeLeague(w, r, p.store.GetLeague())
+		return
+	}
+
+	p.writeLeagueTable(w, r, "", season)
+}
+
+// leaguesHandler returns the table of the league named in the path, for one season when asked
+// for with season or otherwise its totals across every season
+func (p *PlayerServer) leaguesHandler(w http.ResponseWriter, r *http.Request) {
+	league := r.URL.Path[len("/leagues/"):]
+
+	p.writeLeagueTable(w, r, league, r.URL.Query().Get("season"))
+}
+
+func (p *PlayerServer) writeLeagueTable(w http.ResponseWriter, r *http.Request, league, season string) {
+
+	table, err := p.store.GetLeagueTable(league, season)
+
+	if err != nil {
+		http.Error(w, err.Error(), seasonErrorStatus(err))
+		return
+	}
+
+	writeLeague(w, r, table)
+}
+
+// writeLeague writes the league ordered by wins, or by win rate when asked for with
+// rank=winrate, where players with fewer than mingames games come last
+func writeLeague(w http.ResponseWriter, r *http.Request, league League) {
+
+	if r.URL.Query().Get("rank") == rankByWinRate {
+		minGames, err := strconv.Atoi(r.URL.Query().Get("mingames"))
+
+		if err != nil {
+			minGames = 0
+		}
+
+		league = league.RankedByWinRate(minGames)
+	}
+
 	w.Header().Set("content-type", "application/json")
-	json.NewEncoder(w).Encode(p.store.GetLeague())
+	json.NewEncoder(w).Encode(league)
 	w.WriteHeader(http.StatusOK)
 
 }
 
+// seasonsHandler lists the seasons of the league named by the league parameter,
+// or on POST starts a new one called name
+func (p *PlayerServer) seasonsHandler(w http.ResponseWriter, r *http.Request) {
+
+	league := r.URL.Query().Get("league")
+
+	if r.Method == http.MethodPost {
+		err := p.store.StartSeason(league, r.URL.Query().Get("name"))
+
+		if err != nil {
+			http.Error(w, err.Error(), seasonErrorStatus(err))
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(p.store.GetSeasons(league))
+}
+
+// closeSeasonHandler closes the season called name of the league named by the league parameter
+func (p *PlayerServer) closeSeasonHandler(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := p.store.CloseSeason(r.URL.Query().Get("league"), r.URL.Query().Get("name"))
+
+	if err != nil {
+		http.Error(w, err.Error(), seasonErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// seasonErrorStatus is the http status for an error from a league or season
+func seasonErrorStatus(err error) int {
+	switch err {
+	case ErrUnknownLeague, ErrUnknownSeason:
+		return http.StatusNotFound
+	case ErrSeasonName:
+		return http.StatusBadRequest
+	case ErrSeasonExists, ErrSeasonOpen, ErrSeasonClosed:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// ratingsHandler returns every player's rating, highest first
+func (p *PlayerServer) ratingsHandler(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(p.store.GetRatings())
+}
+
+// historyHandler returns the games played, filtered by the player, from and to query parameters.
+// Dates are either RFC 3339 times or whole days, where to includes the whole of its day.
+func (p *PlayerServer) historyHandler(w http.ResponseWriter, r *http.Request) {
+
+	query, err := parseGameQuery(r.URL.Query())
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(p.store.GetGames(query))
+}
+
+func parseGameQuery(values url.Values) (GameQuery, error) {
+
+	query := GameQuery{Player: values.Get("player")}
+
+	var err error
+
+	if from := values.Get("from"); from != "" {
+		query.From, _, err = parseQueryTime(from)
+		if err != nil {
+			return query, err
+		}
+	}
+
+	if to := values.Get("to"); to != "" {
+		var wholeDay bool
+		query.To, wholeDay, err = parseQueryTime(to)
+		if err != nil {
+			return query, err
+		}
+		if wholeDay {
+			query.To = query.To.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	return query, nil
+}
+
+// parseQueryTime parses an RFC 3339 time or a date, reporting which it was
+func parseQueryTime(value string) (time.Time, bool, error) {
+
+	t, err := time.Parse(time.RFC3339, value)
+
+	if err == nil {
+		return t, false, nil
+	}
+
+	t, err = time.Parse(historyDateFormat, value)
+
+	if err != nil {
+		return t, false, ErrBadDate
+	}
+
+	return t, true, nil
+}
+
+// payoutsHandler returns the prize pool and payouts of a tournament described by the entrants, rebuys and
+// add-ons query parameters, the buy_in, rebuy and add_on costs, and the preset payout structure, which is standard
+// by default. If chop lists the stacks of the players left it also returns how ICM would chop the prizes between them.
+func (p *PlayerServer) payoutsHandler(w http.ResponseWriter, r *http.Request) {
+
+	structure, request, err := parsePayoutQuery(r.URL.Query())
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sheet, err := structure.Sheet(request)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(sheet)
+}
+
+func parsePayoutQuery(values url.Values) (PayoutStructure, PayoutRequest, error) {
+
+	var request PayoutRequest
+
+	name := values.Get("structure")
+
+	if name == "" {
+		name = StandardPayouts
+	}
+
+	structure, err := PayoutPreset(name)
+
+	if err != nil {
+		return nil, request, err
+	}
+
+	counts := map[string]*int{
+		"entrants": &request.Entrants,
+		"rebuys":   &request.Rebuys,
+		"add_ons":  &request.AddOns,
+		"buy_in":   &request.BuyIns.BuyIn,
+		"rebuy":    &request.BuyIns.Rebuy,
+		"add_on":   &request.BuyIns.AddOn,
+	}
+
+	for key, count := range counts {
+		if value := values.Get(key); value != "" {
+			if *count, err = strconv.Atoi(value); err != nil {
+				return nil, request, ErrBadPayoutRequest
+			}
+		}
+	}
+
+	if chop := values.Get("chop"); chop != "" {
+		if request.Chop, err = ParseChop(chop); err != nil {
+			return nil, request, err
+		}
+	}
+
+	return structure, request, nil
+}
+
 func (p *PlayerServer) playersHandler(w http.ResponseWriter, r *http.Request) {
 	player := r.URL.Path[len("/players/"):]
 
 	switch r.Method {
 	case http.MethodPost:
-		p.postWin(w, player)
+		p.postWin(w, player, r.URL.Query().Get("league"), r.URL.Query().Get("season"))
 	case http.MethodGet:
 		p.getScore(w, player)
 	}
@@ -145,8 +572,15 @@ func (p *PlayerServer) getScore(w http.ResponseWriter, player string) {
 
 }
 
-func (p *PlayerServer) postWin(w http.ResponseWriter, player string) {
+// postWin records a win for player in a season of league, which default to the main league and its open season
+func (p *PlayerServer) postWin(w http.ResponseWriter, player, league, season string) {
+
+	err := p.store.RecordLeagueWin(league, season, player)
+
+	if err != nil {
+		http.Error(w, err.Error(), seasonErrorStatus(err))
+		return
+	}
 
-	p.store.PostRecordWin(player)
 	w.WriteHeader(http.StatusAccepted)
 }
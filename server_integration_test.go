@@ -1,9 +1,11 @@
 package poker_test
 
 import (
+	"fmt"
 	"github.com/vetch101/go-tddapp"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
@@ -15,7 +17,7 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 	store, err := poker.NewFileSystemPlayerStore(database)
 	poker.AssertNoError(t, err)
 
-	server, _ := poker.NewPlayerServer(store, dummyGame)
+	server, _ := poker.NewPlayerServer(store, managerFor(dummyGame))
 	player := "Pepper"
 
 	for i := 0; i < 3; i++ {
@@ -46,10 +48,66 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 
 		got := getLeagueFromResponse(t, response.Body)
 		want := []poker.Player{
-			{Name: "Bob", Wins: 5},
-			{Name: "Pepper", Wins: 3},
+			{Name: "Bob", Wins: 5, GamesPlayed: 5},
+			{Name: "Pepper", Wins: 3, GamesPlayed: 3},
 		}
 		poker.AssertLeague(t, got, want)
 	})
 
 }
+
+func TestConcurrentWinsAndReads(t *testing.T) {
+
+	database, cleanDatabase := createTempFile(t, `[]`)
+	defer cleanDatabase()
+
+	store, err := poker.NewFileSystemPlayerStore(database)
+	poker.AssertNoError(t, err)
+
+	server := mustMakePlayerServer(t, store, dummyGame)
+
+	players := []string{"Pepper", "Bob", "Cleo"}
+	winsPerPlayer := 50
+
+	var wg sync.WaitGroup
+
+	for _, player := range players {
+		for i := 0; i < winsPerPlayer; i++ {
+			wg.Add(3)
+			go func(player string) {
+				defer wg.Done()
+				server.ServeHTTP(httptest.NewRecorder(), newPostWinRequest(player))
+			}(player)
+			go func(player string) {
+				defer wg.Done()
+				server.ServeHTTP(httptest.NewRecorder(), newGetScoreRequest(player))
+			}(player)
+			go func() {
+				defer wg.Done()
+				server.ServeHTTP(httptest.NewRecorder(), newLeagueRequest())
+			}()
+		}
+	}
+
+	wg.Wait()
+
+	for _, player := range players {
+		t.Run(fmt.Sprintf("no wins lost for %s", player), func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newGetScoreRequest(player))
+
+			poker.AssertResponseBody(t, response.Body.String(), fmt.Sprint(winsPerPlayer))
+		})
+	}
+
+	t.Run("league has every player", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest())
+
+		got := getLeagueFromResponse(t, response.Body)
+
+		if len(got) != len(players) {
+			t.Errorf("got %d players in league want %d", len(got), len(players))
+		}
+	})
+}
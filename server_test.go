@@ -27,7 +27,7 @@ func TestGETPlayers(t *testing.T) {
 		WinCalls: nil,
 		League:   nil,
 	}
-	server, _ := poker.NewPlayerServer(&store, dummyGame)
+	server, _ := poker.NewPlayerServer(&store, managerFor(dummyGame))
 
 	t.Run("returns Pepper's score", func(t *testing.T) {
 		request := newGetScoreRequest("Pepper")
@@ -63,7 +63,7 @@ func TestPOSTWins(t *testing.T) {
 		Scores:   map[string]int{},
 		WinCalls: nil, League: nil,
 	}
-	server, _ := poker.NewPlayerServer(&store, dummyGame)
+	server, _ := poker.NewPlayerServer(&store, managerFor(dummyGame))
 
 	t.Run("it records win on POST", func(t *testing.T) {
 		player := "Pepper"
@@ -91,7 +91,7 @@ func mustDialWS(t *testing.T, url string) *websocket.Conn {
 
 func mustMakePlayerServer(t *testing.T, store poker.PlayerStore, game poker.Game) *poker.PlayerServer {
 	t.Helper()
-	server, err := poker.NewPlayerServer(store, game)
+	server, err := poker.NewPlayerServer(store, managerFor(game))
 	if err != nil {
 		t.Fatal("problem creating player server", err)
 	}
@@ -99,6 +99,11 @@ func mustMakePlayerServer(t *testing.T, store poker.PlayerStore, game poker.Game
 	return server
 }
 
+// managerFor is a GameManager whose every game is game
+func managerFor(game poker.Game) *poker.GameManager {
+	return poker.NewGameManager(func() poker.Game { return game })
+}
+
 func writeWSMessage(t *testing.T, conn *websocket.Conn, message string) {
 	t.Helper()
 	if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
@@ -138,13 +143,14 @@ func assertGameStartedWith(t *testing.T, game *GameSpy, want int) {
 	t.Helper()
 
 	passed := retryUntil(500*time.Millisecond, func() bool {
-		return game.StartedWith == want
+		_, got := game.started()
+		return got == want
 	})
 
-	if game.StartCalled == false {
+	called, got := game.started()
+	if called == false {
 		t.Errorf("game should have started but did not start")
 	}
-	got := game.StartedWith
 	if !passed {
 		t.Errorf("got %d players, but wanted %d", got, want)
 	}
@@ -154,18 +160,19 @@ func assertFinishCalledWith(t *testing.T, game *GameSpy, winner string) {
 	t.Helper()
 
 	passed := retryUntil(500*time.Millisecond, func() bool {
-		return game.FinishedWith == winner
+		_, got := game.finished()
+		return got == winner
 	})
 
 	if !passed {
 		t.Errorf("expected finish called, but finish was not called")
 	}
 
-	if game.FinishCalled == false {
+	called, got := game.finished()
+	if called == false {
 		t.Errorf("game should have finished but did not finish")
 	}
 
-	got := game.FinishedWith
 	if got != winner {
 		t.Errorf("got %s winner, but wanted %s", got, winner)
 	}
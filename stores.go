@@ -0,0 +1,39 @@
+package poker
+
+const (
+	// FileBackend keeps the whole League in a single json file
+	FileBackend = "file"
+
+	// LogBackend appends each win to a log that is compacted into a snapshot
+	LogBackend = "log"
+
+	// BoltBackend keeps each Player under their own key in an embedded bbolt database
+	BoltBackend = "bolt"
+)
+
+// StoreFromFile opens the PlayerStore for backend kept in filename and returns it, its close func and the error
+func StoreFromFile(backend, filename string) (PlayerStore, func(), error) {
+
+	switch backend {
+	case FileBackend:
+		store, closeFunc, err := FileSystemStoreFromFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	case LogBackend:
+		store, closeFunc, err := LogStoreFromFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	case BoltBackend:
+		store, closeFunc, err := BoltStoreFromFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	}
+
+	return nil, nil, ErrUnknownBackend
+}
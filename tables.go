@@ -0,0 +1,236 @@
+package poker
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// DefaultTableSize is the most players seated at a table unless a tournament's rules say otherwise
+const DefaultTableSize = 10
+
+// Table is a table of a tournament and the players seated at it
+type Table struct {
+	Number  int
+	Players []string
+}
+
+// Move is a player being seated at a table, from another table unless they've just entered or rebought
+type Move struct {
+	Player string
+	From   int `json:",omitempty"`
+	To     int
+}
+
+func (m Move) String() string {
+	if m.From == 0 {
+		return fmt.Sprintf("%s sits at table %d", m.Player, m.To)
+	}
+	return fmt.Sprintf("%s moves from table %d to table %d", m.Player, m.From, m.To)
+}
+
+// Seating seats the players of a tournament at as few tables as they fit at, drawing their seats at random.
+// As players are knocked out tables are broken, once everyone left fits at one fewer, and kept balanced so
+// that none has more than one player more than any other, until they're all brought together at the final table.
+type Seating struct {
+	size   int
+	random *rand.Rand
+	tables []*Table
+	moves  []Move
+	final  bool
+}
+
+// NewSeating draws seats for players at tables of up to size, with random picking who sits where
+// and who moves when tables need balancing
+func NewSeating(size int, random *rand.Rand, players ...string) (*Seating, error) {
+
+	if size < 2 {
+		return nil, ErrTableSize
+	}
+
+	drawn := append([]string{}, players...)
+	random.Shuffle(len(drawn), func(i, j int) {
+		drawn[i], drawn[j] = drawn[j], drawn[i]
+	})
+
+	s := &Seating{size: size, random: random}
+
+	for number := 1; number == 1 || (number-1)*size < len(drawn); number++ {
+		s.tables = append(s.tables, &Table{Number: number})
+	}
+
+	// dealing the players round the tables leaves them balanced
+	for i, name := range drawn {
+		table := s.tables[i%len(s.tables)]
+		table.Players = append(table.Players, name)
+	}
+
+	return s, nil
+}
+
+// Seat seats a player who's entered or rebought at the table with the fewest players, opening another
+// table if every one is full
+func (s *Seating) Seat(name string) {
+
+	if s.players() >= len(s.tables)*s.size {
+		s.tables = append(s.tables, &Table{Number: s.tables[len(s.tables)-1].Number + 1})
+	}
+
+	table := s.smallest()
+	table.Players = append(table.Players, name)
+	s.moves = append(s.moves, Move{Player: name, To: table.Number})
+
+	s.balance()
+}
+
+// Remove takes a player who's been knocked out away from their table, breaking and balancing tables as needed
+func (s *Seating) Remove(name string) error {
+
+	table, seat := s.find(name)
+
+	if table == nil {
+		return ErrNotSeated
+	}
+
+	table.Players = append(table.Players[:seat], table.Players[seat+1:]...)
+
+	for len(s.tables) > 1 && s.players() <= (len(s.tables)-1)*s.size {
+		s.breakTable()
+	}
+
+	s.balance()
+
+	return nil
+}
+
+// Tables are the tables in play, by number, and who's seated at them
+func (s *Seating) Tables() []Table {
+
+	tables := make([]Table, len(s.tables))
+
+	for i, table := range s.tables {
+		tables[i] = Table{Number: table.Number, Players: append([]string{}, table.Players...)}
+	}
+
+	return tables
+}
+
+// TableOf is the number of the table name is seated at, or 0 if they're not seated
+func (s *Seating) TableOf(name string) int {
+	if table, _ := s.find(name); table != nil {
+		return table.Number
+	}
+	return 0
+}
+
+// Reseated returns the players who've been seated or moved since it was last called, and reports
+// whether they've just been brought together at the final table
+func (s *Seating) Reseated() ([]Move, bool) {
+
+	moves, final := s.moves, s.final
+	s.moves, s.final = nil, false
+
+	return moves, final
+}
+
+// breakTable breaks the table with the fewest players, the last of them if there's a tie,
+// sharing its players out between the tables with the fewest players
+func (s *Seating) breakTable() {
+
+	broken := len(s.tables) - 1
+
+	for i := broken - 1; i >= 0; i-- {
+		if len(s.tables[i].Players) < len(s.tables[broken].Players) {
+			broken = i
+		}
+	}
+
+	table := s.tables[broken]
+	s.tables = append(s.tables[:broken], s.tables[broken+1:]...)
+
+	for _, name := range table.Players {
+		to := s.smallest()
+		to.Players = append(to.Players, name)
+		s.moves = append(s.moves, Move{Player: name, From: table.Number, To: to.Number})
+	}
+
+	s.final = len(s.tables) == 1
+}
+
+// balance moves players drawn at random from the tables with the most players to those with the fewest
+// until no table has more than one player more than any other
+func (s *Seating) balance() {
+
+	for {
+		from, to := s.largest(), s.smallest()
+
+		if len(from.Players)-len(to.Players) <= 1 {
+			return
+		}
+
+		seat := s.random.Intn(len(from.Players))
+		name := from.Players[seat]
+
+		from.Players = append(from.Players[:seat], from.Players[seat+1:]...)
+		to.Players = append(to.Players, name)
+		s.moves = append(s.moves, Move{Player: name, From: from.Number, To: to.Number})
+	}
+}
+
+// smallest is the table with the fewest players, the first of them if there's a tie
+func (s *Seating) smallest() *Table {
+
+	smallest := s.tables[0]
+
+	for _, table := range s.tables {
+		if len(table.Players) < len(smallest.Players) {
+			smallest = table
+		}
+	}
+
+	return smallest
+}
+
+// largest is the table with the most players, the first of them if there's a tie
+func (s *Seating) largest() *Table {
+
+	largest := s.tables[0]
+
+	for _, table := range s.tables {
+		if len(table.Players) > len(largest.Players) {
+			largest = table
+		}
+	}
+
+	return largest
+}
+
+func (s *Seating) players() int {
+
+	players := 0
+
+	for _, table := range s.tables {
+		players += len(table.Players)
+	}
+
+	return players
+}
+
+func (s *Seating) find(name string) (*Table, int) {
+	for _, table := range s.tables {
+		for seat, player := range table.Players {
+			if player == name {
+				return table, seat
+			}
+		}
+	}
+	return nil, -1
+}
+
+// PrintTables writes each table and who's seated at it, one per line
+func PrintTables(out io.Writer, tables []Table) {
+	for _, table := range tables {
+		fmt.Fprintf(out, "table %d: %s\n", table.Number, strings.Join(table.Players, ", "))
+	}
+}
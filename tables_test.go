@@ -0,0 +1,250 @@
+package poker_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSeating(t *testing.T) {
+
+	t.Run("splits players evenly between as few tables as they fit at", func(t *testing.T) {
+		seating, err := poker.NewSeating(10, poker.NewRandom(1), namePlayers(23)...)
+		poker.AssertNoError(t, err)
+
+		tables := seating.Tables()
+
+		if len(tables) != 3 {
+			t.Fatalf("got %d tables want 3", len(tables))
+		}
+
+		assertBalanced(t, tables, 23)
+	})
+
+	t.Run("draws the same seats from the same seed", func(t *testing.T) {
+		first, _ := poker.NewSeating(10, poker.NewRandom(7), namePlayers(15)...)
+		second, _ := poker.NewSeating(10, poker.NewRandom(7), namePlayers(15)...)
+
+		if !reflect.DeepEqual(first.Tables(), second.Tables()) {
+			t.Errorf("got %v and %v want the same seats", first.Tables(), second.Tables())
+		}
+	})
+
+	t.Run("balances the tables as players are knocked out", func(t *testing.T) {
+		seating, _ := poker.NewSeating(10, poker.NewRandom(3), namePlayers(24)...)
+
+		for left := 23; left > 20; left-- {
+			poker.AssertNoError(t, seating.Remove(seating.Tables()[0].Players[0]))
+			assertBalanced(t, seating.Tables(), left)
+		}
+
+		moves, final := seating.Reseated()
+
+		if len(moves) != 2 || final {
+			t.Fatalf("got moves %v want two players moved to balance the tables", moves)
+		}
+
+		for _, move := range moves {
+			if move.From == 1 || move.To != 1 {
+				t.Errorf("got %v want players moved to table 1", move)
+			}
+		}
+
+		if moves, _ := seating.Reseated(); len(moves) != 0 {
+			t.Errorf("got moves %v want none once they've been seen", moves)
+		}
+	})
+
+	t.Run("breaks tables until everyone left is at the final table", func(t *testing.T) {
+		seating, _ := poker.NewSeating(10, poker.NewRandom(5), namePlayers(12)...)
+		broken := seating.Tables()[0]
+		broken.Players = broken.Players[2:]
+
+		seating.Remove(seating.Tables()[0].Players[0])
+		seating.Remove(seating.Tables()[0].Players[0])
+
+		tables := seating.Tables()
+
+		if len(tables) != 1 || len(tables[0].Players) != 10 {
+			t.Fatalf("got tables %v want a final table of 10", tables)
+		}
+
+		moves, final := seating.Reseated()
+
+		if !final {
+			t.Error("should have reported the final table")
+		}
+
+		var moved []string
+
+		for _, move := range moves {
+			if move.From != broken.Number || move.To != tables[0].Number {
+				t.Errorf("got %v want every move from table %d to table %d", move, broken.Number, tables[0].Number)
+			}
+			moved = append(moved, move.Player)
+		}
+
+		if !reflect.DeepEqual(moved, broken.Players) {
+			t.Errorf("got %v moved want the broken table's %v", moved, broken.Players)
+		}
+	})
+
+	t.Run("seats entries at the smallest table, opening another when they're all full", func(t *testing.T) {
+		seating, _ := poker.NewSeating(10, poker.NewRandom(9), namePlayers(19)...)
+
+		seating.Seat("Ruth")
+
+		if got := seating.TableOf("Ruth"); got != 2 || len(seating.Tables()) != 2 {
+			t.Errorf("got Ruth at table %d of %v want table 2 of 2", got, seating.Tables())
+		}
+
+		seating.Seat("Chris")
+		assertBalanced(t, seating.Tables(), 21)
+
+		if len(seating.Tables()) != 3 {
+			t.Errorf("got %d tables want another opened for Chris", len(seating.Tables()))
+		}
+
+		moves, _ := seating.Reseated()
+		want := []poker.Move{{Player: "Ruth", To: 2}, {Player: "Chris", To: 3}}
+
+		if !reflect.DeepEqual(moves[:2], want) {
+			t.Errorf("got %v want Ruth and Chris seated first with %v", moves, want)
+		}
+	})
+
+	t.Run("tables seat at least two players, and only seated players leave them", func(t *testing.T) {
+		if _, err := poker.NewSeating(1, poker.NewRandom(1), "Ruth", "Chris"); err != poker.ErrTableSize {
+			t.Errorf("got error %v want %v", err, poker.ErrTableSize)
+		}
+
+		seating, _ := poker.NewSeating(10, poker.NewRandom(1), "Ruth", "Chris")
+
+		if err := seating.Remove("Cleo"); err != poker.ErrNotSeated {
+			t.Errorf("got error %v want %v", err, poker.ErrNotSeated)
+		}
+	})
+}
+
+func TestGame_Tables(t *testing.T) {
+
+	t.Run("splits a tournament's tables and brings them together at the final table", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+		game.SetTournament(poker.TournamentRules{TableSize: 5})
+		game.SetRandom(poker.NewRandom(11))
+		out := &bytes.Buffer{}
+
+		players := namePlayers(7)
+		messages := []string{strings.Join(players, ","), poker.TablesCommand}
+
+		for _, name := range players[1:] {
+			messages = append(messages, name+" out")
+		}
+
+		poker.NewCLI(userSends(messages...), out, game).PlayPoker()
+
+		got := out.String()
+
+		for _, want := range []string{"table 1: ", "table 2: ", "final table: ", "1st " + players[0]} {
+			if !strings.Contains(got, want) {
+				t.Errorf("got %q want it to include %q", got, want)
+			}
+		}
+
+		if strings.Count(got, "final table: ") != 1 {
+			t.Errorf("got %q want the final table announced once", got)
+		}
+
+		if len(store.Games) != 1 || store.Games[0].Winner != players[0] {
+			t.Errorf("got %+v want the tournament won by %s", store.Games, players[0])
+		}
+	})
+
+	t.Run("a single table isn't split", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetTournament(poker.FreezeOut)
+		out := &bytes.Buffer{}
+
+		poker.NewCLI(userSends("Ruth,Chris,Cleo", poker.TablesCommand), out, game).PlayPoker()
+
+		if strings.Count(out.String(), "table ") != 1 {
+			t.Errorf("got %q want just the one table printed for the tables command", out.String())
+		}
+	})
+
+	t.Run("tables need a tournament", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		poker.NewCLI(userSends("3", poker.TablesCommand, "Ruth wins"), out, &GameSpy{}).PlayPoker()
+
+		assertMessageSentToUser(t, out, poker.PlayerPrompt, poker.ErrNotTournament.Error()+"\n")
+	})
+}
+
+func TestPrintTables(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	poker.PrintTables(out, []poker.Table{
+		{Number: 1, Players: []string{"Ruth", "Chris"}},
+		{Number: 3, Players: []string{"Cleo", "Bob"}},
+	})
+
+	want := "table 1: Ruth, Chris\ntable 3: Cleo, Bob\n"
+
+	if out.String() != want {
+		t.Errorf("got %q want %q", out.String(), want)
+	}
+
+	if got := (poker.Move{Player: "Ruth", From: 3, To: 1}).String(); got != "Ruth moves from table 3 to table 1" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// namePlayers names n players P1 to Pn
+func namePlayers(n int) []string {
+
+	names := make([]string, n)
+
+	for i := range names {
+		names[i] = fmt.Sprintf("P%d", i+1)
+	}
+
+	return names
+}
+
+// assertBalanced checks that players are seated at tables with no more than one player more at any than another
+func assertBalanced(t *testing.T, tables []poker.Table, players int) {
+	t.Helper()
+
+	seated := map[string]bool{}
+	fewest, most := players, 0
+
+	for _, table := range tables {
+		for _, name := range table.Players {
+			if seated[name] {
+				t.Errorf("%s is seated twice in %v", name, tables)
+			}
+			seated[name] = true
+		}
+
+		if len(table.Players) < fewest {
+			fewest = len(table.Players)
+		}
+
+		if len(table.Players) > most {
+			most = len(table.Players)
+		}
+	}
+
+	if len(seated) != players {
+		t.Errorf("got %d players seated want %d", len(seated), players)
+	}
+
+	if most-fewest > 1 {
+		t.Errorf("got tables %v want them balanced", tables)
+	}
+}
@@ -7,9 +7,16 @@ type Tape struct {
 	File *os.File
 }
 
-// Write is a writer for the tape file
+// Write is a writer for the tape file, replacing everything on it with p
 func (t *Tape) Write(p []byte) (n int, err error) {
-	t.File.Truncate(0)
-	t.File.Seek(0, 0)
+
+	if t.File.Truncate(0) != nil {
+		return 0, ErrFileWrite
+	}
+
+	if _, err := t.File.Seek(0, 0); err != nil {
+		return 0, ErrFileSeek
+	}
+
 	return t.File.Write(p)
 }
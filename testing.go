@@ -1,8 +1,12 @@
 package poker
 
 import (
+	"math"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 )
 
 // StubPlayerStore is a spy stub mock for PlayerStore
@@ -10,6 +14,9 @@ type StubPlayerStore struct {
 	Scores   map[string]int
 	WinCalls []string
 	League   []Player
+	Games    []GameRecord
+	Ratings  Ratings
+	Leagues  Leagues
 }
 
 // GetPlayerScore returns the spy store score
@@ -29,6 +36,181 @@ func (s *StubPlayerStore) PostRecordWin(name string) error {
 	return nil
 }
 
+// RecordGame adds the game to the spy store's Games and its winner to winCalls
+func (s *StubPlayerStore) RecordGame(game GameRecord) error {
+	s.Games = append(s.Games, game)
+	s.WinCalls = append(s.WinCalls, game.Winner)
+	return nil
+}
+
+// GetGames returns the spy store's Games matching query
+func (s *StubPlayerStore) GetGames(query GameQuery) []GameRecord {
+	return filterGames(s.Games, query)
+}
+
+// GetRatings returns the spy store's Ratings
+func (s *StubPlayerStore) GetRatings() Ratings {
+	return s.Ratings
+}
+
+// RecordLeagueWin adds to the wins in winCalls and counts the win in the spy store's Leagues
+func (s *StubPlayerStore) RecordLeagueWin(league, season, name string) error {
+	season, err := s.Leagues.seasonFor(league, season)
+
+	if err != nil {
+		return err
+	}
+
+	s.WinCalls = append(s.WinCalls, name)
+	s.Leagues = s.Leagues.recordWin(league, season, name)
+	return nil
+}
+
+// StartSeason starts a season in the spy store's Leagues
+func (s *StubPlayerStore) StartSeason(league, season string) error {
+	var err error
+	s.Leagues, err = s.Leagues.startSeason(Season{League: league, Name: season, Started: time.Now()})
+	return err
+}
+
+// CloseSeason closes a season in the spy store's Leagues
+func (s *StubPlayerStore) CloseSeason(league, season string) error {
+	var err error
+	s.Leagues, err = s.Leagues.closeSeason(league, season, time.Now())
+	return err
+}
+
+// GetSeasons returns the seasons of league in the spy store's Leagues
+func (s *StubPlayerStore) GetSeasons(league string) []Season {
+	return s.Leagues.seasons(league)
+}
+
+// GetLeagueTable returns a table from the spy store's Leagues
+func (s *StubPlayerStore) GetLeagueTable(league, season string) (League, error) {
+	return s.Leagues.table(league, season)
+}
+
+// FakeClock is a Clock whose time only moves when it's advanced, firing the timers that fall due as it goes
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock returns a FakeClock stopped at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the FakeClock's time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc calls f once the clock has been advanced by d
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.start(d, f, nil)
+}
+
+// NewTimer returns a Timer that sends the time on its channel once the clock has been advanced by d
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.start(d, nil, make(chan time.Time, 1))
+}
+
+func (c *FakeClock) start(d time.Duration, f func(), ch chan time.Time) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{clock: c, f: f, c: ch}
+	c.add(timer, d)
+	return timer
+}
+
+// Advance moves the clock on by d, firing each timer that falls due on the way in the order they do.
+// AfterFunc funcs are called before Advance returns.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	until := c.now.Add(d)
+
+	for len(c.timers) > 0 && !c.timers[0].when.After(until) {
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = timer.when
+
+		c.mu.Unlock()
+		timer.fire()
+		c.mu.Lock()
+	}
+
+	c.now = until
+	c.mu.Unlock()
+}
+
+// Timers is how many timers are waiting to fire
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// add sets timer to fire after d, keeping the timers in the order they fire
+func (c *FakeClock) add(timer *fakeTimer, d time.Duration) {
+	timer.when = c.now.Add(d)
+	c.timers = append(c.timers, timer)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+}
+
+// remove stops timer firing, reporting whether it was going to
+func (c *FakeClock) remove(timer *fakeTimer) bool {
+	for i, waiting := range c.timers {
+		if waiting == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	f     func()
+	c     chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.remove(t)
+	t.clock.add(t, d)
+	return active
+}
+
+func (t *fakeTimer) fire() {
+	if t.f != nil {
+		t.f()
+		return
+	}
+
+	select {
+	case t.c <- t.when:
+	default:
+	}
+}
+
 // AssertStatus is an assertion for http response status
 func AssertStatus(t *testing.T, got, want int) {
 	t.Helper()
@@ -62,6 +244,49 @@ func AssertLeague(t *testing.T, got, want League) {
 	}
 }
 
+// AssertGames asserts the GameRecords, ignoring time zones
+func AssertGames(t *testing.T, got, want []GameRecord) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d games want %d, %v", len(got), len(want), got)
+	}
+
+	for i := range want {
+		if !sameGame(got[i], want[i]) {
+			t.Errorf("game %d is %+v want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func sameGame(a, b GameRecord) bool {
+	if !a.Started.Equal(b.Started) || !a.Finished.Equal(b.Finished) {
+		return false
+	}
+	a.Started, a.Finished = b.Started, b.Finished
+
+	if len(a.Players) == 0 && len(b.Players) == 0 {
+		a.Players = b.Players
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// AssertRatings asserts the Ratings, to the nearest hundredth of a point
+func AssertRatings(t *testing.T, got, want Ratings) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d ratings want %d, %v", len(got), len(want), got)
+	}
+
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Games != want[i].Games || math.Abs(got[i].Rating-want[i].Rating) > 0.005 {
+			t.Errorf("rating %d is %+v want %+v", i, got[i], want[i])
+		}
+	}
+}
+
 // AssertPlayerWin asserts which player won (& that it only wins once)
 func AssertPlayerWin(t *testing.T, store *StubPlayerStore, winner string) {
 	t.Helper()
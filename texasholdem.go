@@ -1,39 +1,550 @@
 package poker
 
 import (
+	"fmt"
 	"io"
+	"math/rand"
+	"strings"
 	"time"
 )
 
+// DefaultStartingChips is how many chips each player starts with
+const DefaultStartingChips = 10000
+
 // TexasHoldEm is a struct containing alerter (a BlindAlerter)
-// and store (PlayerStore)
+// and store (PlayerStore). It plays Texas Hold'em unless it's set to play another Variant.
 type TexasHoldEm struct {
 	alerter           BlindAlerter
+	renderer          Renderer
+	alerts            *Broadcast
+	unsubscribe       func()
 	store             PlayerStore
 	alertsDestination io.Writer
+	league            string
+	random            *rand.Rand
+	variant           Variant
+	bettingStructure  BettingStructure
+	startingChips     int
+	blindStructure    BlindStructure
+	clock             Clock
+	blindClock        *BlindClock
+	lifecycle         Lifecycle
+	tournamentRules   *TournamentRules
+	tournament        *Tournament
+	buyIns            BuyIns
+	payouts           PayoutStructure
+	hand              *Deal
+	betting           *Betting
+	winners           []int
+
+	started         time.Time
+	numberOfPlayers int
+	players         []string
+	blinds          BlindStructure
 }
 
 // NewTexasHoldEm returns a pointer to a TexasHoldEm struct
 func NewTexasHoldEm(alerter BlindAlerter, store PlayerStore) *TexasHoldEm {
 	return &TexasHoldEm{
-		alerter: alerter,
-		store:   store,
+		alerter:       alerter,
+		renderer:      AlerterRenderer(alerter),
+		alerts:        NewBroadcast(),
+		store:         store,
+		clock:         SystemClock,
+		random:        NewRandom(0),
+		variant:       TexasHoldEmVariant,
+		startingChips: DefaultStartingChips,
+	}
+}
+
+// SetStartingChips sets how many chips each player starts with
+func (t *TexasHoldEm) SetStartingChips(chips int) {
+	t.startingChips = chips
+}
+
+// SetBlindStructure sets the blinds that games are played with, and how many chips players start with if
+// the structure says. Without one games are played with the DefaultBlindStructure for their number of players.
+func (t *TexasHoldEm) SetBlindStructure(structure BlindStructure) {
+	t.blindStructure = structure
+	if structure.StartingChips > 0 {
+		t.startingChips = structure.StartingChips
+	}
+}
+
+// SetRenderer sets how blind events are written to a game's alerts destination, which is
+// by the game's BlindAlerter unless it's set
+func (t *TexasHoldEm) SetRenderer(renderer Renderer) {
+	t.renderer = renderer
+}
+
+// SetTournament plays games as tournaments with rules, which need their players' names
+func (t *TexasHoldEm) SetTournament(rules TournamentRules) {
+	t.tournamentRules = &rules
+}
+
+// SetPayouts sets what it costs to play in a tournament and how its prize pool is paid out
+func (t *TexasHoldEm) SetPayouts(buyIns BuyIns, structure PayoutStructure) {
+	t.buyIns = buyIns
+	t.payouts = structure
+}
+
+// SetVariant sets the kind of poker games are played as
+func (t *TexasHoldEm) SetVariant(variant Variant) {
+	t.variant = variant
+}
+
+// Variant is the kind of poker games are played as
+func (t *TexasHoldEm) Variant() Variant {
+	return t.variant
+}
+
+// SetBettingStructure sets how much players can bet and raise, in place of the variant's own betting structure
+func (t *TexasHoldEm) SetBettingStructure(structure BettingStructure) {
+	t.bettingStructure = structure
+}
+
+// SetClock sets the clock that games are timed and their blinds go up by
+func (t *TexasHoldEm) SetClock(clock Clock) {
+	t.clock = clock
+}
+
+// SetRandom sets what decks are shuffled with, so that a seeded random deals the same cards every time
+func (t *TexasHoldEm) SetRandom(random *rand.Rand) {
+	t.random = random
+}
+
+// SetLeague sets the league that finished games are recorded in, which is the main league unless it's set
+func (t *TexasHoldEm) SetLeague(league string) {
+	t.league = league
+}
+
+// Start starts a game of TexasHoldEm with numberOfPlayers, who are named by players when they're known.
+// Another game can only be started once the last one is over, and a game whose hand can't be bet on,
+// such as when the players have no chips, isn't started at all.
+func (t *TexasHoldEm) Start(numberOfPlayers int, alertsDestination io.Writer, players ...string) error {
+
+	// seating and dealing use up the game's random, so they're only done for a game that can start
+	if err := t.lifecycle.CanStart(); err != nil {
+		return err
+	}
+
+	blinds := t.blindStructure
+
+	if len(blinds.Levels) == 0 {
+		blinds = DefaultBlindStructure(numberOfPlayers)
+	}
+
+	blindClock := NewBlindClock(t.clock)
+	tournament, err := t.newTournament(blinds, blindClock, players)
+
+	if err != nil {
+		return err
+	}
+
+	hand, betting, err := t.deal(numberOfPlayers, blinds.Levels[0])
+
+	if err != nil {
+		return err
+	}
+
+	if err := t.lifecycle.Start(); err != nil {
+		return err
+	}
+
+	t.blindClock = blindClock
+	t.tournament = tournament
+	t.started = t.clock.Now()
+	t.numberOfPlayers = numberOfPlayers
+	t.players = players
+	t.blinds = blinds
+	t.alertsDestination = alertsDestination
+
+	// the last game's alerts destination only leaves now, so it sees every blind of its game
+	if t.unsubscribe != nil {
+		t.unsubscribe()
+	}
+
+	t.alerts.Forget()
+	t.unsubscribe = t.alerts.Subscribe(t.renderer(alertsDestination))
+
+	for _, blind := range blinds.Schedule() {
+		t.blindClock.Schedule(blind.At, blind.Event, t.alerts)
+	}
+
+	t.blindClock.Resume()
+
+	if tournament != nil && len(tournament.Tables()) > 1 {
+		PrintTables(alertsDestination, tournament.Tables())
+	}
+
+	t.winners = nil
+	t.hand = hand
+	t.betting = betting
+
+	// stud is bet on from the first cards dealt face up
+	if t.betting != nil && !t.variant.HasBoard() {
+		t.announceStreet()
+	}
+
+	return nil
+}
+
+// deal deals a hand of the variant to numberOfPlayers and starts the betting on it at level. A lone player's
+// game is still played, but without cards, so it has no hand or betting. More players than the variant can
+// deal to is an error.
+func (t *TexasHoldEm) deal(numberOfPlayers int, level BlindLevel) (*Deal, *Betting, error) {
+
+	if numberOfPlayers < 2 {
+		return nil, nil, nil
+	}
+
+	deck := NewDeck()
+	deck.Shuffle(t.random)
+
+	hand, err := t.variant.Deal(deck, numberOfPlayers)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stacks := make([]int, numberOfPlayers)
+
+	for i := range stacks {
+		stacks[i] = t.startingChips
+	}
+
+	// the blind that's announced is the big blind
+	betting, err := t.variant.NewBetting(hand, stacks, 0, level)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t.bettingStructure != nil {
+		betting.SetStructure(t.bettingStructure)
+	}
+
+	return hand, betting, nil
+}
+
+// newTournament is the tournament played between players if games are tournaments, seated at tables drawn
+// with the game's random, whose rebuy and add-on periods go by the blinds, or nil if they're not
+func (t *TexasHoldEm) newTournament(blinds BlindStructure, clock *BlindClock, players []string) (*Tournament, error) {
+
+	if t.tournamentRules == nil {
+		return nil, nil
+	}
+
+	tournament, err := NewTournament(*t.tournamentRules, func() int {
+		level, _ := blinds.LevelAt(clock.Elapsed())
+		return level
+	}, players...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tournament, tournament.DrawSeats(t.random)
+}
+
+// Tournament is the tournament being played, or nil if games aren't tournaments
+func (t *TexasHoldEm) Tournament() *Tournament {
+	return t.tournament
+}
+
+// State is the state of the game being played, or of the last one once it's over
+func (t *TexasHoldEm) State() GameState {
+	return t.lifecycle.State()
+}
+
+// Pause pauses the running game along with its blinds
+func (t *TexasHoldEm) Pause() error {
+
+	if err := t.lifecycle.Pause(); err != nil {
+		return err
+	}
+
+	// the blind clock can only be already paused if it was paused without the game, which leaves it as wanted
+	t.blindClock.Pause()
+
+	return nil
+}
+
+// Resume starts the paused game running again, along with its blinds
+func (t *TexasHoldEm) Resume() error {
+
+	if err := t.lifecycle.Resume(); err != nil {
+		return err
+	}
+
+	t.blindClock.Resume()
+
+	return nil
+}
+
+// BlindClock is the clock announcing the blinds of the game being played, or nil before the first game starts
+func (t *TexasHoldEm) BlindClock() *BlindClock {
+	return t.blindClock
+}
+
+// Alerts is where the blinds of every game are broadcast, to the alerts destination the game was started
+// with and anywhere else that subscribes to them
+func (t *TexasHoldEm) Alerts() *Broadcast {
+	return t.alerts
+}
+
+// Hand is the hand being dealt, or nil if the game's players couldn't be dealt in
+func (t *TexasHoldEm) Hand() *Deal {
+	return t.hand
+}
+
+// Betting is the betting on the hand being dealt, or nil if the game's players couldn't be dealt in
+func (t *TexasHoldEm) Betting() *Betting {
+	return t.betting
+}
+
+// Act takes the turn of the player whose turn it is to bet and announces it to the alerts destination.
+// If everyone else has folded the last player left wins the pot.
+func (t *TexasHoldEm) Act(action Action) error {
+
+	if err := t.lifecycle.Playing(); err != nil {
+		return err
+	}
+
+	if t.betting == nil {
+		return ErrNoHand
 	}
+
+	seat := t.betting.ToAct()
+
+	err := t.betting.Act(seat, action)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(t.alertsDestination, "%s: %s\n", t.seatName(seat), action)
+
+	if t.betting.HandOver() {
+		awards := t.betting.Award(nil)
+
+		if len(awards) > 0 {
+			t.winners = awards[0].Winners
+		}
+
+		for _, award := range awards {
+			fmt.Fprintf(t.alertsDestination, "%s wins %d as everyone else folded\n", t.seatName(award.Winners[0]), award.Amount)
+		}
+	}
+
+	return nil
 }
 
-// Start starts a game of TexasHoldEm with numberOfPlayers
-func (t *TexasHoldEm) Start(numberOfPlayers int, alertsDestination io.Writer) {
-	blinds := []int{100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000}
-	blindTime := 0 * time.Second
-	blindIncrement := time.Duration(5+numberOfPlayers) * time.Second
+// NextStreet deals the next street of the hand, once the betting on this one is over,
+// and announces the board to the alerts destination
+func (t *TexasHoldEm) NextStreet() error {
+
+	if err := t.lifecycle.Playing(); err != nil {
+		return err
+	}
 
-	for _, blind := range blinds {
-		t.alerter.ScheduledAlertAt(blindTime, blind, alertsDestination)
-		blindTime = blindTime + blindIncrement
+	if t.hand == nil || t.betting == nil {
+		return ErrNoHand
 	}
+
+	err := t.betting.NextStreet()
+
+	if err != nil {
+		return err
+	}
+
+	err = t.hand.Next()
+
+	if err != nil {
+		return err
+	}
+
+	if t.hand.Street() != Showdown {
+		t.announceStreet()
+		return nil
+	}
+
+	return t.showdown()
+}
+
+// announceStreet announces the street that's just been dealt to the alerts destination: the board, or
+// in stud the cards showing in front of every player still in the hand
+func (t *TexasHoldEm) announceStreet() {
+
+	if t.variant.HasBoard() {
+		fmt.Fprintf(t.alertsDestination, "%s: %s\n", t.hand.Street(), formatCards(t.hand.Board()))
+		return
+	}
+
+	var showing []string
+
+	for seat, s := range t.betting.Seats() {
+		if !s.Folded {
+			showing = append(showing, t.seatName(seat)+" "+formatCards(t.hand.Showing(seat)))
+		}
+	}
+
+	fmt.Fprintf(t.alertsDestination, "%s: %s\n", t.hand.Street(), strings.Join(showing, ", "))
 }
 
-// Finish finishes the game of TexasHoldEm recording the winner
-func (t *TexasHoldEm) Finish(winner string) {
-	t.store.PostRecordWin(winner)
+// showdown shares out the pots between the best hands still in and announces who won them and with what
+func (t *TexasHoldEm) showdown() error {
+
+	if t.hand == nil || t.betting == nil {
+		return ErrNoHand
+	}
+
+	values := make([]HandValue, t.hand.Seats())
+
+	for seat, s := range t.betting.Seats() {
+		if s.Folded {
+			continue
+		}
+
+		value, err := t.variant.Value(t.hand.Hole(seat), t.hand.Board())
+
+		if err != nil {
+			return err
+		}
+
+		values[seat] = value
+	}
+
+	awards := t.betting.Award(values)
+
+	if len(awards) > 0 {
+		t.winners = awards[0].Winners
+	}
+
+	for _, award := range awards {
+		names := make([]string, len(award.Winners))
+
+		for i, seat := range award.Winners {
+			names[i] = fmt.Sprintf("%s (%s)", t.seatName(seat), formatCards(t.hand.Hole(seat)))
+		}
+
+		value := values[award.Winners[0]]
+
+		if len(names) == 1 {
+			fmt.Fprintf(t.alertsDestination, "showdown: %s wins %d with %s\n", names[0], award.Amount, value)
+		} else {
+			fmt.Fprintf(t.alertsDestination, "showdown: %s split %d with %s\n", strings.Join(names, ", "), award.Amount, value)
+		}
+	}
+
+	return nil
+}
+
+// Winners returns the names of the players who won the main pot, more than one when they split it.
+// It's nil until the hand has been won or if the players weren't named. In a tournament it's the
+// last player left once everyone else has been eliminated.
+func (t *TexasHoldEm) Winners() []string {
+
+	if t.tournament != nil {
+		if winner := t.tournament.Winner(); winner != "" {
+			return []string{winner}
+		}
+		return nil
+	}
+
+	if t.hand == nil || len(t.players) != t.hand.Seats() {
+		return nil
+	}
+
+	var names []string
+
+	for _, seat := range t.winners {
+		names = append(names, t.players[seat])
+	}
+
+	return names
+}
+
+// seatName is the name of the player in seat, or its number if the players weren't named
+func (t *TexasHoldEm) seatName(seat int) string {
+	if len(t.players) == t.hand.Seats() {
+		return t.players[seat]
+	}
+	return fmt.Sprintf("seat %d", seat+1)
+}
+
+// Finish finishes the game of TexasHoldEm, stopping its blinds and recording the history of the game,
+// which counts as a win for the winner and a loss for everyone else who played. A tournament can
+// only be finished by the last player left, and its standings are announced and recorded.
+func (t *TexasHoldEm) Finish(winner string) error {
+
+	if err := t.lifecycle.CanFinish(); err != nil {
+		return err
+	}
+
+	if t.tournament != nil {
+		if err := t.tournament.Finish(winner); err != nil {
+			return err
+		}
+	}
+
+	if err := t.lifecycle.Finish(); err != nil {
+		return err
+	}
+
+	finished := t.clock.Now()
+	t.stopBlinds()
+
+	level, blind := t.blinds.LevelAt(finished.Sub(t.started))
+
+	game := GameRecord{
+		ID:              NewGameID(),
+		Started:         t.started,
+		Finished:        finished,
+		NumberOfPlayers: t.numberOfPlayers,
+		Players:         t.players,
+		Winner:          winner,
+		BlindLevel:      level,
+		League:          t.league,
+		Variant:         t.variant.Name,
+	}
+
+	if level > 0 {
+		game.Blind = blind.BigBlind
+	}
+
+	if t.tournament != nil {
+		game.Players = t.tournament.Players()
+		game.NumberOfPlayers = len(game.Players)
+		game.Standings = t.tournament.Standings()
+		PrintStandings(t.alertsDestination, game.Standings)
+
+		if t.payouts != nil {
+			game.PrizePool, game.Payouts = t.payouts.PayoutsFor(t.buyIns, game.Standings)
+			fmt.Fprintf(t.alertsDestination, "prize pool %d\n", game.PrizePool)
+			PrintPayouts(t.alertsDestination, game.Payouts)
+		}
+	}
+
+	return t.store.RecordGame(game)
+}
+
+// Abort ends the game without a winner, stopping its blinds without recording it
+func (t *TexasHoldEm) Abort() error {
+
+	if err := t.lifecycle.Abort(); err != nil {
+		return err
+	}
+
+	t.stopBlinds()
+
+	return nil
+}
+
+// stopBlinds stops the blinds of the game, which has no blind clock if it's aborted before it starts
+func (t *TexasHoldEm) stopBlinds() {
+	if t.blindClock != nil {
+		t.blindClock.Stop()
+	}
+	t.alerts.Forget()
 }
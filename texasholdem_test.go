@@ -1,10 +1,14 @@
 package poker_test
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/vetch101/go-tddapp"
 	"io"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -24,13 +28,34 @@ func (s ScheduledAlert) String() string {
 }
 
 type SpyBlindAlerter struct {
+	mu     sync.Mutex
 	alerts []ScheduledAlert
 }
 
 func (s *SpyBlindAlerter) ScheduledAlertAt(duration time.Duration, amount int, to io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.alerts = append(s.alerts, ScheduledAlert{duration, amount})
 }
 
+// sent returns the alerts scheduled so far, safe to use while a BlindClock is sending them
+func (s *SpyBlindAlerter) sent() []ScheduledAlert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ScheduledAlert{}, s.alerts...)
+}
+
+// scheduledBlinds is every alert a game's clock has sent to the spy, followed by those it's still to send
+func scheduledBlinds(alerter *SpyBlindAlerter, clock *poker.BlindClock) []ScheduledAlert {
+	alerts := alerter.sent()
+	for _, blind := range clock.Pending() {
+		if blind.Event.Type == poker.LevelStarted {
+			alerts = append(alerts, ScheduledAlert{blind.At, blind.Event.BigBlind})
+		}
+	}
+	return alerts
+}
+
 func TestGame_Start(t *testing.T) {
 	t.Run("it schedules blind values for 5 players", func(t *testing.T) {
 
@@ -55,7 +80,7 @@ func TestGame_Start(t *testing.T) {
 			{100 * time.Minute, 8000},
 		}
 
-		checkSchedulingCases(cases, t, blindAlerter)
+		checkSchedulingCases(cases, t, scheduledBlinds(blindAlerter, game.BlindClock()))
 
 	})
 
@@ -74,8 +99,70 @@ func TestGame_Start(t *testing.T) {
 			{36 * time.Minute, 400},
 		}
 
-		checkSchedulingCases(cases, t, blindAlerter)
+		checkSchedulingCases(cases, t, scheduledBlinds(blindAlerter, game.BlindClock()))
+
+	})
+
+	t.Run("another game can only start once the last one is over", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(&SpyBlindAlerter{}, dummyPlayerStore)
+
+		poker.AssertNoError(t, game.Start(5, &bytes.Buffer{}))
+		first := game.BlindClock()
+
+		assertStateError(t, game.Start(5, &bytes.Buffer{}), poker.GameRunning, "started")
 
+		game.Abort()
+		poker.AssertNoError(t, game.Start(5, &bytes.Buffer{}))
+		defer game.Finish("Ruth")
+
+		assertClockStopped(t, first)
+
+		if game.BlindClock() == first || !game.BlindClock().Armed() {
+			t.Error("the new game should have its own blind clock running")
+		}
+	})
+}
+
+func TestGame_StartWithBlindStructure(t *testing.T) {
+
+	structure := poker.BlindStructure{
+		Name:          "quick",
+		StartingChips: 2000,
+		Levels: []poker.BlindLevel{
+			{SmallBlind: 25, BigBlind: 50, Duration: poker.Duration(3 * time.Minute)},
+			{Break: true, Duration: poker.Duration(5 * time.Minute)},
+			{SmallBlind: 50, BigBlind: 100, Ante: 10},
+		},
+	}
+
+	blindAlerter := &SpyBlindAlerter{}
+	game := poker.NewTexasHoldEm(blindAlerter, dummyPlayerStore)
+	game.SetBlindStructure(structure)
+
+	game.Start(3, &bytes.Buffer{})
+
+	t.Run("breaks hold back the next level", func(t *testing.T) {
+		cases := []ScheduledAlert{
+			{0 * time.Second, 50},
+			{8 * time.Minute, 100},
+		}
+		got := scheduledBlinds(blindAlerter, game.BlindClock())
+
+		checkSchedulingCases(cases, t, got)
+
+		if len(got) != len(cases) {
+			t.Errorf("got %d alerts scheduled want %d", len(got), len(cases))
+		}
+	})
+
+	t.Run("the first level's blinds are posted from the structure's starting chips", func(t *testing.T) {
+		want := []int{2000, 1975, 1950}
+
+		for seat, s := range game.Betting().Seats() {
+			if s.Chips != want[seat] {
+				t.Errorf("got seat %d with %d chips want %d", seat, s.Chips, want[seat])
+			}
+		}
 	})
 }
 
@@ -89,15 +176,351 @@ func Test_Finish(t *testing.T) {
 	poker.AssertPlayerWin(t, store, winner)
 }
 
-func checkSchedulingCases(cases []ScheduledAlert, t *testing.T, alerter poker.BlindAlerter) {
+func Test_FinishStopsTheBlinds(t *testing.T) {
+	game := poker.NewTexasHoldEm(&SpyBlindAlerter{}, dummyPlayerStore)
+
+	game.Start(5, &bytes.Buffer{})
+
+	if !game.BlindClock().Armed() {
+		t.Fatal("the blind clock should be waiting for the next blind")
+	}
+
+	game.Finish("Ruth")
+
+	assertClockStopped(t, game.BlindClock())
+}
+
+func Test_FinishRecordsGame(t *testing.T) {
+	store := &poker.StubPlayerStore{}
+	game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+
+	before := time.Now()
+	game.Start(5, os.Stdout)
+	game.Finish("Ruth")
+
+	if len(store.Games) != 1 {
+		t.Fatalf("got %d games recorded want 1", len(store.Games))
+	}
+
+	got := store.Games[0]
+
+	if got.ID == "" {
+		t.Errorf("game was not given an id")
+	}
+
+	if got.Started.Before(before) || got.Finished.Before(got.Started) {
+		t.Errorf("game ran from %v to %v, want both after %v", got.Started, got.Finished, before)
+	}
+
+	if got.NumberOfPlayers != 5 || got.Winner != "Ruth" {
+		t.Errorf("got %d players won by %q, want 5 won by Ruth", got.NumberOfPlayers, got.Winner)
+	}
+
+	if got.BlindLevel != 1 || got.Blind != 100 {
+		t.Errorf("got blind level %d at %d, want level 1 at 100", got.BlindLevel, got.Blind)
+	}
+}
+
+func Test_PlaysThroughTheBlinds(t *testing.T) {
+	store := &poker.StubPlayerStore{}
+	out := &bytes.Buffer{}
+	clock := poker.NewFakeClock(time.Date(2019, 10, 1, 20, 0, 0, 0, time.UTC))
+	game := poker.NewTexasHoldEm(poker.NewBlindAlerter(clock), store)
+	game.SetClock(clock)
+
+	game.Start(5, out)
+	clock.Advance(25 * time.Minute)
+
+	want := "Blind is now 100\nBlind is now 200\nBlind is now 300\n"
+
+	if out.String() != want {
+		t.Errorf("got %q announced want %q", out.String(), want)
+	}
+
+	game.Finish("Ruth")
+
+	if clock.Timers() != 0 {
+		t.Errorf("got %d timers left after the game finished want none", clock.Timers())
+	}
+
+	got := store.Games[0]
+
+	if got.Finished.Sub(got.Started) != 25*time.Minute || got.BlindLevel != 3 || got.Blind != 300 {
+		t.Errorf("got a game of %v finished at level %d at %d, want 25m0s at level 3 at 300",
+			got.Finished.Sub(got.Started), got.BlindLevel, got.Blind)
+	}
+
+	clock.Advance(time.Hour)
+
+	if out.String() != want {
+		t.Errorf("got %q announced after the game finished", out.String())
+	}
+}
+
+func Test_FinishRecordsParticipants(t *testing.T) {
+	store := &poker.StubPlayerStore{}
+	game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+
+	game.Start(3, os.Stdout, "Chris", "Cleo", "Ruth")
+	game.Finish("Ruth")
+
+	poker.AssertPlayerWin(t, store, "Ruth")
+
+	got := store.Games[0].Players
+	want := []string{"Chris", "Cleo", "Ruth"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got players %v want %v", got, want)
+	}
+}
+
+func Test_FinishRecordsLeague(t *testing.T) {
+	store := &poker.StubPlayerStore{}
+	game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+
+	game.SetLeague("office")
+	game.Start(2, os.Stdout)
+	game.Finish("Ruth")
+
+	if got := store.Games[0].League; got != "office" {
+		t.Errorf("got game recorded in league %q want %q", got, "office")
+	}
+}
+
+func Test_DealsReproducibly(t *testing.T) {
+
+	newSeededGame := func(out io.Writer) *poker.TexasHoldEm {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetRandom(poker.NewRandom(2019))
+		game.Start(4, out)
+		return game
+	}
+
+	first, second := newSeededGame(&bytes.Buffer{}), newSeededGame(&bytes.Buffer{})
+
+	for seat := 0; seat < 4; seat++ {
+		if !reflect.DeepEqual(first.Hand().Hole(seat), second.Hand().Hole(seat)) {
+			t.Errorf("seat %d got %v and %v from the same seed", seat, first.Hand().Hole(seat), second.Hand().Hole(seat))
+		}
+	}
+
+	out := &bytes.Buffer{}
+	game := newSeededGame(out)
+
+	for i := 0; i < 3; i++ {
+		checkAround(t, game)
+		poker.AssertNoError(t, game.NextStreet())
+	}
+
+	board := game.Hand().Board()
+	streets := []string{
+		fmt.Sprintf("flop: %v %v %v\n", board[0], board[1], board[2]),
+		fmt.Sprintf("turn: %v %v %v %v\n", board[0], board[1], board[2], board[3]),
+		fmt.Sprintf("river: %v %v %v %v %v\n", board[0], board[1], board[2], board[3], board[4]),
+	}
+
+	for _, want := range streets {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("got %q announced want it to contain %q", out.String(), want)
+		}
+	}
+
+	checkAround(t, game)
+	poker.AssertNoError(t, game.NextStreet())
+
+	if game.Hand().Street() != poker.Showdown {
+		t.Errorf("got street %v want %v", game.Hand().Street(), poker.Showdown)
+	}
+}
+
+func Test_RejectedStartDealsNothing(t *testing.T) {
+
+	holeCards := func(rejectStart bool) []poker.Card {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetRandom(poker.NewRandom(2019))
+		game.SetTournament(poker.FreezeOut)
+		game.Start(3, &bytes.Buffer{}, "Ruth", "Chris", "Cleo")
+
+		if rejectStart {
+			assertStateError(t, game.Start(3, &bytes.Buffer{}, "Ruth", "Chris", "Cleo"), poker.GameRunning, "started")
+		}
+
+		game.Abort()
+		game.Start(3, &bytes.Buffer{}, "Ruth", "Chris", "Cleo")
+
+		return game.Hand().Hole(0)
+	}
+
+	if got, want := holeCards(true), holeCards(false); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v dealt after a start was rejected want %v", got, want)
+	}
+}
+
+func Test_ShowdownFindsTheWinners(t *testing.T) {
+
+	t.Run("names the winners of a hand between named players", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetRandom(poker.NewRandom(2019))
+		game.Start(3, out, "Chris", "Cleo", "Ruth")
+
+		for i := 0; i < 3; i++ {
+			checkAround(t, game)
+			poker.AssertNoError(t, game.NextStreet())
+		}
+
+		if game.Winners() != nil {
+			t.Errorf("got winners %v before the showdown", game.Winners())
+		}
+
+		checkAround(t, game)
+		poker.AssertNoError(t, game.NextStreet())
+
+		seats, _, _ := game.Hand().Showdown()
+		want := []string{}
+		for _, seat := range seats {
+			want = append(want, []string{"Chris", "Cleo", "Ruth"}[seat])
+		}
+
+		if !reflect.DeepEqual(game.Winners(), want) {
+			t.Errorf("got winners %v want %v", game.Winners(), want)
+		}
+
+		if !strings.Contains(out.String(), "showdown: "+want[0]+" (") {
+			t.Errorf("got %q announced, want the showdown won by %s", out.String(), want[0])
+		}
+	})
+
+	t.Run("announces seats when the players weren't named", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.Start(3, out)
+
+		for i := 0; i < 4; i++ {
+			checkAround(t, game)
+			poker.AssertNoError(t, game.NextStreet())
+		}
+
+		if game.Winners() != nil {
+			t.Errorf("got winners %v without names", game.Winners())
+		}
+
+		if !strings.Contains(out.String(), "showdown: seat ") {
+			t.Errorf("got %q announced, want the showdown won by a seat", out.String())
+		}
+	})
+}
+
+func Test_Betting(t *testing.T) {
+
+	t.Run("announces each action and who it was by", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.Start(3, out, "Chris", "Cleo", "Ruth")
+
+		poker.AssertNoError(t, game.Act(poker.Action{Type: poker.Raise, Amount: 600}))
+		poker.AssertNoError(t, game.Act(poker.Action{Type: poker.Call}))
+
+		want := "Chris: raise 600\nCleo: call\n"
+
+		if out.String() != want {
+			t.Errorf("got %q announced want %q", out.String(), want)
+		}
+	})
+
+	t.Run("won't deal the next street until the betting is over", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.Start(3, &bytes.Buffer{})
+
+		if err := game.NextStreet(); err != poker.ErrBettingOpen {
+			t.Errorf("got error %v want %v", err, poker.ErrBettingOpen)
+		}
+	})
+
+	t.Run("the last player left wins when everyone else folds", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.Start(3, out, "Chris", "Cleo", "Ruth")
+
+		poker.AssertNoError(t, game.Act(poker.Action{Type: poker.Fold}))
+		poker.AssertNoError(t, game.Act(poker.Action{Type: poker.Fold}))
+
+		if !reflect.DeepEqual(game.Winners(), []string{"Ruth"}) {
+			t.Errorf("got winners %v want Ruth", game.Winners())
+		}
+
+		if !strings.Contains(out.String(), "Ruth wins 150 as everyone else folded\n") {
+			t.Errorf("got %q announced, want Ruth to win the blinds", out.String())
+		}
+
+		if got := game.Betting().Seats()[2].Chips; got != poker.DefaultStartingChips+50 {
+			t.Errorf("got Ruth with %d chips want %d", got, poker.DefaultStartingChips+50)
+		}
+	})
+
+	t.Run("needs a hand to bet on", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+
+		assertStateError(t, game.Act(poker.Action{Type: poker.Check}), poker.GameCreated, "played")
+
+		game.Start(1, &bytes.Buffer{})
+		defer game.Finish("Ruth")
+
+		if err := game.Act(poker.Action{Type: poker.Check}); err != poker.ErrNoHand {
+			t.Errorf("got error %v want %v", err, poker.ErrNoHand)
+		}
+	})
+
+	t.Run("won't start a game whose players have no chips to bet", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetStartingChips(0)
+
+		if err := game.Start(3, &bytes.Buffer{}); err != poker.ErrNoChips {
+			t.Fatalf("got error %v want %v", err, poker.ErrNoChips)
+		}
+
+		if game.State() != poker.GameCreated {
+			t.Errorf("got the game %v want it still %v", game.State(), poker.GameCreated)
+		}
+
+		assertStateError(t, game.NextStreet(), poker.GameCreated, "played")
+	})
+}
+
+func Test_NextStreetNeedsAHand(t *testing.T) {
+	game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+
+	assertStateError(t, game.NextStreet(), poker.GameCreated, "played")
+
+	game.Start(1, &bytes.Buffer{})
+	defer game.Finish("Ruth")
+
+	if err := game.NextStreet(); err != poker.ErrNoHand {
+		t.Errorf("got error %v want %v", err, poker.ErrNoHand)
+	}
+}
+
+// checkAround calls or checks for every player until the betting on the street is over
+func checkAround(t *testing.T, game *poker.TexasHoldEm) {
+	t.Helper()
+	for game.Betting().ToAct() >= 0 {
+		action := poker.Action{Type: poker.Check}
+		if game.Betting().ToCall() > 0 {
+			action.Type = poker.Call
+		}
+		poker.AssertNoError(t, game.Act(action))
+	}
+}
+
+func checkSchedulingCases(cases []ScheduledAlert, t *testing.T, alerts []ScheduledAlert) {
 	t.Helper()
 	for i, want := range cases {
 		t.Run(fmt.Sprintf(want.String()), func(t *testing.T) {
-			if len(cases) <= i {
-				t.Fatalf("alert %d was not scheduled %v", i, cases)
+			if len(alerts) <= i {
+				t.Fatalf("alert %d was not scheduled %v", i, alerts)
 			}
 
-			got := cases[i]
+			got := alerts[i]
 
 			assertScheduledAlert(t, got, want)
 		})
@@ -111,3 +534,63 @@ func assertScheduledAlert(t *testing.T, got, want ScheduledAlert) {
 		t.Errorf("got %v, want %v", got, want)
 	}
 }
+
+func TestGame_Lifecycle(t *testing.T) {
+
+	t.Run("a game that hasn't started can't be finished, and isn't recorded", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+
+		assertStateError(t, game.Finish("Ruth"), poker.GameCreated, "finished")
+
+		if len(store.Games) != 0 {
+			t.Errorf("got %v recorded want no games", store.Games)
+		}
+	})
+
+	t.Run("pausing the game pauses its blinds and its betting", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.Start(3, &bytes.Buffer{})
+		defer game.Finish("Ruth")
+
+		poker.AssertNoError(t, game.Pause())
+
+		if game.State() != poker.GamePaused || !game.BlindClock().Paused() {
+			t.Errorf("got the game %v and its blinds paused %v, want both paused", game.State(), game.BlindClock().Paused())
+		}
+
+		assertStateError(t, game.Act(poker.Action{Type: poker.Call}), poker.GamePaused, "played")
+		assertStateError(t, game.Pause(), poker.GamePaused, "paused")
+
+		poker.AssertNoError(t, game.Resume())
+
+		if game.State() != poker.GameRunning || game.BlindClock().Paused() {
+			t.Errorf("got the game %v and its blinds paused %v, want both running", game.State(), game.BlindClock().Paused())
+		}
+
+		assertStateError(t, game.Resume(), poker.GameRunning, "resumed")
+	})
+
+	t.Run("aborting the game stops its blinds without recording it", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+		game.Start(3, &bytes.Buffer{})
+
+		poker.AssertNoError(t, game.Abort())
+
+		assertClockStopped(t, game.BlindClock())
+		assertStateError(t, game.Finish("Ruth"), poker.GameAborted, "finished")
+
+		if len(store.Games) != 0 {
+			t.Errorf("got %v recorded want no games", store.Games)
+		}
+	})
+}
+
+func assertStateError(t *testing.T, err error, state poker.GameState, action string) {
+	t.Helper()
+
+	if want := (poker.StateError{State: state, Action: action}); err != want {
+		t.Errorf("got error %v want %v", err, want)
+	}
+}
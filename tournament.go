@@ -0,0 +1,457 @@
+package poker
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// TournamentRules are how long a tournament's players can enter late and rebuy for, counted in blind levels,
+// when they can take an add-on, and how many players are seated at each table, which is the DefaultTableSize
+// if it's 0. The zero TournamentRules is a freeze-out.
+type TournamentRules struct {
+	RebuyLevels int
+	MaxRebuys   int
+	AddOnLevel  int
+	TableSize   int
+}
+
+// FreezeOut is a tournament that's entered once, without rebuys or add-ons
+var FreezeOut = TournamentRules{}
+
+// Standing is where a player finished in a tournament, or is 0 while they're still in it
+type Standing struct {
+	Name     string
+	Position int  `json:",omitempty"`
+	Rebuys   int  `json:",omitempty"`
+	AddOn    bool `json:",omitempty"`
+	OutAt    int  `json:",omitempty"`
+}
+
+// The commands players send to run a tournament, each followed by a player's name,
+// apart from eliminations which are the name followed by TournamentOut
+const (
+	EnterCommand  = "enter"
+	RebuyCommand  = "rebuy"
+	AddOnCommand  = "add-on"
+	TournamentOut = "out"
+)
+
+// TablesCommand is the command players send to see who's seated at each table of a tournament
+const TablesCommand = "tables"
+
+// Tournament keeps track of the players who enter a tournament, the order they're eliminated in and
+// their rebuys and add-ons, from which it works out where everyone finished. Players can enter late and
+// rebuy up to the end of the rules' RebuyLevels, and take a single add-on during the AddOnLevel.
+type Tournament struct {
+	rules    TournamentRules
+	level    func() int
+	entrants []*Standing
+	out      []*Standing
+	finished bool
+	seating  *Seating
+}
+
+// NewTournament starts a tournament between players, where level is the blind level being played
+func NewTournament(rules TournamentRules, level func() int, players ...string) (*Tournament, error) {
+
+	if len(players) < 2 {
+		return nil, ErrTournamentPlayers
+	}
+
+	tournament := &Tournament{rules: rules, level: level}
+
+	for _, name := range players {
+		if tournament.find(name) != nil {
+			return nil, ErrAlreadyEntered
+		}
+		tournament.entrants = append(tournament.entrants, &Standing{Name: name})
+	}
+
+	return tournament, nil
+}
+
+// DrawSeats seats the players at tables of the rules' TableSize, drawn by random, splitting them between as
+// many tables as they need. From then on tables are broken and balanced as players are knocked out, while every
+// table plays to the tournament's one blind clock.
+func (t *Tournament) DrawSeats(random *rand.Rand) error {
+
+	size := t.rules.TableSize
+
+	if size == 0 {
+		size = DefaultTableSize
+	}
+
+	seating, err := NewSeating(size, random, t.Left()...)
+
+	if err != nil {
+		return err
+	}
+
+	t.seating = seating
+
+	return nil
+}
+
+// Tables are the tables being played and who's seated at them, or nil if seats haven't been drawn
+func (t *Tournament) Tables() []Table {
+	if t.seating == nil {
+		return nil
+	}
+	return t.seating.Tables()
+}
+
+// Reseated returns the players who've been seated or moved table since it was last called, and reports
+// whether they've just been brought together at the final table
+func (t *Tournament) Reseated() ([]Move, bool) {
+	if t.seating == nil {
+		return nil, false
+	}
+	return t.seating.Reseated()
+}
+
+// Enter enters another player into the tournament while it's still taking rebuys
+func (t *Tournament) Enter(name string) error {
+
+	if t.find(name) != nil {
+		return ErrAlreadyEntered
+	}
+
+	if t.finished || t.level() > t.rules.RebuyLevels {
+		return ErrEntriesClosed
+	}
+
+	t.entrants = append(t.entrants, &Standing{Name: name})
+
+	if t.seating != nil {
+		t.seating.Seat(name)
+	}
+
+	return nil
+}
+
+// Eliminate knocks name out of the tournament. Once a single player is left the tournament is over.
+func (t *Tournament) Eliminate(name string) error {
+
+	entrant, err := t.playing(name)
+
+	if err != nil {
+		return err
+	}
+
+	entrant.OutAt = t.level()
+	t.out = append(t.out, entrant)
+
+	if t.seating != nil {
+		return t.seating.Remove(name)
+	}
+
+	return nil
+}
+
+// Rebuy buys name back into the tournament after they've been eliminated, while rebuys are open. The
+// player knocked out heads-up can rebuy too, as long as the tournament hasn't been finished.
+func (t *Tournament) Rebuy(name string) error {
+
+	entrant := t.find(name)
+
+	if entrant == nil {
+		return ErrUnknownEntrant
+	}
+
+	if t.finished || t.level() > t.rules.RebuyLevels {
+		return ErrRebuysClosed
+	}
+
+	i := t.outIndex(entrant)
+
+	if i < 0 {
+		return ErrNotEliminated
+	}
+
+	if t.rules.MaxRebuys > 0 && entrant.Rebuys >= t.rules.MaxRebuys {
+		return ErrRebuyLimit
+	}
+
+	t.out = append(t.out[:i], t.out[i+1:]...)
+	entrant.OutAt = 0
+	entrant.Rebuys++
+
+	if t.seating != nil {
+		t.seating.Seat(name)
+	}
+
+	return nil
+}
+
+// AddOn gives name their add-on, which they can take once during the add-on level while they're still in
+func (t *Tournament) AddOn(name string) error {
+
+	entrant, err := t.playing(name)
+
+	if err != nil {
+		return err
+	}
+
+	if t.rules.AddOnLevel == 0 || t.level() != t.rules.AddOnLevel {
+		return ErrAddOnClosed
+	}
+
+	if entrant.AddOn {
+		return ErrAddOnTaken
+	}
+
+	entrant.AddOn = true
+
+	return nil
+}
+
+// Left is the names of the players still in the tournament, in the order they entered
+func (t *Tournament) Left() []string {
+
+	var left []string
+
+	for _, entrant := range t.entrants {
+		if t.outIndex(entrant) < 0 {
+			left = append(left, entrant.Name)
+		}
+	}
+
+	return left
+}
+
+// Winner is the last player left, or empty while there's still more than one
+func (t *Tournament) Winner() string {
+	if left := t.Left(); len(left) == 1 {
+		return left[0]
+	}
+	return ""
+}
+
+// Finish ends the tournament, which winner must have won by being the last player left
+func (t *Tournament) Finish(winner string) error {
+
+	if t.Winner() == "" {
+		return ErrTournamentNotOver
+	}
+
+	if t.Winner() != winner {
+		return ErrWrongWinner
+	}
+
+	t.finished = true
+
+	return nil
+}
+
+// Standings returns every entrant, those still in first in the order they entered, followed by those
+// who have been eliminated from the best finish down. The last player left finishes first.
+func (t *Tournament) Standings() []Standing {
+
+	var standings []Standing
+
+	for _, name := range t.Left() {
+		standing := *t.find(name)
+
+		if t.over() {
+			standing.Position = 1
+		}
+
+		standings = append(standings, standing)
+	}
+
+	for i := len(t.out) - 1; i >= 0; i-- {
+		standing := *t.out[i]
+		standing.Position = len(t.entrants) - i
+		standings = append(standings, standing)
+	}
+
+	return standings
+}
+
+// Players is the names of everyone who entered
+func (t *Tournament) Players() []string {
+
+	names := make([]string, len(t.entrants))
+
+	for i, entrant := range t.entrants {
+		names[i] = entrant.Name
+	}
+
+	return names
+}
+
+func (t *Tournament) over() bool {
+	return t.finished || len(t.entrants)-len(t.out) <= 1
+}
+
+// playing returns name's entry as long as they're still in a tournament that isn't over
+func (t *Tournament) playing(name string) (*Standing, error) {
+
+	entrant := t.find(name)
+
+	if entrant == nil {
+		return nil, ErrUnknownEntrant
+	}
+
+	if t.over() {
+		return nil, ErrTournamentOver
+	}
+
+	if t.outIndex(entrant) >= 0 {
+		return nil, ErrEliminated
+	}
+
+	return entrant, nil
+}
+
+func (t *Tournament) find(name string) *Standing {
+	for _, entrant := range t.entrants {
+		if entrant.Name == name {
+			return entrant
+		}
+	}
+	return nil
+}
+
+func (t *Tournament) outIndex(entrant *Standing) int {
+	for i, out := range t.out {
+		if out == entrant {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseTournamentCommand reads a tournament command and the player it's for from input,
+// reporting whether it was one. Only a tournament has players to eliminate, so anything else
+// ending in TournamentOut is left to be read as something else.
+func parseTournamentCommand(tournament *Tournament, input string) (string, string, bool) {
+
+	input = strings.TrimSpace(input)
+
+	if name := strings.TrimSuffix(input, " "+TournamentOut); tournament != nil && name != input && name != "" {
+		return TournamentOut, strings.TrimSpace(name), true
+	}
+
+	for _, command := range []string{EnterCommand, RebuyCommand, AddOnCommand} {
+		if name := strings.TrimPrefix(input, command+" "); name != input && name != "" {
+			return command, strings.TrimSpace(name), true
+		}
+	}
+
+	return "", "", false
+}
+
+// runTournamentCommand enters, eliminates, rebuys or gives an add-on to name as command says.
+// Only players still seated in the tournament can be eliminated.
+func runTournamentCommand(tournament *Tournament, command, name string) error {
+
+	if tournament == nil {
+		return ErrNotTournament
+	}
+
+	switch command {
+	case EnterCommand:
+		return tournament.Enter(name)
+	case RebuyCommand:
+		return tournament.Rebuy(name)
+	case AddOnCommand:
+		return tournament.AddOn(name)
+	}
+
+	return tournament.Eliminate(name)
+}
+
+// announceSeating writes the players who've been seated or moved table to out, along with the
+// final table once they've been brought together at it
+func announceSeating(tournament *Tournament, out io.Writer) {
+
+	moves, final := tournament.Reseated()
+
+	for _, move := range moves {
+		fmt.Fprintln(out, move)
+	}
+
+	if tables := tournament.Tables(); final && len(tables) == 1 {
+		fmt.Fprintf(out, "final table: %s\n", strings.Join(tables[0].Players, ", "))
+	}
+}
+
+// printTables writes who's seated at each of the tournament's tables to out
+func printTables(tournament *Tournament, out io.Writer) error {
+
+	if tournament == nil {
+		return ErrNotTournament
+	}
+
+	PrintTables(out, tournament.Tables())
+
+	return nil
+}
+
+// ParseTournamentRules reads tournament rules written as "freeze-out", or as a comma separated list of
+// rebuys={levels}, max-rebuys={number}, add-on={level} and table-size={players}, such as "rebuys=4,add-on=5"
+func ParseTournamentRules(input string) (TournamentRules, error) {
+
+	var rules TournamentRules
+
+	if input == "freeze-out" {
+		return rules, nil
+	}
+
+	for _, setting := range strings.Split(input, ",") {
+		parts := strings.SplitN(strings.TrimSpace(setting), "=", 2)
+
+		if len(parts) != 2 {
+			return rules, ErrBadTournamentRules
+		}
+
+		value, err := strconv.Atoi(parts[1])
+
+		if err != nil || value < 0 {
+			return rules, ErrBadTournamentRules
+		}
+
+		switch parts[0] {
+		case "rebuys":
+			rules.RebuyLevels = value
+		case "max-rebuys":
+			rules.MaxRebuys = value
+		case "add-on":
+			rules.AddOnLevel = value
+		case "table-size":
+			if value < 2 {
+				return rules, ErrBadTournamentRules
+			}
+			rules.TableSize = value
+		default:
+			return rules, ErrBadTournamentRules
+		}
+	}
+
+	return rules, nil
+}
+
+// ordinal writes a finishing position the way it's said, such as 1st or 22nd
+func ordinal(position int) string {
+
+	suffix := "th"
+
+	switch position % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+
+	if position%100 >= 11 && position%100 <= 13 {
+		suffix = "th"
+	}
+
+	return fmt.Sprintf("%d%s", position, suffix)
+}
@@ -0,0 +1,282 @@
+package poker_test
+
+import (
+	"bytes"
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTournament(t *testing.T) {
+
+	t.Run("players finish in the reverse of the order they're eliminated in", func(t *testing.T) {
+		tournament, _ := poker.NewTournament(poker.FreezeOut, levelOf1, "Ruth", "Chris", "Cleo", "Bob")
+
+		poker.AssertNoError(t, tournament.Eliminate("Chris"))
+		poker.AssertNoError(t, tournament.Eliminate("Bob"))
+
+		if winner := tournament.Winner(); winner != "" {
+			t.Errorf("got winner %q with two players left", winner)
+		}
+
+		assertTournamentError(t, tournament.Finish("Ruth"), poker.ErrTournamentNotOver)
+
+		poker.AssertNoError(t, tournament.Eliminate("Ruth"))
+
+		assertTournamentError(t, tournament.Finish("Ruth"), poker.ErrWrongWinner)
+		poker.AssertNoError(t, tournament.Finish("Cleo"))
+
+		want := []poker.Standing{
+			{Name: "Cleo", Position: 1},
+			{Name: "Ruth", Position: 2, OutAt: 1},
+			{Name: "Bob", Position: 3, OutAt: 1},
+			{Name: "Chris", Position: 4, OutAt: 1},
+		}
+
+		if got := tournament.Standings(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("a freeze-out can't be rebought or entered late", func(t *testing.T) {
+		tournament, _ := poker.NewTournament(poker.FreezeOut, levelOf1, "Ruth", "Chris", "Cleo")
+		tournament.Eliminate("Chris")
+
+		assertTournamentError(t, tournament.Rebuy("Chris"), poker.ErrRebuysClosed)
+		assertTournamentError(t, tournament.Enter("Bob"), poker.ErrEntriesClosed)
+		assertTournamentError(t, tournament.AddOn("Ruth"), poker.ErrAddOnClosed)
+	})
+
+	t.Run("eliminated players rebuy until the rebuy levels are over", func(t *testing.T) {
+		level := 1
+		tournament, _ := poker.NewTournament(poker.TournamentRules{RebuyLevels: 2, MaxRebuys: 1}, func() int { return level }, "Ruth", "Chris", "Cleo")
+
+		assertTournamentError(t, tournament.Rebuy("Chris"), poker.ErrNotEliminated)
+
+		tournament.Eliminate("Chris")
+		poker.AssertNoError(t, tournament.Rebuy("Chris"))
+
+		tournament.Eliminate("Chris")
+		assertTournamentError(t, tournament.Rebuy("Chris"), poker.ErrRebuyLimit)
+
+		level = 2
+		poker.AssertNoError(t, tournament.Enter("Bob"))
+		tournament.Eliminate("Bob")
+
+		level = 3
+		assertTournamentError(t, tournament.Rebuy("Bob"), poker.ErrRebuysClosed)
+		assertTournamentError(t, tournament.Enter("Pete"), poker.ErrEntriesClosed)
+
+		if got := tournament.Left(); !reflect.DeepEqual(got, []string{"Ruth", "Cleo"}) {
+			t.Errorf("got %v left want [Ruth Cleo]", got)
+		}
+
+		want := []poker.Standing{
+			{Name: "Ruth"},
+			{Name: "Cleo"},
+			{Name: "Bob", Position: 3, OutAt: 2},
+			{Name: "Chris", Position: 4, Rebuys: 1, OutAt: 1},
+		}
+
+		if got := tournament.Standings(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("the player knocked out heads-up can rebuy until it's finished", func(t *testing.T) {
+		tournament, _ := poker.NewTournament(poker.TournamentRules{RebuyLevels: 1}, levelOf1, "Ruth", "Chris")
+
+		tournament.Eliminate("Chris")
+		poker.AssertNoError(t, tournament.Rebuy("Chris"))
+
+		if left := tournament.Left(); !reflect.DeepEqual(left, []string{"Ruth", "Chris"}) {
+			t.Errorf("got %v left want Ruth and Chris", left)
+		}
+
+		tournament.Eliminate("Chris")
+		poker.AssertNoError(t, tournament.Finish("Ruth"))
+
+		assertTournamentError(t, tournament.Rebuy("Chris"), poker.ErrRebuysClosed)
+		assertTournamentError(t, tournament.Enter("Bob"), poker.ErrEntriesClosed)
+	})
+
+	t.Run("players still in take one add-on during the add-on level", func(t *testing.T) {
+		level := 1
+		tournament, _ := poker.NewTournament(poker.TournamentRules{RebuyLevels: 1, AddOnLevel: 2}, func() int { return level }, "Ruth", "Chris", "Cleo")
+		tournament.Eliminate("Chris")
+
+		assertTournamentError(t, tournament.AddOn("Ruth"), poker.ErrAddOnClosed)
+
+		level = 2
+		poker.AssertNoError(t, tournament.AddOn("Ruth"))
+		assertTournamentError(t, tournament.AddOn("Ruth"), poker.ErrAddOnTaken)
+		assertTournamentError(t, tournament.AddOn("Chris"), poker.ErrEliminated)
+		assertTournamentError(t, tournament.AddOn("Pete"), poker.ErrUnknownEntrant)
+	})
+
+	t.Run("needs at least two different players", func(t *testing.T) {
+		if _, err := poker.NewTournament(poker.FreezeOut, levelOf1, "Ruth"); err != poker.ErrTournamentPlayers {
+			t.Errorf("got error %v want %v", err, poker.ErrTournamentPlayers)
+		}
+
+		if _, err := poker.NewTournament(poker.FreezeOut, levelOf1, "Ruth", "Ruth"); err != poker.ErrAlreadyEntered {
+			t.Errorf("got error %v want %v", err, poker.ErrAlreadyEntered)
+		}
+	})
+
+	t.Run("no one can be eliminated once it's over", func(t *testing.T) {
+		tournament, _ := poker.NewTournament(poker.FreezeOut, levelOf1, "Ruth", "Chris")
+		tournament.Eliminate("Chris")
+
+		assertTournamentError(t, tournament.Eliminate("Ruth"), poker.ErrTournamentOver)
+	})
+}
+
+func TestParseTournamentRules(t *testing.T) {
+
+	cases := []struct {
+		input string
+		want  poker.TournamentRules
+		err   error
+	}{
+		{"freeze-out", poker.FreezeOut, nil},
+		{"rebuys=4", poker.TournamentRules{RebuyLevels: 4}, nil},
+		{"rebuys=4, max-rebuys=2,add-on=5", poker.TournamentRules{RebuyLevels: 4, MaxRebuys: 2, AddOnLevel: 5}, nil},
+		{"rebuys", poker.TournamentRules{}, poker.ErrBadTournamentRules},
+		{"rebuys=-1", poker.TournamentRules{}, poker.ErrBadTournamentRules},
+		{"bounties=1", poker.TournamentRules{}, poker.ErrBadTournamentRules},
+		{"rebuys=2,table-size=9", poker.TournamentRules{RebuyLevels: 2, TableSize: 9}, nil},
+		{"table-size=1", poker.TournamentRules{}, poker.ErrBadTournamentRules},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			got, err := poker.ParseTournamentRules(c.input)
+
+			if err != c.err {
+				t.Fatalf("got error %v want %v", err, c.err)
+			}
+
+			if err == nil && got != c.want {
+				t.Errorf("got %+v want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestGame_Tournament(t *testing.T) {
+
+	t.Run("needs its players' names", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetTournament(poker.FreezeOut)
+
+		assertTournamentError(t, game.Start(3, &bytes.Buffer{}), poker.ErrTournamentPlayers)
+
+		if game.State() != poker.GameCreated {
+			t.Errorf("got state %v, a tournament that couldn't start shouldn't be running", game.State())
+		}
+	})
+
+	t.Run("is played out from the CLI and recorded with its standings", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+		game.SetRenderer(poker.TextRenderer)
+		game.SetTournament(poker.TournamentRules{RebuyLevels: 1})
+		out := &bytes.Buffer{}
+
+		in := userSends("Ruth,Chris,Cleo", "Chris out", "rebuy Chris", "Cleo out", "add-on Ruth", "Chris out")
+		poker.NewCLI(in, out, game).PlayPoker()
+
+		if len(store.Games) != 1 {
+			t.Fatalf("got %d games recorded want 1", len(store.Games))
+		}
+
+		record := store.Games[0]
+		want := []poker.Standing{
+			{Name: "Ruth", Position: 1},
+			{Name: "Chris", Position: 2, Rebuys: 1, OutAt: 1},
+			{Name: "Cleo", Position: 3, OutAt: 1},
+		}
+
+		if record.Winner != "Ruth" || !reflect.DeepEqual(record.Standings, want) {
+			t.Errorf("got %s winning with %+v want Ruth with %+v", record.Winner, record.Standings, want)
+		}
+
+		wantOut := poker.ErrAddOnClosed.Error() + "\n1st Ruth\n2nd Chris, 1 rebuy\n3rd Cleo\n"
+
+		if !strings.HasSuffix(out.String(), wantOut) {
+			t.Errorf("got %q want it to end %q", out.String(), wantOut)
+		}
+	})
+
+	t.Run("isn't finished by a game that can't be", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetTournament(poker.TournamentRules{RebuyLevels: 1})
+		game.Start(2, &bytes.Buffer{}, "Ruth", "Chris")
+
+		tournament := game.Tournament()
+		tournament.Eliminate("Chris")
+		poker.AssertNoError(t, game.Abort())
+
+		assertStateError(t, game.Finish("Ruth"), poker.GameAborted, "finished")
+		poker.AssertNoError(t, tournament.Rebuy("Chris"))
+	})
+
+	t.Run("tournament commands need a tournament", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		game := &GameSpy{}
+
+		poker.NewCLI(userSends("3", "rebuy Ruth", "Ruth wins"), out, game).PlayPoker()
+
+		assertMessageSentToUser(t, out, poker.PlayerPrompt, poker.ErrNotTournament.Error()+"\n")
+	})
+
+	t.Run("only eliminates players still seated in it", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+		game.SetTournament(poker.FreezeOut)
+		out := &bytes.Buffer{}
+
+		in := userSends("Ruth,Chris,Cleo", "Bob out", "Chris  out ", "Chris out", "Cleo out")
+		poker.NewCLI(in, out, game).PlayPoker()
+
+		for _, err := range []error{poker.ErrUnknownEntrant, poker.ErrEliminated} {
+			if !strings.Contains(out.String(), err.Error()+"\n") {
+				t.Errorf("got %q want it to contain %q", out.String(), err)
+			}
+		}
+
+		if len(store.Games) != 1 || store.Games[0].Winner != "Ruth" {
+			t.Errorf("got %+v recorded want the tournament won by Ruth", store.Games)
+		}
+	})
+}
+
+func TestPrintStandings(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	poker.PrintStandings(out, []poker.Standing{
+		{Name: "Ruth", Position: 1, AddOn: true},
+		{Name: "Chris", Position: 2, Rebuys: 3},
+		{Name: "Cleo", Position: 11},
+		{Name: "Bob", Position: 22},
+	})
+
+	want := "1st Ruth, add-on\n2nd Chris, 3 rebuys\n11th Cleo\n22nd Bob\n"
+
+	if out.String() != want {
+		t.Errorf("got %q want %q", out.String(), want)
+	}
+}
+
+func levelOf1() int {
+	return 1
+}
+
+func assertTournamentError(t *testing.T, got, want error) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got error %v want %v", got, want)
+	}
+}
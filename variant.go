@@ -0,0 +1,288 @@
+package poker
+
+// streetDeal is what's dealt on a street: cards to each seat, face down and then face up, and cards to the board
+type streetDeal struct {
+	street Street
+	down   int
+	up     int
+	board  int
+}
+
+// Variant is a kind of poker: what's dealt on each street, how players make their best hand from the cards
+// they can use, and the betting structure it's played with unless the game says otherwise. Variants share
+// the deck, the hand evaluator and the betting.
+type Variant struct {
+	Name      string
+	streets   []streetDeal
+	mustUse   int
+	structure BettingStructure
+}
+
+// The names of the variants games can be played as
+const (
+	TexasHoldEmName   = "texas-holdem"
+	OmahaName         = "omaha"
+	PotLimitOmahaName = "pot-limit-omaha"
+	SevenCardStudName = "seven-card-stud"
+)
+
+var boardStreets = []streetDeal{{street: Flop, board: 3}, {street: Turn, board: 1}, {street: River, board: 1}}
+
+var (
+	// TexasHoldEmVariant is no-limit Texas Hold'em, where players make their best hand from their two
+	// hole cards and five on the board, using as many of each as they like
+	TexasHoldEmVariant = Variant{
+		Name:    TexasHoldEmName,
+		streets: append([]streetDeal{{street: PreFlop, down: 2}}, boardStreets...),
+	}
+
+	// Omaha is no-limit Omaha, where players are dealt four hole cards and have to use exactly two of
+	// them, with three from the board, to make their best hand
+	Omaha = Variant{
+		Name:    OmahaName,
+		streets: append([]streetDeal{{street: PreFlop, down: 4}}, boardStreets...),
+		mustUse: 2,
+	}
+
+	// PotLimitOmaha is Omaha where no one can bet more than the pot
+	PotLimitOmaha = Variant{
+		Name:      PotLimitOmahaName,
+		streets:   Omaha.streets,
+		mustUse:   2,
+		structure: PotLimit{},
+	}
+
+	// SevenCardStud is played without a board. Players are dealt two cards face down and one face up,
+	// then three more face up and a last one face down, and make their best hand from any five. The
+	// lowest card showing brings in the betting, and on later streets the best hand showing bets first.
+	SevenCardStud = Variant{
+		Name: SevenCardStudName,
+		streets: []streetDeal{
+			{street: ThirdStreet, down: 2, up: 1},
+			{street: FourthStreet, up: 1},
+			{street: FifthStreet, up: 1},
+			{street: SixthStreet, up: 1},
+			{street: SeventhStreet, down: 1},
+		},
+	}
+)
+
+var variants = map[string]Variant{
+	TexasHoldEmName:   TexasHoldEmVariant,
+	OmahaName:         Omaha,
+	PotLimitOmahaName: PotLimitOmaha,
+	SevenCardStudName: SevenCardStud,
+}
+
+// VariantFor returns the variant called name, which is Texas Hold'em if name is empty
+func VariantFor(name string) (Variant, error) {
+
+	if name == "" {
+		return TexasHoldEmVariant, nil
+	}
+
+	variant, ok := variants[name]
+
+	if !ok {
+		return Variant{}, ErrUnknownVariant
+	}
+
+	return variant, nil
+}
+
+// MaxPlayers is the most players a hand of the variant can be dealt to from one deck, burning a card before each street
+func (v Variant) MaxPlayers() int {
+
+	perSeat, board := 0, 0
+
+	for _, dealing := range v.streets {
+		perSeat += dealing.down + dealing.up
+		board += dealing.board
+	}
+
+	return (52 - board - (len(v.streets) - 1)) / perSeat
+}
+
+// Streets are the streets of the variant that are bet on, in the order they're dealt
+func (v Variant) Streets() []Street {
+
+	streets := make([]Street, len(v.streets))
+
+	for i, dealing := range v.streets {
+		streets[i] = dealing.street
+	}
+
+	return streets
+}
+
+// HasBoard reports whether the variant deals cards to a board that every player shares
+func (v Variant) HasBoard() bool {
+	for _, dealing := range v.streets {
+		if dealing.board > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Structure is the betting structure the variant is played with, which is no-limit unless it says otherwise
+func (v Variant) Structure() BettingStructure {
+	if v.structure == nil {
+		return NoLimit{}
+	}
+	return v.structure
+}
+
+// Deal deals the first street of a hand of the variant from deck to numberOfPlayers seats,
+// one card at a time round the table, starting at seat 0
+func (v Variant) Deal(deck *Deck, numberOfPlayers int) (*Deal, error) {
+
+	if numberOfPlayers < 2 || numberOfPlayers > v.MaxPlayers() {
+		return nil, ErrDealPlayers
+	}
+
+	d := &Deal{
+		deck:    deck,
+		variant: v,
+		hole:    make([][]Card, numberOfPlayers),
+		up:      make([][]Card, numberOfPlayers),
+	}
+
+	if err := d.deal(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Value is the value of the best hand that can be made from hole cards and the board the way the variant says
+func (v Variant) Value(hole, board []Card) (HandValue, error) {
+	if v.mustUse > 0 {
+		return EvaluateUsing(hole, board, v.mustUse)
+	}
+	return Evaluate(append(copyCards(hole), board...))
+}
+
+// NewBetting starts the betting on hand between seats with the chips in stacks at level. Hands with a board
+// have a button and blinds, and stud hands a bring-in. Bets are limited by the variant's betting structure.
+func (v Variant) NewBetting(hand *Deal, stacks []int, button int, level BlindLevel) (*Betting, error) {
+
+	var betting *Betting
+	var err error
+
+	if v.HasBoard() {
+		betting, err = NewBetting(stacks, button, level)
+	} else {
+		betting, err = NewStudBetting(stacks, hand.BringIn(), level, hand.ShowingValue)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	betting.SetStructure(v.Structure())
+
+	return betting, nil
+}
+
+// EvaluateUsing returns the value of the best five card hand made from exactly use of the hole cards and
+// the rest from the board, as in Omaha where players have to use two of their hole cards
+func EvaluateUsing(hole, board []Card, use int) (HandValue, error) {
+
+	if len(hole) < use || len(board) < 5-use || use < 0 || use > 5 {
+		return 0, ErrHandSize
+	}
+
+	var best HandValue
+
+	for _, fromHole := range combinations(hole, use) {
+		for _, fromBoard := range combinations(board, 5-use) {
+			value, err := Evaluate(append(fromHole, fromBoard...))
+
+			if err != nil {
+				return 0, err
+			}
+
+			if value > best {
+				best = value
+			}
+		}
+	}
+
+	return best, nil
+}
+
+// combinations returns every way of choosing n of cards, keeping them in order
+func combinations(cards []Card, n int) [][]Card {
+
+	if n == 0 {
+		return [][]Card{{}}
+	}
+
+	var chosen [][]Card
+
+	for i := 0; i <= len(cards)-n; i++ {
+		for _, rest := range combinations(cards[i+1:], n-1) {
+			chosen = append(chosen, append([]Card{cards[i]}, rest...))
+		}
+	}
+
+	return chosen
+}
+
+// BringIn is the seat with the lowest card showing, which brings in the betting on a stud hand. Ties go to the lowest suit.
+func (d *Deal) BringIn() int {
+
+	bringIn := 0
+
+	for seat, up := range d.up {
+		lowest, card := d.up[bringIn][0], up[0]
+
+		if card.Rank < lowest.Rank || (card.Rank == lowest.Rank && card.Suit < lowest.Suit) {
+			bringIn = seat
+		}
+	}
+
+	return bringIn
+}
+
+// ShowingValue is how good the cards showing in seat are, the best of which bets first on a stud hand's
+// later streets. Only pairs, trips and quads count, as there are too few cards for straights and flushes.
+func (d *Deal) ShowingValue(seat int) HandValue {
+
+	if len(d.up[seat]) == 0 {
+		return 0
+	}
+
+	var counts [Ace + 1]int
+
+	for _, card := range d.up[seat] {
+		counts[card.Rank]++
+	}
+
+	// ranks in the order they break ties: the biggest group first, then the highest rank
+	var ranks []Rank
+
+	for size := 4; size >= 1; size-- {
+		for rank := Ace; rank >= Two; rank-- {
+			if counts[rank] == size {
+				ranks = append(ranks, rank)
+			}
+		}
+	}
+
+	category := HighCard
+
+	switch {
+	case counts[ranks[0]] == 4:
+		category = FourOfAKind
+	case counts[ranks[0]] == 3:
+		category = ThreeOfAKind
+	case counts[ranks[0]] == 2 && len(ranks) > 1 && counts[ranks[1]] == 2:
+		category = TwoPair
+	case counts[ranks[0]] == 2:
+		category = OnePair
+	}
+
+	return handValue(category, ranks...)
+}
@@ -0,0 +1,318 @@
+package poker_test
+
+import (
+	"bytes"
+	"github.com/vetch101/go-tddapp"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVariantFor(t *testing.T) {
+
+	cases := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{"", poker.TexasHoldEmName, nil},
+		{"texas-holdem", poker.TexasHoldEmName, nil},
+		{"omaha", poker.OmahaName, nil},
+		{"pot-limit-omaha", poker.PotLimitOmahaName, nil},
+		{"seven-card-stud", poker.SevenCardStudName, nil},
+		{"razz", "", poker.ErrUnknownVariant},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := poker.VariantFor(c.name)
+
+			if err != c.err {
+				t.Fatalf("got error %v want %v", err, c.err)
+			}
+
+			if got.Name != c.want {
+				t.Errorf("got %q want %q", got.Name, c.want)
+			}
+		})
+	}
+}
+
+func TestVariantDeal(t *testing.T) {
+
+	t.Run("a deck deals to as many players as it has cards for", func(t *testing.T) {
+		cases := map[string]int{
+			poker.TexasHoldEmName:   22,
+			poker.OmahaName:         11,
+			poker.SevenCardStudName: 6,
+		}
+
+		for name, want := range cases {
+			variant, _ := poker.VariantFor(name)
+
+			if got := variant.MaxPlayers(); got != want {
+				t.Errorf("got %d players for %s want %d", got, name, want)
+			}
+
+			if _, err := variant.Deal(poker.NewDeck(), want+1); err != poker.ErrDealPlayers {
+				t.Errorf("got error %v dealing %s to %d want %v", err, name, want+1, poker.ErrDealPlayers)
+			}
+		}
+	})
+
+	t.Run("omaha deals four hole cards and a board of five", func(t *testing.T) {
+		deal, err := poker.Omaha.Deal(poker.NewDeck(), 4)
+		poker.AssertNoError(t, err)
+
+		for seat := 0; seat < 4; seat++ {
+			if len(deal.Hole(seat)) != 4 {
+				t.Errorf("got seat %d dealt %v want four cards", seat, deal.Hole(seat))
+			}
+		}
+
+		for i := 0; i < 3; i++ {
+			poker.AssertNoError(t, deal.Next())
+		}
+
+		if len(deal.Board()) != 5 || deal.Street() != poker.River {
+			t.Errorf("got %v on the %v want five cards on the river", deal.Board(), deal.Street())
+		}
+	})
+
+	t.Run("seven card stud deals seven cards, four of them face up, and no board", func(t *testing.T) {
+		deal, err := poker.SevenCardStud.Deal(poker.NewDeck(), 6)
+		poker.AssertNoError(t, err)
+
+		streets := []poker.Street{poker.ThirdStreet, poker.FourthStreet, poker.FifthStreet, poker.SixthStreet, poker.SeventhStreet}
+		showing := []int{1, 2, 3, 4, 4}
+
+		for i, want := range streets {
+			if i > 0 {
+				poker.AssertNoError(t, deal.Next())
+			}
+
+			if deal.Street() != want || len(deal.Hole(0)) != i+3 || len(deal.Showing(5)) != showing[i] {
+				t.Errorf("got %v with %v showing %v want %v", deal.Street(), deal.Hole(0), deal.Showing(5), want)
+			}
+		}
+
+		poker.AssertNoError(t, deal.Next())
+
+		if deal.Street() != poker.Showdown || len(deal.Board()) != 0 {
+			t.Errorf("got %v with board %v want a showdown without a board", deal.Street(), deal.Board())
+		}
+
+		if got := poker.SevenCardStud.Streets(); !reflect.DeepEqual(got, streets) {
+			t.Errorf("got streets %v want %v", got, streets)
+		}
+	})
+}
+
+func TestEvaluateUsing(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		hole  string
+		board string
+		want  poker.HandCategory
+	}{
+		{"has to use two hole cards, not four to a royal flush", "As Ks Qs Js", "Ts 9s 2h 3d 4c", poker.HighCard},
+		{"has to use two hole cards, not one to a flush", "Ah 2c 7c 8d", "Kh Qh Jh Th 3c", poker.HighCard},
+		{"a board straight doesn't play by itself", "2c 2d 7h 7s", "9c Tc Jd Qh Ks", poker.OnePair},
+		{"makes a full house from a pair and the board's trips", "Kc Kd 2h 3s", "9c 9d 9h 4s 5c", poker.FullHouse},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cards := mustParseHands(t, c.hole, c.board)
+
+			got, err := poker.EvaluateUsing(cards[0], cards[1], 2)
+			poker.AssertNoError(t, err)
+
+			if got.Category() != c.want {
+				t.Errorf("got %v want %v", got, c.want)
+			}
+
+			value, _ := poker.Omaha.Value(cards[0], cards[1])
+
+			if value != got {
+				t.Errorf("got %v valued in omaha want %v", value, got)
+			}
+		})
+	}
+
+	t.Run("needs enough cards to make a hand", func(t *testing.T) {
+		cards := mustParseHands(t, "As", "Ks Qs Js Ts")
+
+		if _, err := poker.EvaluateUsing(cards[0], cards[1], 2); err != poker.ErrHandSize {
+			t.Errorf("got error %v want %v", err, poker.ErrHandSize)
+		}
+	})
+}
+
+func TestStudShowing(t *testing.T) {
+
+	t.Run("the lowest card showing brings in the betting, and the highest bets first", func(t *testing.T) {
+		// an unshuffled deck shows 8c, 9c and Tc on third street, then Qc, Kc and Ac on fourth
+		deal, _ := poker.SevenCardStud.Deal(poker.NewDeck(), 3)
+
+		if deal.BringIn() != 0 {
+			t.Errorf("got seat %d bringing in with %v want seat 0", deal.BringIn(), deal.Showing(deal.BringIn()))
+		}
+
+		poker.AssertNoError(t, deal.Next())
+
+		if !(deal.ShowingValue(2) > deal.ShowingValue(1) && deal.ShowingValue(1) > deal.ShowingValue(0)) {
+			t.Errorf("got %v, %v and %v showing want them valued highest last", deal.Showing(0), deal.Showing(1), deal.Showing(2))
+		}
+	})
+
+	t.Run("pairs and trips showing beat high cards", func(t *testing.T) {
+		for seed := int64(1); seed <= 20; seed++ {
+			deck := poker.NewDeck()
+			deck.Shuffle(poker.NewRandom(seed))
+			deal, _ := poker.SevenCardStud.Deal(deck, 6)
+
+			for i := 0; i < 3; i++ {
+				poker.AssertNoError(t, deal.Next())
+			}
+
+			for seat := 0; seat < 6; seat++ {
+				if got, want := deal.ShowingValue(seat).Category(), showingCategory(deal.Showing(seat)); got != want {
+					t.Errorf("got %v showing %v want %v", got, deal.Showing(seat), want)
+				}
+			}
+		}
+	})
+}
+
+func TestGame_Variants(t *testing.T) {
+
+	t.Run("plays omaha hands to a showdown of the best hands using two hole cards", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetVariant(poker.Omaha)
+		game.SetRandom(poker.NewRandom(2019))
+		game.Start(4, out, "Chris", "Cleo", "Ruth", "Bob")
+
+		for i := 0; i < 4; i++ {
+			checkAround(t, game)
+			poker.AssertNoError(t, game.NextStreet())
+		}
+
+		board := game.Hand().Board()
+		var best poker.HandValue
+
+		for seat := 0; seat < 4; seat++ {
+			value, _ := poker.EvaluateUsing(game.Hand().Hole(seat), board, 2)
+			if value > best {
+				best = value
+			}
+		}
+
+		if !strings.Contains(out.String(), "with "+best.String()) {
+			t.Errorf("got %q announced want the showdown won with %v", out.String(), best)
+		}
+	})
+
+	t.Run("plays seven card stud, announcing the cards showing on each street", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		out := &bytes.Buffer{}
+		game := poker.NewTexasHoldEm(dummySpyAlerter, store)
+		game.SetVariant(poker.SevenCardStud)
+		game.SetRandom(poker.NewRandom(2019))
+		game.Start(3, out, "Chris", "Cleo", "Ruth")
+
+		for _, street := range []string{"third street: ", "fourth street: ", "fifth street: ", "sixth street: ", "seventh street: "} {
+			if !strings.Contains(out.String(), street+"Chris ") {
+				t.Errorf("got %q announced want it to contain %q", out.String(), street)
+			}
+
+			checkAround(t, game)
+			poker.AssertNoError(t, game.NextStreet())
+		}
+
+		if game.Winners() == nil || !strings.Contains(out.String(), "showdown: ") {
+			t.Fatalf("got %q announced want a showdown", out.String())
+		}
+
+		poker.AssertNoError(t, game.Finish(game.Winners()[0]))
+
+		if len(store.Games) != 1 || store.Games[0].Variant != poker.SevenCardStudName {
+			t.Errorf("got %+v recorded want a game of %s", store.Games, poker.SevenCardStudName)
+		}
+	})
+
+	t.Run("plays seven card stud down to a showdown without a small blind", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetVariant(poker.SevenCardStud)
+		game.SetBlindStructure(poker.BlindStructure{Levels: []poker.BlindLevel{{BigBlind: 100}}})
+		poker.AssertNoError(t, game.Start(2, &bytes.Buffer{}, "Chris", "Cleo"))
+
+		for i := 0; i < 5; i++ {
+			checkAround(t, game)
+			poker.AssertNoError(t, game.NextStreet())
+		}
+
+		if len(game.Winners()) == 0 {
+			t.Error("got no winners want the bring-in won at the showdown")
+		}
+	})
+
+	t.Run("won't start a game of more players than the variant can deal to", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetVariant(poker.SevenCardStud)
+
+		if err := game.Start(poker.SevenCardStud.MaxPlayers()+1, &bytes.Buffer{}); err != poker.ErrDealPlayers {
+			t.Fatalf("got error %v want %v", err, poker.ErrDealPlayers)
+		}
+
+		if game.State() != poker.GameCreated {
+			t.Errorf("got the game %v want it still %v", game.State(), poker.GameCreated)
+		}
+	})
+
+	t.Run("pot-limit omaha won't let a player bet more than the pot", func(t *testing.T) {
+		game := poker.NewTexasHoldEm(dummySpyAlerter, dummyPlayerStore)
+		game.SetVariant(poker.PotLimitOmaha)
+		game.Start(3, &bytes.Buffer{})
+
+		max := game.Betting().MaxBet()
+
+		if err := game.Act(poker.Action{Type: poker.Raise, Amount: max + 1}); err != poker.ErrOverLimit {
+			t.Errorf("got error %v raising to %d want %v", err, max+1, poker.ErrOverLimit)
+		}
+
+		poker.AssertNoError(t, game.Act(poker.Action{Type: poker.Raise, Amount: max}))
+	})
+}
+
+// showingCategory is the pair, two pair, trips or quads showing in cards, or high card if there isn't one
+func showingCategory(cards []poker.Card) poker.HandCategory {
+
+	counts := map[poker.Rank]int{}
+	pairs, most := 0, 0
+
+	for _, card := range cards {
+		counts[card.Rank]++
+		if counts[card.Rank] == 2 {
+			pairs++
+		}
+		if counts[card.Rank] > most {
+			most = counts[card.Rank]
+		}
+	}
+
+	switch {
+	case most == 4:
+		return poker.FourOfAKind
+	case most == 3:
+		return poker.ThreeOfAKind
+	case pairs == 2:
+		return poker.TwoPair
+	case pairs == 1:
+		return poker.OnePair
+	}
+
+	return poker.HighCard
+}